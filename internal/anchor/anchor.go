@@ -0,0 +1,204 @@
+// Package anchor attaches context items to lines of code and finds those
+// lines again after the code has changed.
+//
+// An anchor records a file path, a line number and a fingerprint: a hash of
+// the anchored line and the lines around it, compared without leading and
+// trailing whitespace. When the file changes, Check looks for the lines with
+// the same fingerprint, so an anchor follows its code when lines are inserted
+// or removed above it or when it is re-indented. If the surrounding lines
+// changed as well, the anchored line itself is searched for. Anchors whose
+// code cannot be found anymore are stale.
+package anchor
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// contextLines is the number of lines before and after the anchored line
+// that are part of its fingerprint.
+const contextLines = 2
+
+// Check results.
+const (
+	StatusOK    = "ok"    // The code is still at the anchored line
+	StatusMoved = "moved" // The code was found at another line
+	StatusStale = "stale" // The code could not be found
+)
+
+// Result is the outcome of checking an anchor.
+type Result struct {
+	// Anchor is the checked anchor, updated to the code's current line and
+	// fingerprint, or marked stale
+	Anchor models.Anchor
+
+	// Status is one of the Status* constants
+	Status string
+
+	// Reason explains why a stale anchor could not be found
+	Reason string
+}
+
+// ParseLocation splits a location of the form "path:line".
+func ParseLocation(spec string) (string, int, error) {
+	i := strings.LastIndex(spec, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("invalid location %q: use path:line", spec)
+	}
+	line, err := strconv.Atoi(spec[i+1:])
+	if err != nil || line < 1 {
+		return "", 0, fmt.Errorf("invalid line number in %q: must be a positive number", spec)
+	}
+	return spec[:i], line, nil
+}
+
+// New creates an anchor for a location of the form "path:line". A relative
+// path is relative to the working directory; the anchor stores it relative to
+// root, the project root, unless the file is outside of it.
+func New(root, spec string) (models.Anchor, error) {
+	path, line, err := ParseLocation(spec)
+	if err != nil {
+		return models.Anchor{}, err
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return models.Anchor{}, fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	lines, err := readLines(abs)
+	if err != nil {
+		return models.Anchor{}, fmt.Errorf("cannot anchor to %s: %w", spec, err)
+	}
+	if line > len(lines) {
+		return models.Anchor{}, fmt.Errorf("%s has only %d lines", path, len(lines))
+	}
+
+	stored := abs
+	if absRoot, err := filepath.Abs(root); err == nil {
+		if rel, err := filepath.Rel(absRoot, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			stored = rel
+		}
+	}
+
+	return models.Anchor{
+		Path:        filepath.ToSlash(stored),
+		Line:        line,
+		Fingerprint: Fingerprint(lines, line),
+		Text:        strings.TrimSpace(lines[line-1]),
+	}, nil
+}
+
+// Fingerprint returns the fingerprint of the given 1-based line of lines.
+// It covers the line and up to contextLines lines on either side, without
+// leading and trailing whitespace.
+func Fingerprint(lines []string, line int) string {
+	start := line - 1 - contextLines
+	if start < 0 {
+		start = 0
+	}
+	end := line + contextLines
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	h := sha256.New()
+	for _, l := range lines[start:end] {
+		h.Write([]byte(strings.TrimSpace(l)))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Check finds the code of an anchor in its file, which is resolved relative
+// to root.
+//
+// The anchored line is tried first. Otherwise the line whose fingerprint
+// matches and, failing that, the line with the same text is used, preferring
+// the one closest to the anchored line. The returned anchor is updated to
+// that line, with a fresh fingerprint.
+func Check(root string, a models.Anchor) Result {
+	path := filepath.FromSlash(a.Path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+
+	lines, err := readLines(path)
+	if err != nil {
+		a.Stale = true
+		reason := "file cannot be read"
+		if os.IsNotExist(err) {
+			reason = "file not found"
+		}
+		return Result{Anchor: a, Status: StatusStale, Reason: reason}
+	}
+
+	if a.Line <= len(lines) && Fingerprint(lines, a.Line) == a.Fingerprint {
+		a.Stale = false
+		return Result{Anchor: a, Status: StatusOK}
+	}
+
+	line := closest(a.Line, len(lines), func(l int) bool {
+		return Fingerprint(lines, l) == a.Fingerprint
+	})
+	if line == 0 && a.Text != "" {
+		line = closest(a.Line, len(lines), func(l int) bool {
+			return strings.TrimSpace(lines[l-1]) == a.Text
+		})
+	}
+	if line == 0 {
+		a.Stale = true
+		return Result{Anchor: a, Status: StatusStale, Reason: "code not found"}
+	}
+
+	status := StatusMoved
+	if line == a.Line {
+		// Only the surrounding lines changed
+		status = StatusOK
+	}
+	a.Line = line
+	a.Fingerprint = Fingerprint(lines, line)
+	a.Stale = false
+	return Result{Anchor: a, Status: status}
+}
+
+// closest returns the 1-based line between 1 and n closest to line for which
+// match returns true, or 0 if there is none. Earlier lines win ties.
+func closest(line, n int, match func(l int) bool) int {
+	for d := 0; d < n+line; d++ {
+		if l := line - d; l >= 1 && l <= n && match(l) {
+			return l
+		}
+		if l := line + d; d > 0 && l >= 1 && l <= n && match(l) {
+			return l
+		}
+	}
+	return 0
+}
+
+// readLines reads the lines of the file at path.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	return lines, nil
+}
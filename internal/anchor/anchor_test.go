@@ -0,0 +1,107 @@
+package anchor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLocation(t *testing.T) {
+	path, line, err := ParseLocation("internal/auth/login.go:120")
+	if err != nil || path != "internal/auth/login.go" || line != 120 {
+		t.Errorf("Unexpected result: %q %d %v", path, line, err)
+	}
+	for _, bad := range []string{"login.go", "login.go:", "login.go:0", "login.go:abc", ":12"} {
+		if _, _, err := ParseLocation(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestNewAndCheck(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "internal", "auth")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "login.go")
+	write := func(lines ...string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	original := []string{
+		"package auth",
+		"",
+		"func login() error {",
+		"	for i := 0; i < 3; i++ {",
+		"		if err := try(); err == nil {",
+		"			return nil",
+		"		}",
+		"	}",
+		"	return errFailed",
+		"}",
+	}
+	write(original...)
+
+	a, err := New(root, file+":5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Path != "internal/auth/login.go" || a.Line != 5 || a.Text != "if err := try(); err == nil {" {
+		t.Errorf("Unexpected anchor: %+v", a)
+	}
+	if _, err := New(root, file+":50"); err == nil {
+		t.Error("Expected error for a line past the end of the file")
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		if r := Check(root, a); r.Status != StatusOK || r.Anchor.Line != 5 {
+			t.Errorf("Expected ok at 5, got %+v", r)
+		}
+	})
+
+	t.Run("moved and re-indented", func(t *testing.T) {
+		moved := append([]string{"// Package auth handles logins.", "// More docs."}, original...)
+		moved[6] = "\t\t\tif err := try(); err == nil {"
+		write(moved...)
+		r := Check(root, a)
+		if r.Status != StatusMoved || r.Anchor.Line != 7 {
+			t.Errorf("Expected moved to 7, got %+v", r)
+		}
+	})
+
+	t.Run("context changed but line kept", func(t *testing.T) {
+		changed := append([]string{"// header"}, original...)
+		changed[4] = "	for i := 0; i < 5; i++ {"
+		write(changed...)
+		r := Check(root, a)
+		if r.Status != StatusMoved || r.Anchor.Line != 6 {
+			t.Errorf("Expected moved to 6 by text, got %+v", r)
+		}
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		write("package auth", "", "func login() error { return nil }")
+		if r := Check(root, a); r.Status != StatusStale || !r.Anchor.Stale || r.Reason != "code not found" {
+			t.Errorf("Expected stale, got %+v", r)
+		}
+		os.Remove(file)
+		if r := Check(root, a); r.Status != StatusStale || r.Reason != "file not found" {
+			t.Errorf("Expected stale for missing file, got %+v", r)
+		}
+	})
+}
+
+func TestFingerprintIgnoresWhitespace(t *testing.T) {
+	a := Fingerprint([]string{"a", "  b", "c"}, 2)
+	b := Fingerprint([]string{"a", "\tb  ", "c"}, 2)
+	if a != b {
+		t.Error("Expected fingerprints to ignore surrounding whitespace")
+	}
+	if a == Fingerprint([]string{"a", "b", "d"}, 2) {
+		t.Error("Expected fingerprint to cover the following lines")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/ondrahracek/contextkeeper/internal/anchor"
 	"github.com/ondrahracek/contextkeeper/internal/config"
 	"github.com/ondrahracek/contextkeeper/internal/models"
 	"github.com/ondrahracek/contextkeeper/internal/storage"
@@ -34,6 +35,20 @@ var addCmd = &cobra.Command{
   # Add with project and tags
   ck add "Fix bug #123" --project "web-app" --tags "bug,urgent"
 
+  # Add with a priority and a due date
+  ck add "Fix login before the release" --priority high --due friday
+  ck add "Renew certificates" --due 2026-11-01
+  ck add "Follow up with design" --due +3d
+
+  # Add a subtask of another item
+  ck add "Switch the login page to OIDC" --parent abc12345
+
+  # Only relevant while the current branch is alive
+  ck add "Login tests are flaky on this branch" --branch
+
+  # Attach the item to a line of code
+  ck add "This retry loop never backs off" --at internal/auth/login.go:120
+
   # Open editor for multi-line content
   ck add --editor
 
@@ -53,6 +68,20 @@ var (
 	useEditor bool
 	// addSyncFlag triggers sync to AI agent files after adding
 	addSyncFlag bool
+	// addPinFlag pins the new item
+	addPinFlag bool
+	// addPriorityFlag sets the priority of the new item
+	addPriorityFlag string
+	// addDueFlag sets the due date of the new item
+	addDueFlag string
+	// addParentFlag makes the new item a subtask of another item
+	addParentFlag string
+	// addAtFlag attaches the new item to code locations (path:line)
+	addAtFlag []string
+	// addBranchFlag binds the new item to a git branch
+	addBranchFlag string
+	// addGlobalFlag keeps the new item from being bound to a branch
+	addGlobalFlag bool
 )
 
 // addCommand is the execution function for the add command.
@@ -96,31 +125,100 @@ func addCommand(cmd *cobra.Command, args []string) error {
 	}
 
 	// Parse and validate tags
-	tags := utils.ParseTags(tagStr)
+	tags, err := parseTagInput(tagStr)
+	if err != nil {
+		return err
+	}
 	if err := utils.ValidateTags(tags); err != nil {
 		return err
 	}
 
+	priority, err := utils.ParsePriority(addPriorityFlag)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	var due *time.Time
+	if addDueFlag != "" {
+		t, err := utils.ParseDue(addDueFlag, now)
+		if err != nil {
+			return err
+		}
+		due = &t
+	}
+
+	// Initialize storage
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	var anchors []models.Anchor
+	for _, spec := range addAtFlag {
+		a, err := anchor.New(projectRoot(storagePath), spec)
+		if err != nil {
+			return err
+		}
+		anchors = append(anchors, a)
+	}
+
 	// Get project from flag or environment variable
 	project := projectFlag
 	if project == "" {
 		project = os.Getenv("CK_DEFAULT_PROJECT")
 	}
 
+	// Subtasks belong to their parent's project unless told otherwise
+	var parent *models.ContextItem
+	if addParentFlag != "" {
+		p, err := lookupItem(cmd, stor, addParentFlag)
+		if err != nil {
+			return fmt.Errorf("parent: %w", err)
+		}
+		parent = &p
+		if projectFlag == "" && parent.Project != "" {
+			project = parent.Project
+		}
+	}
+	var parentID string
+	if parent != nil {
+		parentID = parent.ID
+	}
+
+	branch, err := newItemBranch(storagePath, addBranchFlag, addGlobalFlag, parent)
+	if err != nil {
+		return err
+	}
+
+	// New items of a project get its default tags
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+	tags, _ = retag(tags, cfg.ProjectTags(project), nil)
+	warnSimilarProject(cmd, stor.GetAll(), project)
+
+	id, err := utils.GenerateUUID()
+	if err != nil {
+		return err
+	}
+
 	// Create the new item using models.ContextItem
-	now := time.Now()
 	item := models.ContextItem{
-		ID:        utils.GenerateUUID(),
+		ID:        id,
 		Content:   content,
 		Project:   project,
 		Tags:      tags,
 		CreatedAt: now,
-	}
-
-	// Initialize storage and add the item
-	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
-	if err := stor.Load(); err != nil {
-		return fmt.Errorf("failed to load storage: %w", err)
+		Pinned:    addPinFlag,
+		Priority:  priority,
+		DueAt:     due,
+		Branch:    branch,
+		ParentID:  parentID,
+		Anchors:   anchors,
 	}
 
 	if err := stor.Add(item); err != nil {
@@ -133,7 +231,7 @@ func addCommand(cmd *cobra.Command, args []string) error {
 
 	if jsonOutput {
 		result := map[string]string{
-			"id":     item.ID[:8],
+			"id":     shortID(item.ID),
 			"status": "added",
 		}
 		data, _ := json.MarshalIndent(result, "", "  ")
@@ -161,6 +259,14 @@ func init() {
 	addCmd.Flags().BoolVarP(&useEditor, "editor", "e", false, "Open editor to enter content")
 	addCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
 	addCmd.Flags().BoolVar(&addSyncFlag, "sync", false, "Sync to AI agent rule files after adding")
+	addCmd.Flags().BoolVar(&addPinFlag, "pin", false, "Pin the item to the top of synced files")
+	addCmd.Flags().StringVar(&addPriorityFlag, "priority", "", "Priority of the item: high, medium or low")
+	addCmd.Flags().StringVar(&addDueFlag, "due", "", "Due date: YYYY-MM-DD, today, tomorrow, +3d, +2w or a weekday")
+	addCmd.Flags().StringVar(&addParentFlag, "parent", "", "Make the item a subtask of the item with this ID")
+	addCmd.Flags().StringArrayVar(&addAtFlag, "at", nil, "Attach the item to a code location (path:line, repeatable)")
+	addCmd.Flags().StringVar(&addBranchFlag, "branch", "", "Bind the item to the current git branch, or to --branch=<name>")
+	addCmd.Flags().Lookup("branch").NoOptDefVal = currentBranchName
+	addCmd.Flags().BoolVar(&addGlobalFlag, "global", false, "Do not bind the item to a branch, even with automatic binding")
 
 	// Add command to root
 	RootCmd.AddCommand(addCmd)
@@ -0,0 +1,176 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// agendaCmd shows open items with due dates, grouped by urgency.
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "Show overdue, today, upcoming and blocked items",
+	Long: `Show open items with a due date, in three sections: overdue items, items due
+today, and items due within the next few days (7 by default). Within each
+section items are ordered by due date and then priority.
+
+A fourth section lists the blocked items, whether marked with 'ck block' or
+blocked by an open item through a link (see 'ck link'), with or without a
+due date. Blocked items are only listed there.`,
+	Example: `  # What needs attention this week
+  ck agenda
+
+  # Look two weeks ahead
+  ck agenda --days 14
+
+  # Output as JSON
+  ck agenda --json`,
+	Args: cobra.NoArgs,
+	RunE: agendaCommand,
+}
+
+// agendaDaysFlag is the number of days ahead shown as upcoming
+var agendaDaysFlag int
+
+// agenda holds the items of each agenda section.
+type agenda struct {
+	Overdue  []models.ContextItem
+	Today    []models.ContextItem
+	Upcoming []models.ContextItem
+	Blocked  []models.ContextItem
+
+	// BlockedBy holds the IDs of the open items blocking each item
+	BlockedBy map[string][]string
+}
+
+// agendaBlockedJSON is the JSON form of an item in the blocked section,
+// with why it is blocked: the reason given to ck block and the items
+// blocking it through links.
+type agendaBlockedJSON struct {
+	listItemJSON
+	Reason    string   `json:"reason,omitempty"`
+	BlockedBy []string `json:"blockedBy"`
+}
+
+// agendaCommand is the execution function for the agenda command.
+func agendaCommand(cmd *cobra.Command, args []string) error {
+	if agendaDaysFlag < 1 {
+		return fmt.Errorf("--days must be at least 1")
+	}
+
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	a := buildAgenda(filterActive(stor.GetAll()), time.Now(), agendaDaysFlag)
+
+	if jsonOutput {
+		toJSON := func(items []models.ContextItem) []listItemJSON {
+			out := make([]listItemJSON, 0, len(items))
+			for _, item := range items {
+				out = append(out, newListItemJSON(item))
+			}
+			return out
+		}
+		blocked := make([]agendaBlockedJSON, 0, len(a.Blocked))
+		for _, item := range a.Blocked {
+			entry := agendaBlockedJSON{listItemJSON: newListItemJSON(item), BlockedBy: []string{}}
+			if item.EffectiveState() == models.StateBlocked {
+				entry.Reason = item.StateReason()
+			}
+			for _, id := range a.BlockedBy[item.ID] {
+				entry.BlockedBy = append(entry.BlockedBy, shortID(id))
+			}
+			blocked = append(blocked, entry)
+		}
+		result := map[string]interface{}{
+			"overdue":  toJSON(a.Overdue),
+			"today":    toJSON(a.Today),
+			"upcoming": toJSON(a.Upcoming),
+			"blocked":  blocked,
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal agenda to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if len(a.Overdue)+len(a.Today)+len(a.Upcoming)+len(a.Blocked) == 0 {
+		fmt.Fprintf(out, "Nothing due in the next %d days and nothing blocked.\n", agendaDaysFlag)
+		return nil
+	}
+
+	sections := []struct {
+		title string
+		items []models.ContextItem
+	}{
+		{"Overdue", a.Overdue},
+		{"Today", a.Today},
+		{fmt.Sprintf("Upcoming (next %d days)", agendaDaysFlag), a.Upcoming},
+		{"Blocked", a.Blocked},
+	}
+	first := true
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(out)
+		}
+		first = false
+		fmt.Fprintf(out, "%s (%d)\n", section.title, len(section.items))
+		fmt.Fprint(out, utils.FormatItems(section.items, utils.ListOptions{BlockedBy: a.BlockedBy}))
+	}
+	return nil
+}
+
+// buildAgenda sorts the open items with a due date into agenda sections.
+// Items due more than days days after now are left out. Blocked items go
+// into the blocked section whatever their due date.
+func buildAgenda(items []models.ContextItem, now time.Time, days int) agenda {
+	sortItems(items, sortDue)
+
+	a := agenda{BlockedBy: models.Blockers(items)}
+	for _, item := range items {
+		if item.CompletedAt != nil {
+			continue
+		}
+		if item.EffectiveState() == models.StateBlocked || len(a.BlockedBy[item.ID]) > 0 {
+			a.Blocked = append(a.Blocked, item)
+			continue
+		}
+		if item.DueAt == nil {
+			continue
+		}
+		switch until := utils.DaysUntil(*item.DueAt, now); {
+		case until < 0:
+			a.Overdue = append(a.Overdue, item)
+		case until == 0:
+			a.Today = append(a.Today, item)
+		case until <= days:
+			a.Upcoming = append(a.Upcoming, item)
+		}
+	}
+	return a
+}
+
+// init registers the agenda command with the root command.
+func init() {
+	agendaCmd.Flags().IntVarP(&agendaDaysFlag, "days", "d", 7, "Number of days ahead to show as upcoming")
+	agendaCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	RootCmd.AddCommand(agendaCmd)
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestPriorityAndDueDates(t *testing.T) {
+	now := time.Now()
+	day := func(offset int) *time.Time {
+		y, m, d := now.Date()
+		t := time.Date(y, m, d+offset, 0, 0, 0, 0, time.Local)
+		return &t
+	}
+
+	_, storagePath := testStore(t)
+	stor := storage.NewStorage(storagePath)
+	stor.Add(models.ContextItem{ID: "nodue-item-0000", Content: "Someday idea", CreatedAt: now})
+	stor.Add(models.ContextItem{ID: "later-item-0000", Content: "Next week", CreatedAt: now, DueAt: day(5), Priority: models.PriorityLow})
+	stor.Add(models.ContextItem{ID: "today-item-0000", Content: "Due today", CreatedAt: now, DueAt: day(0)})
+	stor.Add(models.ContextItem{ID: "late1-item-0000", Content: "Late release fix", CreatedAt: now, DueAt: day(-2), Priority: models.PriorityHigh})
+	stor.Add(models.ContextItem{ID: "far-item-00000", Content: "Far away", CreatedAt: now, DueAt: day(30)})
+
+	t.Run("agenda groups by urgency", func(t *testing.T) {
+		var result map[string][]map[string]interface{}
+		if err := json.Unmarshal([]byte(mustRun(t, "agenda", "--json")), &result); err != nil {
+			t.Fatal(err)
+		}
+		if len(result["overdue"]) != 1 || len(result["today"]) != 1 || len(result["upcoming"]) != 1 {
+			t.Fatalf("Unexpected agenda: %v", result)
+		}
+		if result["overdue"][0]["priority"] != "high" {
+			t.Errorf("Expected overdue item with priority, got %v", result["overdue"][0])
+		}
+
+		out := mustRun(t, "agenda", "--days", "60")
+		if !strings.Contains(out, "Overdue (1)") || !strings.Contains(out, "Upcoming (next 60 days) (2)") {
+			t.Errorf("Unexpected agenda output:\n%s", out)
+		}
+	})
+
+	t.Run("list sorts by priority and due date", func(t *testing.T) {
+		ids := func(args ...string) string {
+			var items []map[string]interface{}
+			if err := json.Unmarshal([]byte(mustRun(t, args...)), &items); err != nil {
+				t.Fatal(err)
+			}
+			var out []string
+			for _, item := range items {
+				out = append(out, item["id"].(string)[:5])
+			}
+			return strings.Join(out, " ")
+		}
+		if got := ids("list", "--json", "--sort", "priority"); got != "late1 later today far-i nodue" {
+			t.Errorf("Unexpected priority order: %s", got)
+		}
+		if got := ids("list", "--json", "--sort", "due"); got != "late1 today later far-i nodue" {
+			t.Errorf("Unexpected due order: %s", got)
+		}
+		if got := ids("list", "--json", "due:<today"); got != "late1" {
+			t.Errorf("Unexpected due query result: %s", got)
+		}
+	})
+
+	t.Run("status and sync flag overdue items", func(t *testing.T) {
+		var status map[string]interface{}
+		if err := json.Unmarshal([]byte(mustRun(t, "status", "--json")), &status); err != nil {
+			t.Fatal(err)
+		}
+		if status["overdueItems"] != float64(1) || status["dueTodayItems"] != float64(1) {
+			t.Errorf("Unexpected status counts: %v", status)
+		}
+
+		content, err := buildSyncContent(stor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(content, "Late release fix _(today, high, overdue 2d)_") {
+			t.Errorf("Expected overdue flag in sync content:\n%s", content)
+		}
+	})
+
+	t.Run("add sets priority and due date", func(t *testing.T) {
+		mustRun(t, "add", "Ship it", "--priority", "h", "--due", "+3d")
+		stor.Load()
+		var added models.ContextItem
+		for _, item := range stor.GetAll() {
+			if item.Content == "Ship it" {
+				added = item
+			}
+		}
+		if added.Priority != models.PriorityHigh || added.DueAt == nil || !added.DueAt.Equal(*day(3)) {
+			t.Errorf("Unexpected added item: %+v", added)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ondrahracek/contextkeeper/internal/anchor"
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// anchorsCmd groups the commands that manage code anchors.
+var anchorsCmd = &cobra.Command{
+	Use:   "anchors",
+	Short: "Manage code anchors",
+	Long: `Manage code anchors: the file and line locations items are attached to with
+'ck add --at path:line'.
+
+Anchors store the path relative to the project root (the directory that
+contains .contextkeeper), the line number, and a fingerprint of the
+surrounding lines. Use 'ck anchors check' to follow anchors after the code
+has moved.`,
+}
+
+// anchorsCheckCmd re-locates anchors after code changes.
+var anchorsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Re-locate anchors and report stale ones",
+	Long: `Check every anchor against the current code.
+
+Anchors whose code moved, for example because lines were added above it, are
+updated to the new line. Anchors whose code cannot be found anymore are
+marked stale and reported; they are shown as stale by 'ck show' and
+'ck list --anchors' until the code is found again.`,
+	Example: `  # Update anchors after a refactoring
+  ck anchors check
+
+  # Only report, without updating the store
+  ck anchors check --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runAnchorsCheck,
+}
+
+// Command flags for the anchors commands.
+var (
+	anchorsDryRunFlag bool // --dry-run: Report without updating the store
+	anchorsJSONFlag   bool // --json: Output as JSON
+)
+
+// anchorCheckJSON is the JSON form of a checked anchor.
+type anchorCheckJSON struct {
+	ID      string `json:"id"`
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	OldLine int    `json:"oldLine,omitempty"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// runAnchorsCheck checks the anchors of all items and saves the updated ones.
+func runAnchorsCheck(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	root := projectRoot(storagePath)
+
+	var results []anchorCheckJSON
+	counts := make(map[string]int)
+	for _, item := range stor.GetAll() {
+		if len(item.Anchors) == 0 {
+			continue
+		}
+		changed := false
+		item.Anchors = append([]models.Anchor(nil), item.Anchors...)
+		for i, a := range item.Anchors {
+			r := anchor.Check(root, a)
+			counts[r.Status]++
+			result := anchorCheckJSON{ID: shortID(item.ID), Path: a.Path, Line: r.Anchor.Line, Status: r.Status, Reason: r.Reason}
+			if r.Anchor.Line != a.Line {
+				result.OldLine = a.Line
+			}
+			results = append(results, result)
+			if r.Anchor != a {
+				item.Anchors[i] = r.Anchor
+				changed = true
+			}
+		}
+		if changed && !anchorsDryRunFlag {
+			if err := stor.Update(item); err != nil {
+				return fmt.Errorf("failed to update item %q: %w", item.ID, err)
+			}
+		}
+	}
+
+	if anchorsJSONFlag {
+		if results == nil {
+			results = []anchorCheckJSON{}
+		}
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal anchors to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	if len(results) == 0 {
+		cmd.Println("No anchors. Attach items to code with 'ck add --at path:line'.")
+		return nil
+	}
+	for _, r := range results {
+		location := fmt.Sprintf("%s:%d", filepath.FromSlash(r.Path), r.Line)
+		switch r.Status {
+		case anchor.StatusMoved:
+			cmd.Printf("moved  %s  %s:%d -> %d\n", r.ID, filepath.FromSlash(r.Path), r.OldLine, r.Line)
+		case anchor.StatusStale:
+			cmd.Printf("stale  %s  %s (%s)\n", r.ID, location, r.Reason)
+		}
+	}
+	cmd.Printf("Checked %d anchors: %d ok, %d moved, %d stale\n",
+		len(results), counts[anchor.StatusOK], counts[anchor.StatusMoved], counts[anchor.StatusStale])
+	if anchorsDryRunFlag && counts[anchor.StatusMoved]+counts[anchor.StatusStale] > 0 {
+		cmd.Println("Dry run: no anchors were updated.")
+	}
+	return nil
+}
+
+// projectRoot returns the directory anchors are relative to: the directory
+// containing the store directory.
+func projectRoot(storagePath string) string {
+	return filepath.Dir(config.StoreDir(storagePath))
+}
+
+// init registers the anchors commands with the root command.
+func init() {
+	anchorsCheckCmd.Flags().BoolVar(&anchorsDryRunFlag, "dry-run", false, "Report without updating the store")
+	anchorsCheckCmd.Flags().BoolVar(&anchorsJSONFlag, "json", false, "Output as JSON")
+
+	anchorsCmd.AddCommand(anchorsCheckCmd)
+	RootCmd.AddCommand(anchorsCmd)
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestAnchorCommands(t *testing.T) {
+	root, storagePath := testStore(t)
+
+	source := filepath.Join(root, "internal", "auth", "login.go")
+	if err := os.MkdirAll(filepath.Dir(source), 0755); err != nil {
+		t.Fatal(err)
+	}
+	code := []string{
+		"package auth",
+		"",
+		"func login() error {",
+		"	for i := 0; i < 3; i++ {",
+		"		retry()",
+		"	}",
+		"	return nil",
+		"}",
+	}
+	writeSource := func(lines ...string) {
+		t.Helper()
+		if err := os.WriteFile(source, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSource(code...)
+
+	t.Run("add --at stores a relative anchor", func(t *testing.T) {
+		mustRun(t, "add", "This retry loop never backs off", "--at", source+":5")
+
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		items := stor.GetAll()
+		if len(items) != 1 || len(items[0].Anchors) != 1 {
+			t.Fatalf("Expected one anchored item, got %+v", items)
+		}
+		a := items[0].Anchors[0]
+		if a.Path != "internal/auth/login.go" || a.Line != 5 || a.Text != "retry()" || a.Fingerprint == "" {
+			t.Errorf("Unexpected anchor: %+v", a)
+		}
+
+		if _, err := run(t, "add", "Nowhere", "--at", source+":99"); err == nil {
+			t.Error("Expected an anchor past the end of the file to fail")
+		}
+		if _, err := run(t, "add", "Nowhere", "--at", "missing.go:1"); err == nil {
+			t.Error("Expected an anchor to a missing file to fail")
+		}
+	})
+
+	t.Run("list and show display anchors", func(t *testing.T) {
+		loc := filepath.Join("internal", "auth", "login.go") + ":5"
+		if out := mustRun(t, "list", "--anchors"); !strings.Contains(out, "<"+loc+">") {
+			t.Errorf("Expected anchor in list output, got:\n%s", out)
+		}
+		if out := mustRun(t, "list"); strings.Contains(out, loc) {
+			t.Errorf("Expected no anchors without --anchors, got:\n%s", out)
+		}
+
+		var items []map[string]interface{}
+		stor := storage.NewStorage(storagePath)
+		stor.Load()
+		out := mustRun(t, "show", shortID(stor.GetAll()[0].ID))
+		if !strings.Contains(out, "Anchors:\n  "+loc+"  retry()") {
+			t.Errorf("Expected anchor in show output, got:\n%s", out)
+		}
+		if err := json.Unmarshal([]byte(mustRun(t, "list", "--json")), &items); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := items[0]["anchors"]; !ok {
+			t.Errorf("Expected anchors in JSON, got %v", items[0])
+		}
+
+		content, err := buildSyncContent(stor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(content, "at internal/auth/login.go:5") {
+			t.Errorf("Expected anchor in sync content:\n%s", content)
+		}
+	})
+
+	t.Run("check follows moved code", func(t *testing.T) {
+		writeSource(append([]string{"// Package auth handles logins.", "// It retries."}, code...)...)
+
+		out := mustRun(t, "anchors", "check", "--dry-run")
+		if !strings.Contains(out, "login.go:5 -> 7") || !strings.Contains(out, "Dry run") {
+			t.Errorf("Unexpected dry run output:\n%s", out)
+		}
+
+		out = mustRun(t, "anchors", "check")
+		if !strings.Contains(out, "1 moved") {
+			t.Errorf("Unexpected output:\n%s", out)
+		}
+		stor := storage.NewStorage(storagePath)
+		stor.Load()
+		if line := stor.GetAll()[0].Anchors[0].Line; line != 7 {
+			t.Errorf("Expected anchor moved to line 7, got %d", line)
+		}
+		if out := mustRun(t, "anchors", "check"); !strings.Contains(out, "1 ok, 0 moved, 0 stale") {
+			t.Errorf("Expected anchor to be up to date, got:\n%s", out)
+		}
+	})
+
+	t.Run("check reports stale anchors", func(t *testing.T) {
+		writeSource("package auth", "", "func login() error { return nil }")
+
+		var results []map[string]interface{}
+		if err := json.Unmarshal([]byte(mustRun(t, "anchors", "check", "--json")), &results); err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0]["status"] != "stale" {
+			t.Errorf("Expected stale anchor, got %v", results)
+		}
+		if out := mustRun(t, "list", "--anchors"); !strings.Contains(out, "stale>") {
+			t.Errorf("Expected stale anchor in list output, got:\n%s", out)
+		}
+	})
+}
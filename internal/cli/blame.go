@@ -0,0 +1,97 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/history"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// blameCmd shows who last changed each active item.
+var blameCmd = &cobra.Command{
+	Use:   "blame",
+	Short: "Show who last changed each active item",
+	Long: `Show, for each active item, the commit and author that last changed it,
+from the git history of the store file (.contextkeeper/items.json).
+
+Items with changes that are not committed yet are marked as uncommitted. Use
+'ck history <id>' to see all changes of an item.`,
+	Example: `  # Who touched what?
+  ck blame
+
+  # Output as JSON
+  ck blame --json`,
+	Args: cobra.NoArgs,
+	RunE: blameCommand,
+}
+
+// blameJSON is the JSON form of an item's last change.
+type blameJSON struct {
+	ID      string     `json:"id"`
+	Content string     `json:"content"`
+	Commit  string     `json:"commit,omitempty"`
+	Author  string     `json:"author,omitempty"`
+	Date    *time.Time `json:"date,omitempty"`
+}
+
+// blameCommand is the execution function for the blame command.
+func blameCommand(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	snapshots, err := loadHistory(storagePath)
+	if err != nil {
+		return err
+	}
+	last := history.LastChanged(snapshots)
+
+	var out []blameJSON
+	for _, item := range filterActive(stor.GetAll()) {
+		if item.Archived {
+			continue
+		}
+		j := blameJSON{ID: item.ID, Content: item.Content}
+		if c := last[item.ID].Commit; c != nil {
+			date := c.Date
+			j.Commit, j.Author, j.Date = c.Hash, c.Author, &date
+		}
+		out = append(out, j)
+	}
+
+	if jsonOutput {
+		if out == nil {
+			out = []blameJSON{}
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal blame to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	if len(out) == 0 {
+		cmd.Println("No active items.")
+		return nil
+	}
+	for _, j := range out {
+		cmd.Printf("%s  %s  %s\n", shortID(j.ID), commitLabel(last[j.ID].Commit), j.Content)
+	}
+	return nil
+}
+
+// init registers the blame command with the root command.
+func init() {
+	blameCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	RootCmd.AddCommand(blameCmd)
+}
@@ -0,0 +1,221 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// branchCleanupCmd archives the items of merged and deleted branches.
+var branchCleanupCmd = &cobra.Command{
+	Use:   "branch-cleanup",
+	Short: "Archive items of merged or deleted branches",
+	Long: `Archive the items bound to git branches (see 'ck add --branch') that were
+merged into the base branch or whose upstream branch was deleted from the
+remote.
+
+With --deleted, the items of branches that exist neither locally nor as a
+remote-tracking branch are archived too. This is not the default: the branch
+of an item may belong to a teammate or not have been created yet.
+
+The base branch is --base, the "base" setting in the "branches" section of
+.contextkeeper/config.json, or main or master, whichever exists. The current
+branch is never considered merged, nor is a branch whose tip is the tip of
+the base branch: a new branch cannot be told apart from one that was just
+fast-forwarded into the base branch. Such branches are cleaned up once the
+base branch moves on or, with --deleted, once they are deleted.`,
+	Example: `  # After merging and deleting feature branches
+  ck branch-cleanup
+
+  # See what would be archived
+  ck branch-cleanup --dry-run
+
+  # Also archive the items of branches deleted everywhere
+  ck branch-cleanup --deleted
+
+  # Use another base branch
+  ck branch-cleanup --base develop`,
+	Args: cobra.NoArgs,
+	RunE: runBranchCleanup,
+}
+
+// Command flags for the branch-cleanup command.
+var (
+	branchBaseFlag    string // --base: The branch feature branches are merged into
+	branchDryRunFlag  bool   // --dry-run: Report without archiving
+	branchDeletedFlag bool   // --deleted: Also archive items of branches that exist nowhere
+)
+
+// currentBranchName is the value of --branch given without a name.
+const currentBranchName = "HEAD"
+
+// filterBranch returns the global items and the items bound to branch.
+func filterBranch(items []models.ContextItem, branch string) []models.ContextItem {
+	filtered := make([]models.ContextItem, 0, len(items))
+	for _, item := range items {
+		if item.Branch == "" || item.Branch == branch {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// baseBranch returns the branch feature branches are merged into: the
+// configured one, or main or master if it exists.
+func baseBranch(root string, cfg *config.StoreConfig) (string, error) {
+	if cfg.Branches.Base != "" {
+		return cfg.Branches.Base, nil
+	}
+	for _, name := range []string{"main", "master"} {
+		if _, err := git.ResolveCommit(root, "refs/heads/"+name); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("cannot find the base branch: use --base or set \"base\" in the \"branches\" section of config.json")
+}
+
+// newItemBranch returns the branch a new item is bound to: the one given
+// with --branch, the parent's, or with automatic binding configured the
+// current branch unless it is the base branch. Global items are bound to
+// none.
+func newItemBranch(storagePath, flag string, global bool, parent *models.ContextItem) (string, error) {
+	root := projectRoot(storagePath)
+	switch {
+	case global:
+		return "", nil
+	case flag == currentBranchName:
+		branch := git.CurrentBranch(root)
+		if branch == "" {
+			return "", fmt.Errorf("--branch: no branch is checked out")
+		}
+		return branch, nil
+	case flag != "":
+		return flag, nil
+	case parent != nil && parent.Branch != "":
+		return parent.Branch, nil
+	}
+
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil || !cfg.Branches.Auto {
+		return "", err
+	}
+	branch := git.CurrentBranch(root)
+	if base, err := baseBranch(root, cfg); err == nil && branch == base {
+		return "", nil
+	}
+	return branch, nil
+}
+
+// runBranchCleanup is the execution function for the branch-cleanup command.
+func runBranchCleanup(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	root := projectRoot(storagePath)
+	if !git.IsRepo(root) {
+		return fmt.Errorf("not a git repository: %s", root)
+	}
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+
+	base := branchBaseFlag
+	if base == "" {
+		if base, err = baseBranch(root, cfg); err != nil {
+			return err
+		}
+	}
+	baseTip, err := git.ResolveCommit(root, base)
+	if err != nil {
+		return fmt.Errorf("base branch: %w", err)
+	}
+
+	local, err := git.Branches(root, "")
+	if err != nil {
+		return err
+	}
+	merged, err := git.Branches(root, base)
+	if err != nil {
+		return err
+	}
+	remote, err := git.RemoteBranches(root)
+	if err != nil {
+		return err
+	}
+	upstreamGone, err := git.GoneBranches(root)
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(local)+len(remote))
+	for _, b := range append(local, remote...) {
+		exists[b] = true
+	}
+	current := git.CurrentBranch(root)
+	gone := make(map[string]string) // Branch name to reason
+	for _, b := range upstreamGone {
+		if b != current {
+			gone[b] = "deleted upstream"
+		}
+	}
+	for _, b := range merged {
+		if b == base || b == current {
+			continue
+		}
+		if tip, err := git.ResolveCommit(root, "refs/heads/"+b); err == nil && tip != baseTip {
+			gone[b] = "merged into " + base
+		}
+	}
+
+	verb := "Archived"
+	if branchDryRunFlag {
+		verb = "Would archive"
+	}
+	archived := 0
+	branches := make(map[string]bool)
+	for _, item := range stor.GetAll() {
+		if item.Branch == "" || item.Archived {
+			continue
+		}
+		reason, ok := gone[item.Branch]
+		if branchDeletedFlag && !exists[item.Branch] {
+			reason, ok = "deleted", true
+		}
+		if !ok {
+			continue
+		}
+		if !branchDryRunFlag {
+			if err := stor.Archive(item.ID); err != nil {
+				return fmt.Errorf("failed to archive item %q: %w", item.ID, err)
+			}
+		}
+		cmd.Printf("%s %s (%s %s): %s\n", verb, shortID(item.ID), item.Branch, reason, item.Content)
+		archived++
+		branches[item.Branch] = true
+	}
+
+	if archived == 0 {
+		cmd.Println("No items of merged or deleted branches.")
+		return nil
+	}
+	cmd.Printf("%s %d items of %d branches\n", verb, archived, len(branches))
+	return nil
+}
+
+// init registers the branch-cleanup command with the root command.
+func init() {
+	branchCleanupCmd.Flags().StringVar(&branchBaseFlag, "base", "", "Branch that feature branches are merged into (default main or master)")
+	branchCleanupCmd.Flags().BoolVar(&branchDryRunFlag, "dry-run", false, "Report without archiving")
+	branchCleanupCmd.Flags().BoolVar(&branchDeletedFlag, "deleted", false, "Also archive items of branches that exist neither locally nor on a remote")
+	RootCmd.AddCommand(branchCleanupCmd)
+}
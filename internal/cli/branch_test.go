@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestBranches(t *testing.T) {
+	root, storagePath := testStore(t)
+
+	gitRun := func(t *testing.T, args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=Ada", "-c", "user.email=ada@example.com"}, args...)
+		if _, err := git.Run(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit := func(t *testing.T, file string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, file), []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+		gitRun(t, "add", file)
+		gitRun(t, "commit", "-q", "-m", "Add "+file)
+	}
+	byContent := func(t *testing.T) map[string]models.ContextItem {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		items := make(map[string]models.ContextItem)
+		for _, item := range stor.GetAll() {
+			items[item.Content] = item
+		}
+		return items
+	}
+
+	gitRun(t, "init", "-q", "-b", "main")
+	commit(t, "README")
+	mustRun(t, "add", "Global note")
+
+	t.Run("add --branch binds to the current branch", func(t *testing.T) {
+		gitRun(t, "checkout", "-q", "-b", "feature/login")
+		mustRun(t, "add", "Login tests are flaky", "--branch")
+		mustRun(t, "add", "Ask about the old flow", "--branch=feature/old")
+
+		items := byContent(t)
+		if b := items["Login tests are flaky"].Branch; b != "feature/login" {
+			t.Errorf("Branch = %q, want feature/login", b)
+		}
+		if b := items["Ask about the old flow"].Branch; b != "feature/old" {
+			t.Errorf("Branch = %q, want feature/old", b)
+		}
+		if b := items["Global note"].Branch; b != "" {
+			t.Errorf("Branch = %q, want none", b)
+		}
+	})
+
+	t.Run("automatic binding", func(t *testing.T) {
+		if err := config.SaveStoreConfig(storagePath, &config.StoreConfig{Branches: config.BranchConfig{Auto: true}}); err != nil {
+			t.Fatal(err)
+		}
+		defer config.SaveStoreConfig(storagePath, &config.StoreConfig{})
+
+		mustRun(t, "add", "Rename the login handler")
+		mustRun(t, "add", "Global decision", "--global")
+		gitRun(t, "checkout", "-q", "main")
+		mustRun(t, "add", "Made on the base branch")
+		gitRun(t, "checkout", "-q", "feature/login")
+
+		items := byContent(t)
+		if b := items["Rename the login handler"].Branch; b != "feature/login" {
+			t.Errorf("Branch = %q, want feature/login", b)
+		}
+		if b := items["Global decision"].Branch; b != "" {
+			t.Errorf("Branch with --global = %q, want none", b)
+		}
+		if b := items["Made on the base branch"].Branch; b != "" {
+			t.Errorf("Branch on main = %q, want none", b)
+		}
+	})
+
+	t.Run("list and sync show the current branch", func(t *testing.T) {
+		out := mustRun(t, "list")
+		if !strings.Contains(out, "Login tests are flaky") || !strings.Contains(out, "Global note") {
+			t.Errorf("Expected global and branch items on the branch, got: %s", out)
+		}
+		if strings.Contains(out, "Ask about the old flow") {
+			t.Errorf("Item of another branch listed: %s", out)
+		}
+
+		gitRun(t, "checkout", "-q", "main")
+		out = mustRun(t, "list")
+		if strings.Contains(out, "Login tests are flaky") || !strings.Contains(out, "Global note") {
+			t.Errorf("Expected only global items on main, got: %s", out)
+		}
+		if out := mustRun(t, "list", "--all-branches"); !strings.Contains(out, "Login tests are flaky") {
+			t.Errorf("Expected all items with --all-branches, got: %s", out)
+		}
+		if out := mustRun(t, "list", "branch:feature/login"); !strings.Contains(out, "Login tests are flaky") || strings.Contains(out, "Global note") {
+			t.Errorf("Unexpected branch query output: %s", out)
+		}
+
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		content, err := buildSyncContent(stor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(content, "Login tests are flaky") || !strings.Contains(content, "Global note") {
+			t.Errorf("Expected only global items synced on main, got:\n%s", content)
+		}
+	})
+
+	t.Run("branch-cleanup archives merged and deleted branches", func(t *testing.T) {
+		gitRun(t, "checkout", "-q", "feature/login")
+		commit(t, "login.go")
+		gitRun(t, "checkout", "-q", "main")
+		gitRun(t, "merge", "-q", "--no-ff", "-m", "Merge feature/login", "feature/login")
+		gitRun(t, "branch", "feature/new")
+		mustRun(t, "add", "Plan the new feature", "--branch=feature/new")
+
+		// feature/gone tracks a branch deleted from the remote, feature/team
+		// exists only on the remote
+		gitRun(t, "remote", "add", "origin", root)
+		gitRun(t, "branch", "feature/gone")
+		gitRun(t, "config", "branch.feature/gone.remote", "origin")
+		gitRun(t, "config", "branch.feature/gone.merge", "refs/heads/feature/gone")
+		gitRun(t, "update-ref", "refs/remotes/origin/feature/team", "HEAD")
+		mustRun(t, "add", "Drop the retry flag", "--branch=feature/gone")
+		mustRun(t, "add", "Teammate's branch", "--branch=feature/team")
+
+		out := mustRun(t, "branch-cleanup", "--dry-run")
+		if !strings.Contains(out, "Would archive 3 items of 2 branches") {
+			t.Errorf("Unexpected dry run output: %s", out)
+		}
+		if byContent(t)["Login tests are flaky"].Archived {
+			t.Fatal("Dry run archived an item")
+		}
+
+		out = mustRun(t, "branch-cleanup")
+		if !strings.Contains(out, "(feature/login merged into main)") || !strings.Contains(out, "(feature/gone deleted upstream)") {
+			t.Errorf("Unexpected output: %s", out)
+		}
+		if byContent(t)["Ask about the old flow"].Archived {
+			t.Error("Item of a branch that never existed here archived without --deleted")
+		}
+
+		out = mustRun(t, "branch-cleanup", "--deleted")
+		if !strings.Contains(out, "(feature/old deleted)") || !strings.Contains(out, "Archived 1 items of 1 branches") {
+			t.Errorf("Unexpected output with --deleted: %s", out)
+		}
+		items := byContent(t)
+		for content, want := range map[string]bool{
+			"Login tests are flaky":    true,
+			"Rename the login handler": true,
+			"Drop the retry flag":      true,
+			"Ask about the old flow":   true,
+			"Plan the new feature":     false,
+			"Teammate's branch":        false,
+			"Global note":              false,
+		} {
+			if items[content].Archived != want {
+				t.Errorf("%q archived = %v, want %v", content, items[content].Archived, want)
+			}
+		}
+	})
+}
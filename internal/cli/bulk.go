@@ -0,0 +1,200 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/query"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// Commands that change items (done, remove, tag and move) take several item
+// references, or select items with the filters of ck list instead. Changes
+// to more than one item, or to items selected by filters, are previewed and
+// need confirmation unless --yes is given.
+
+// bulkHelp describes the selection of items for bulk commands' help texts.
+const bulkHelp = `Several items can be given at once, or selected with --project, --tags and
+--query like in ck list: closed items are left out unless the query mentions
+status, as are items bound to other git branches. Changes to several items
+are listed and need confirmation; use --dry-run to only list them and --yes
+to skip the confirmation.`
+
+// bulkFlags holds the flags of a command that changes several items at once.
+type bulkFlags struct {
+	project string
+	tags    string
+	query   string
+	dryRun  bool
+	yes     bool
+}
+
+// register adds the flags to cmd.
+func (b *bulkFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&b.project, "project", "P", "", "Select the items of a project")
+	cmd.Flags().StringVarP(&b.tags, "tags", "t", "", "Select the items with these tags (comma or space separated)")
+	cmd.Flags().StringVarP(&b.query, "query", "q", "", "Select the items matching a query, as in ck list")
+	cmd.Flags().BoolVar(&b.dryRun, "dry-run", false, "List the items that would change without changing them")
+	cmd.Flags().BoolVarP(&b.yes, "yes", "y", false, "Change several items without asking for confirmation")
+}
+
+// filtered reports whether any filter flag is set.
+func (b *bulkFlags) filtered() bool {
+	return b.project != "" || b.tags != "" || b.query != ""
+}
+
+// single reports whether the command was given exactly one item reference
+// and no filters, so that it runs as before bulk changes existed.
+func (b *bulkFlags) single(args []string) bool {
+	return len(args) == 1 && !b.filtered() && !b.dryRun
+}
+
+// selectItems returns the items the command acts on: the items referenced
+// by args, or the items matching the filter flags, in store order.
+func (b *bulkFlags) selectItems(cmd *cobra.Command, stor storage.Storage, args []string) ([]models.ContextItem, error) {
+	if len(args) > 0 && b.filtered() {
+		return nil, fmt.Errorf("give item references or filters (--project, --tags, --query), not both")
+	}
+
+	if len(args) > 0 {
+		var items []models.ContextItem
+		seen := make(map[string]bool)
+		for _, ref := range args {
+			item, err := lookupItem(cmd, stor, ref)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[item.ID] {
+				seen[item.ID] = true
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+
+	if !b.filtered() {
+		return nil, fmt.Errorf("no items given: give item references, or select items with --project, --tags or --query")
+	}
+	node, err := parseItemQuery([]string{b.query})
+	if err != nil {
+		return nil, err
+	}
+	items := stor.GetAll()
+	if b.project != "" {
+		items = filterByProject(items, b.project)
+	}
+	if b.tags != "" {
+		tags, err := parseTagInput(b.tags)
+		if err != nil {
+			return nil, err
+		}
+		items = filterByTags(items, tags)
+	}
+	if !query.HasField(node, query.FieldStatus) {
+		items = filterActive(items)
+	}
+	if !query.HasField(node, query.FieldBranch) {
+		items = filterBranch(items, git.CurrentBranch(projectRoot(config.FindStoragePath(pathFlag))))
+	}
+	return query.Filter(items, node), nil
+}
+
+// bulkAction describes the change a bulk command makes to each item.
+type bulkAction struct {
+	name     string // Name of the action in JSON output, e.g. "done"
+	question string // Confirmation question, with %d for the number of items
+	result   string // Result message, with %d for the number of items
+	apply    func(item models.ContextItem) error
+}
+
+// bulkItemJSON is an item in the JSON summary of a bulk command.
+type bulkItemJSON struct {
+	ID      string `json:"id"`
+	Number  int    `json:"number,omitempty"`
+	Content string `json:"content"`
+}
+
+// bulkJSON is the JSON summary of a bulk command.
+type bulkJSON struct {
+	Action  string         `json:"action"`
+	DryRun  bool           `json:"dryRun,omitempty"`
+	Changed int            `json:"changed"`
+	Items   []bulkItemJSON `json:"items"`
+}
+
+// run lists the items, asks for confirmation if there are several or they
+// were selected by filters, and applies the action to each item in a single
+// storage batch. It returns the number of items changed.
+func (b *bulkFlags) run(cmd *cobra.Command, stor storage.Storage, items []models.ContextItem, action bulkAction) (int, error) {
+	summary := bulkJSON{Action: action.name, DryRun: b.dryRun, Items: make([]bulkItemJSON, 0, len(items))}
+	for _, item := range items {
+		summary.Items = append(summary.Items, bulkItemJSON{ID: shortID(item.ID), Number: item.Number, Content: item.Content})
+	}
+	printSummary := func() error {
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal summary to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	if len(items) == 0 {
+		if jsonOutput {
+			return 0, printSummary()
+		}
+		cmd.Println("No matching items.")
+		return 0, nil
+	}
+	ask := (len(items) > 1 || b.filtered()) && !b.yes
+	if jsonOutput && ask && !b.dryRun {
+		return 0, fmt.Errorf("--json needs --yes or --dry-run, as it cannot ask for confirmation")
+	}
+
+	if !jsonOutput {
+		for _, item := range items {
+			label := shortID(item.ID)
+			if item.Number > 0 {
+				label = fmt.Sprintf("#%d %s", item.Number, label)
+			}
+			cmd.Printf("  %s  %s\n", label, previewContent(item.Content, 60))
+		}
+	}
+	if b.dryRun {
+		if jsonOutput {
+			return 0, printSummary()
+		}
+		cmd.Printf("Dry run: %d items would change\n", len(items))
+		return 0, nil
+	}
+	if ask && !confirm(cmd, fmt.Sprintf(action.question, len(items))) {
+		cmd.Println("Cancelled.")
+		return 0, nil
+	}
+
+	err := stor.Batch(func() error {
+		for _, item := range items {
+			if err := action.apply(item); err != nil {
+				return fmt.Errorf("failed to change item %s: %w", shortID(item.ID), err)
+			}
+			summary.Changed++
+		}
+		return nil
+	})
+	if err != nil {
+		return summary.Changed, err
+	}
+	if jsonOutput {
+		return summary.Changed, printSummary()
+	}
+	cmd.Printf(action.result+"\n", summary.Changed)
+	return summary.Changed, nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestBulkCommands(t *testing.T) {
+	defer RootCmd.SetIn(nil)
+
+	_, storagePath := testStore(t)
+
+	created := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	closed := created.Add(time.Hour)
+	stor := storage.NewStorage(storagePath)
+	for _, item := range []models.ContextItem{
+		{ID: "aaaa1111-item", Content: "Rate limit the API", Project: "api", Tags: []string{"sprint-12"}, CreatedAt: created},
+		{ID: "bbbb2222-item", Content: "Fix the login redirect", Project: "api", Tags: []string{"sprint-12", "bug"}, CreatedAt: created},
+		{ID: "cccc3333-item", Content: "Restyle the header", Project: "web", Tags: []string{"sprint-12"}, CreatedAt: created},
+		{ID: "dddd4444-item", Content: "Old decision", Project: "api", CreatedAt: created, CompletedAt: &closed},
+		{ID: "eeee5555-item", Content: "Recent decision", Project: "api", CreatedAt: time.Now(), CompletedAt: &closed},
+	} {
+		if err := stor.Add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(t *testing.T, id string) (models.ContextItem, bool) {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		item, err := stor.GetByID(id)
+		return item, err == nil
+	}
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		out := mustExecute(t, "", "done", "--project", "api", "--tags", "sprint-12", "--dry-run")
+		if !strings.Contains(out, "#1 aaaa1111  Rate limit the API") || !strings.Contains(out, "#2 bbbb2222") ||
+			strings.Contains(out, "cccc3333") || !strings.Contains(out, "Dry run: 2 items would change") {
+			t.Errorf("Unexpected preview:\n%s", out)
+		}
+		if item, _ := get(t, "aaaa1111-item"); item.CompletedAt != nil {
+			t.Error("Dry run completed an item")
+		}
+
+		var summary bulkJSON
+		if err := json.Unmarshal([]byte(mustExecute(t, "", "remove", "--query", "status:done created:<2026-01-01", "--dry-run", "--json")), &summary); err != nil {
+			t.Fatal(err)
+		}
+		if !summary.DryRun || summary.Changed != 0 || len(summary.Items) != 1 || summary.Items[0].ID != "dddd4444" {
+			t.Errorf("Unexpected summary: %+v", summary)
+		}
+	})
+
+	t.Run("confirmation", func(t *testing.T) {
+		out := mustExecute(t, "n\n", "done", "--project", "api", "--tags", "sprint-12")
+		if !strings.Contains(out, "Mark 2 items as done? (y/N): ") || !strings.Contains(out, "Cancelled.") {
+			t.Errorf("Expected a declined confirmation, got:\n%s", out)
+		}
+		if item, _ := get(t, "aaaa1111-item"); item.CompletedAt != nil {
+			t.Error("Declined confirmation completed an item")
+		}
+		if _, err := runInput(t, "", "done", "--tags", "sprint-12", "--json"); err == nil || !strings.Contains(err.Error(), "--yes") {
+			t.Errorf("Expected --json to need --yes, got %v", err)
+		}
+
+		out = mustExecute(t, "y\n", "done", "--project", "api", "--tags", "sprint-12")
+		if !strings.Contains(out, "Marked 2 items as done") {
+			t.Errorf("Unexpected output:\n%s", out)
+		}
+		for _, id := range []string{"aaaa1111-item", "bbbb2222-item"} {
+			if item, _ := get(t, id); item.EffectiveState() != models.StateDone {
+				t.Errorf("Expected %s done, got %s", id, item.EffectiveState())
+			}
+		}
+		if out := mustExecute(t, "", "done", "--tags", "sprint-12", "--project", "api"); !strings.Contains(out, "No matching items.") {
+			t.Errorf("Expected closed items left out, got:\n%s", out)
+		}
+	})
+
+	t.Run("several references", func(t *testing.T) {
+		out := mustExecute(t, "", "tag", "3", "cccc", "#1", "--add", "sprint-13", "--remove", "sprint-12", "--yes")
+		if !strings.Contains(out, "Changed the tags of 2 items") {
+			t.Errorf("Unexpected output:\n%s", out)
+		}
+		if item, _ := get(t, "cccc3333-item"); strings.Join(item.Tags, ",") != "sprint-13" {
+			t.Errorf("Tags = %v, want [sprint-13]", item.Tags)
+		}
+
+		// A single item needs no confirmation
+		mustExecute(t, "", "move", "cccc", "--to", "frontend")
+		if item, _ := get(t, "cccc3333-item"); item.Project != "frontend" {
+			t.Errorf("Project = %q, want frontend", item.Project)
+		}
+
+		if _, err := runInput(t, "", "tag", "1", "--tags", "bug", "--add", "x"); err == nil {
+			t.Error("Expected an error for references and filters together")
+		}
+		if _, err := runInput(t, "", "move", "--to", "web"); err == nil || !strings.Contains(err.Error(), "no items given") {
+			t.Errorf("Expected an error without items, got %v", err)
+		}
+		if _, err := runInput(t, "", "tag", "1", "--add", "not valid!"); err == nil {
+			t.Error("Expected an error for an invalid tag")
+		}
+	})
+
+	t.Run("remove with json summary", func(t *testing.T) {
+		var summary bulkJSON
+		if err := json.Unmarshal([]byte(mustExecute(t, "", "remove", "--query", "status:done created:<2026-01-01", "--yes", "--json")), &summary); err != nil {
+			t.Fatal(err)
+		}
+		if summary.Action != "remove" || summary.Changed != 3 || len(summary.Items) != 3 {
+			t.Errorf("Unexpected summary: %+v", summary)
+		}
+		for id, want := range map[string]bool{"aaaa1111-item": false, "dddd4444-item": false, "eeee5555-item": true, "cccc3333-item": true} {
+			if _, ok := get(t, id); ok != want {
+				t.Errorf("%s exists = %v, want %v", id, ok, want)
+			}
+		}
+	})
+}
+
+func TestRetag(t *testing.T) {
+	tests := []struct {
+		tags, add, remove []string
+		want              []string
+		changed           bool
+	}{
+		{tags: []string{"a", "b"}, add: []string{"c"}, want: []string{"a", "b", "c"}, changed: true},
+		{tags: []string{"a", "b"}, add: []string{"a"}, want: []string{"a", "b"}},
+		{tags: []string{"a", "b"}, remove: []string{"a"}, want: []string{"b"}, changed: true},
+		{tags: []string{"a"}, add: []string{"b"}, remove: []string{"a", "z"}, want: []string{"b"}, changed: true},
+		{tags: nil, remove: []string{"a"}, want: nil},
+	}
+	for _, tt := range tests {
+		got, changed := retag(tt.tags, tt.add, tt.remove)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") || changed != tt.changed {
+			t.Errorf("retag(%v, %v, %v) = %v, %v, want %v, %v", tt.tags, tt.add, tt.remove, got, changed, tt.want, tt.changed)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/history"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd compares two versions of the store item by item.
+var diffCmd = &cobra.Command{
+	Use:   "diff [rev1] [rev2]",
+	Short: "Compare two versions of the store",
+	Long: `Compare two versions of the store file (.contextkeeper/items.json) item by
+item: which items were created, completed, reopened, changed or deleted, with
+the changed fields and a line diff of changed content.
+
+Without revisions, the last commit (HEAD) is compared with the working tree.
+With one revision, that commit is compared with the working tree; with two,
+the first commit is compared with the second. Revisions are anything git
+accepts, such as main, HEAD~3 or a commit hash. Use it to review a pull
+request that changes the store: ck diff main HEAD.`,
+	Example: `  # What changed since the last commit?
+  ck diff
+
+  # Review the items changed on this branch
+  ck diff main HEAD
+
+  # Output as JSON
+  ck diff HEAD~1 --json`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: diffCommand,
+}
+
+// diffItemJSON is the JSON form of an item's change.
+type diffItemJSON struct {
+	ID      string              `json:"id"`
+	Content string              `json:"content"`
+	Kind    string              `json:"kind"`
+	Changes []historyChangeJSON `json:"changes,omitempty"`
+}
+
+// diffCommand is the execution function for the diff command.
+func diffCommand(cmd *cobra.Command, args []string) error {
+	itemsPath := itemsFilePath(config.FindStoragePath(pathFlag))
+	oldRev, newRev := "HEAD", ""
+	if len(args) > 0 {
+		oldRev = args[0]
+	}
+	if len(args) > 1 {
+		newRev = args[1]
+	}
+
+	before, err := history.Revision(itemsPath, oldRev)
+	if err != nil {
+		return fmt.Errorf("failed to load the store at %s: %w", oldRev, err)
+	}
+	after, err := history.Revision(itemsPath, newRev)
+	if err != nil {
+		return fmt.Errorf("failed to load the store at %s: %w", orWorkingTree(newRev), err)
+	}
+	diffs := history.Diff(before, after)
+
+	if jsonOutput {
+		out := make([]diffItemJSON, 0, len(diffs))
+		for _, d := range diffs {
+			j := diffItemJSON{ID: d.ID, Content: d.Content, Kind: d.Kind}
+			for _, c := range d.Changes {
+				j.Changes = append(j.Changes, historyChangeJSON{Field: c.Field, Old: c.Old, New: c.New})
+			}
+			out = append(out, j)
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	if len(diffs) == 0 {
+		cmd.Println("No items changed.")
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, d := range diffs {
+		counts[d.Kind]++
+		cmd.Printf("%-9s %s  %s\n", d.Kind, shortID(d.ID), d.Content)
+		for _, c := range d.Changes {
+			switch {
+			case d.Kind == history.KindCreated:
+				if c.Field != "content" {
+					cmd.Printf("    %s: %s\n", c.Field, c.New)
+				}
+			case c.Field == "content":
+				cmd.Println("    content:")
+				for _, line := range history.LineDiff(c.Old, c.New) {
+					cmd.Printf("      %s\n", line)
+				}
+			default:
+				cmd.Printf("    %s: %s -> %s\n", c.Field, orNone(c.Old), orNone(c.New))
+			}
+		}
+	}
+
+	cmd.Printf("\n%d items changed:", len(diffs))
+	sep := " "
+	for _, kind := range []string{history.KindCreated, history.KindCompleted, history.KindReopened, history.KindChanged, history.KindDeleted} {
+		if counts[kind] > 0 {
+			cmd.Printf("%s%d %s", sep, counts[kind], kind)
+			sep = ", "
+		}
+	}
+	cmd.Println()
+	return nil
+}
+
+// orWorkingTree returns rev, or "the working tree" if it is empty.
+func orWorkingTree(rev string) string {
+	if rev == "" {
+		return "the working tree"
+	}
+	return rev
+}
+
+// init registers the diff command with the root command.
+func init() {
+	diffCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	RootCmd.AddCommand(diffCmd)
+}
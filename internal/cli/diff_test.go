@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestDiffCommand(t *testing.T) {
+	root, storagePath := testStore(t)
+
+	commit := func(t *testing.T, msg string) {
+		t.Helper()
+		for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", msg}} {
+			args = append([]string{"-c", "user.name=Ada", "-c", "user.email=ada@example.com"}, args...)
+			if _, err := git.Run(root, args...); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	ids := func(t *testing.T) map[string]string {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		ids := make(map[string]string)
+		for _, item := range stor.GetAll() {
+			ids[item.Content] = item.ID
+		}
+		return ids
+	}
+
+	if _, err := git.Run(root, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "README"), []byte("readme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, "Initial commit")
+	mustRun(t, "add", "Use OIDC\nfor login")
+	mustRun(t, "add", "Old note")
+	mustRun(t, "add", "Rate limit the API")
+	commit(t, "Add items")
+	before := ids(t)
+
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := stor.GetByID(before["Use OIDC\nfor login"])
+	item.Content = "Use OIDC\nfor login and SSO"
+	if err := stor.Update(item); err != nil {
+		t.Fatal(err)
+	}
+	mustRun(t, "done", shortID(before["Rate limit the API"]))
+	mustRun(t, "remove", shortID(before["Old note"]), "--force")
+	mustRun(t, "add", "Document the API", "-p", "api")
+
+	t.Run("working tree against HEAD", func(t *testing.T) {
+		out := mustRun(t, "diff")
+		for _, want := range []string{
+			"changed   " + shortID(before["Use OIDC\nfor login"]),
+			"        Use OIDC\n      - for login\n      + for login and SSO\n",
+			"completed " + shortID(before["Rate limit the API"]),
+			"    state: todo -> done",
+			"deleted   " + shortID(before["Old note"]),
+			"created   ",
+			"    project: api",
+			"4 items changed: 1 created, 1 completed, 1 changed, 1 deleted",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected %q in output:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("between commits", func(t *testing.T) {
+		commit(t, "Update items")
+		if out := mustRun(t, "diff"); !strings.Contains(out, "No items changed.") {
+			t.Errorf("Expected no changes, got: %s", out)
+		}
+
+		var got []diffItemJSON
+		if err := json.Unmarshal([]byte(mustRun(t, "diff", "HEAD~2", "HEAD~1", "--json")), &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 3 {
+			t.Fatalf("Expected 3 created items, got %+v", got)
+		}
+		for _, d := range got {
+			if d.Kind != "created" {
+				t.Errorf("Unexpected kind %q for %q", d.Kind, d.Content)
+			}
+		}
+
+		if _, err := run(t, "diff", "no-such-branch"); err == nil || !strings.Contains(err.Error(), "unknown revision") {
+			t.Errorf("Expected an unknown revision error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,174 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/scan"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd checks the store and the code for broken references.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check for references to missing items",
+	Long: `Check for references to items that do not exist anymore, or never did:
+
+  - "ck:<id>" references in the working tree and in commit messages (see
+    'ck refs') that match no item, or match several items
+  - links to deleted items (see 'ck link')
+  - subtasks of deleted items
+
+The command fails if any problem is found, so it can be used in CI or in
+the pre-commit hook installed by 'ck hooks install'. As references in old
+commits cannot be fixed, --no-history leaves them out.`,
+	Example: `  # Check the project
+  ck doctor
+
+  # Only check the working tree and the store
+  ck doctor --no-history
+
+  # Output as JSON
+  ck doctor --json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         doctorCommand,
+}
+
+// doctorNoHistoryFlag skips references in commit messages.
+var doctorNoHistoryFlag bool
+
+// Kinds of problems reported by the doctor command.
+const (
+	problemDanglingRef   = "dangling-ref"
+	problemAmbiguousRef  = "ambiguous-ref"
+	problemMissingLink   = "missing-link"
+	problemMissingParent = "missing-parent"
+)
+
+// doctorProblem is a problem found by the doctor command.
+type doctorProblem struct {
+	Kind     string `json:"kind"`
+	Location string `json:"location"`
+	ID       string `json:"id"`
+	Message  string `json:"message"`
+}
+
+// doctorCommand is the execution function for the doctor command.
+func doctorCommand(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	var refs []scan.Ref
+	var err error
+	if doctorNoHistoryFlag {
+		refs, err = scan.TreeRefs(projectRoot(storagePath))
+	} else {
+		refs, err = collectRefs(projectRoot(storagePath))
+	}
+	if err != nil {
+		return err
+	}
+	problems := checkRefs(stor, refs)
+	problems = append(problems, checkItemRefs(stor)...)
+
+	if jsonOutput {
+		if problems == nil {
+			problems = []doctorProblem{}
+		}
+		data, err := json.MarshalIndent(problems, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal problems to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	} else {
+		for _, p := range problems {
+			cmd.Printf("%s  %s\n", p.Location, p.Message)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems", len(problems))
+	}
+	if !jsonOutput {
+		cmd.Printf("No problems found (%d references checked).\n", len(refs))
+	}
+	return nil
+}
+
+// checkRefs returns a problem for each reference that does not resolve to
+// exactly one item.
+func checkRefs(stor storage.Storage, refs []scan.Ref) []doctorProblem {
+	var problems []doctorProblem
+	for _, r := range refs {
+		_, err := resolveRef(stor, r.ID)
+		switch {
+		case errors.Is(err, storage.ErrItemNotFound):
+			problems = append(problems, doctorProblem{
+				Kind:     problemDanglingRef,
+				Location: r.Location(),
+				ID:       r.ID,
+				Message:  fmt.Sprintf("ck:%s refers to no item (deleted or unknown)", r.ID),
+			})
+		case errors.Is(err, storage.ErrAmbiguousID):
+			problems = append(problems, doctorProblem{
+				Kind:     problemAmbiguousRef,
+				Location: r.Location(),
+				ID:       r.ID,
+				Message:  fmt.Sprintf("ck:%s is ambiguous (use more characters)", r.ID),
+			})
+		}
+	}
+	return problems
+}
+
+// checkItemRefs returns a problem for each link or parent of a stored item
+// that points to a missing item.
+func checkItemRefs(stor storage.Storage) []doctorProblem {
+	items := stor.GetAll()
+	exists := make(map[string]bool, len(items))
+	for _, item := range items {
+		exists[item.ID] = true
+	}
+
+	var problems []doctorProblem
+	for _, item := range items {
+		location := "item " + shortID(item.ID)
+		if item.ParentID != "" && !exists[item.ParentID] {
+			problems = append(problems, doctorProblem{
+				Kind:     problemMissingParent,
+				Location: location,
+				ID:       item.ParentID,
+				Message:  fmt.Sprintf("is a subtask of deleted item %s", shortID(item.ParentID)),
+			})
+		}
+		for _, l := range item.Links {
+			if !exists[l.Target] {
+				problems = append(problems, doctorProblem{
+					Kind:     problemMissingLink,
+					Location: location,
+					ID:       l.Target,
+					Message:  fmt.Sprintf("%s deleted item %s", l.Type, shortID(l.Target)),
+				})
+			}
+		}
+	}
+	return problems
+}
+
+// init registers the doctor command with the root command.
+func init() {
+	doctorCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	doctorCmd.Flags().BoolVar(&doctorNoHistoryFlag, "no-history", false, "Skip references in commit messages")
+	RootCmd.AddCommand(doctorCmd)
+}
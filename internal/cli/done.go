@@ -6,10 +6,7 @@ package cli
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"os"
-	"strings"
 	"time"
 
 	"github.com/ondrahracek/contextkeeper/internal/config"
@@ -18,81 +15,129 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// doneCmd marks a context item as completed.
+// doneCmd marks context items as completed.
 //
-// The command requires an item ID (can be partial prefix) as an argument.
+// The command takes item IDs (can be partial prefixes), or filters that
+// select the items.
 var doneCmd = &cobra.Command{
-	Use:   "done <id>",
-	Short: "Mark a context item as completed",
-	Long:  "Mark a context item as completed by its ID. Use 'ck list' to see item IDs.",
+	Use:   "done [id...]",
+	Short: "Mark context items as completed",
+	Long:  "Mark context items as completed by their ID. Use 'ck list' to see item IDs. Open subtasks are listed as a warning, or completed too with --cascade.\n\n" + bulkHelp + "\n\n" + itemRefHelp,
 	Example: `  # Mark an item as done (full ID)
   ck done abc12345-def6-7890-1234-567890abcdef
 
   # Mark an item as done (partial ID prefix - at least 6 chars recommended)
-  ck done abc12345`,
-	Args: cobra.ExactArgs(1),
+  ck done abc12345
+
+  # Mark the most recently added item as done
+  ck done @last
+
+  # Mark the item mentioning "auth bug" as done
+  ck done '~auth bug'
+
+  # Complete an item together with all of its open subtasks
+  ck done abc12345 --cascade
+
+  # Mark several items as done
+  ck done 12 14 15
+
+  # Close a sprint, listing the items first
+  ck done --project api --tags sprint-12 --dry-run
+  ck done --project api --tags sprint-12`,
+	Args: cobra.ArbitraryArgs,
 	RunE: doneCommand,
 }
 
 // doneCommand is the execution function for the done command.
-// It finds and marks a context item as completed using prefix matching.
+// It finds and marks context items as completed.
 func doneCommand(cmd *cobra.Command, args []string) error {
-	id := args[0]
-
 	// Initialize storage
 	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
 	if err := stor.Load(); err != nil {
 		return fmt.Errorf("failed to load storage: %w", err)
 	}
 
-	// Try exact match first
-	item, err := stor.GetByID(id)
-	if err == nil {
-		// Exact match found
+	if doneBulk.single(args) {
+		item, err := lookupItem(cmd, stor, args[0])
+		if err != nil {
+			return err
+		}
 		return markItemComplete(stor, cmd, item)
 	}
 
-	// Try prefix match
-	if errors.Is(err, storage.ErrItemNotFound) || errors.Is(err, storage.ErrAmbiguousID) {
-		item, err = stor.GetByPrefix(id)
-		if err != nil {
-			if errors.Is(err, storage.ErrItemNotFound) {
-				return fmt.Errorf("item not found: %s", id)
-			}
-			if errors.Is(err, storage.ErrAmbiguousID) {
-				return showAmbiguousMatches(stor, cmd, id)
+	items, err := doneBulk.selectItems(cmd, stor, args)
+	if err != nil {
+		return err
+	}
+	var open []models.ContextItem
+	seen := make(map[string]bool)
+	for _, item := range items {
+		candidates := []models.ContextItem{item}
+		if doneCascadeFlag {
+			candidates = append(candidates, models.Descendants(stor.GetAll(), item.ID)...)
+		}
+		for _, c := range candidates {
+			if c.CompletedAt == nil && !seen[c.ID] {
+				seen[c.ID] = true
+				open = append(open, c)
 			}
-			return fmt.Errorf("failed to retrieve item by prefix %q: %w", id, err)
 		}
-		// Found unique match
-		return markItemComplete(stor, cmd, item)
 	}
 
-	return fmt.Errorf("failed to retrieve item %q: %w", id, err)
+	now := time.Now()
+	changed, err := doneBulk.run(cmd, stor, open, bulkAction{
+		name:     "done",
+		question: "Mark %d items as done?",
+		result:   "Marked %d items as done",
+		apply: func(item models.ContextItem) error {
+			item.SetState(models.StateDone, "", now)
+			return stor.Update(item)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if changed > 0 && doneSyncFlag {
+		if synced := syncAfterCRUD(cmd.OutOrStdout()); synced > 0 && !jsonOutput {
+			cmd.Printf("Synced %d files\n", synced)
+		}
+	}
+	return nil
 }
 
-// markItemComplete marks an item as completed and saves it to storage.
+// markItemComplete marks an item as completed and saves it to storage. Items
+// that are already done or won't be fixed are rejected.
 func markItemComplete(stor storage.Storage, cmd *cobra.Command, item models.ContextItem) error {
-	now := time.Now()
-	item.CompletedAt = &now
+	if item.CompletedAt != nil {
+		return fmt.Errorf("item %s is already %s", shortID(item.ID), item.EffectiveState())
+	}
+	item.SetState(models.StateDone, "", time.Now())
 
 	if err := stor.Update(item); err != nil {
 		return fmt.Errorf("failed to update item %q: %w", item.ID, err)
 	}
 
+	closed, err := closeSubtasks(stor, cmd, item, models.StateDone, doneCascadeFlag)
+	if err != nil {
+		return err
+	}
+
 	if err := stor.Save(); err != nil {
 		return fmt.Errorf("failed to save item %q: %w", item.ID, err)
 	}
 
 	if jsonOutput {
 		result := map[string]string{
-			"id":     item.ID[:8],
+			"id":     shortID(item.ID),
 			"status": "completed",
 		}
 		data, _ := json.MarshalIndent(result, "", "  ")
 		fmt.Fprintln(cmd.OutOrStdout(), string(data))
 	} else {
-		cmd.Printf("Marked item as completed: %s\n", item.ID[:8])
+		cmd.Printf("Marked item as completed: %s\n", shortID(item.ID))
+		if closed > 0 {
+			cmd.Printf("Also completed %d subtasks\n", closed)
+		}
 	}
 
 	// Sync to files if --sync flag is set
@@ -106,43 +151,67 @@ func markItemComplete(stor storage.Storage, cmd *cobra.Command, item models.Cont
 	return nil
 }
 
-// showAmbiguousMatches shows all items matching the prefix.
-func showAmbiguousMatches(stor storage.Storage, cmd *cobra.Command, prefix string) error {
-	allItems := stor.GetAll()
-	var matches []models.ContextItem
-	for _, item := range allItems {
-		if strings.HasPrefix(item.ID, prefix) {
-			matches = append(matches, item)
+// closeSubtasks deals with the open subtasks (at any depth) of parent, which
+// has just been closed in the given state. With cascade they are closed in
+// the same state; otherwise they are listed in a warning. It returns the
+// number of subtasks closed.
+func closeSubtasks(stor storage.Storage, cmd *cobra.Command, parent models.ContextItem, state string, cascade bool) (int, error) {
+	var open []models.ContextItem
+	for _, item := range models.Descendants(stor.GetAll(), parent.ID) {
+		if item.CompletedAt == nil {
+			open = append(open, item)
 		}
 	}
-
-	if len(matches) <= 1 {
-		return fmt.Errorf("item not found: %s", prefix)
+	if len(open) == 0 {
+		return 0, nil
 	}
 
-	fmt.Fprintf(os.Stderr, "Error: %d items match %q:\n", len(matches), prefix)
-	for _, item := range matches {
-		preview := item.Content
-		if len(preview) > 40 {
-			preview = preview[:40] + "..."
+	if !cascade {
+		errOut := cmd.ErrOrStderr()
+		fmt.Fprintf(errOut, "Warning: %s has %d open subtasks:\n", shortID(parent.ID), len(open))
+		for _, item := range open {
+			preview := item.Content
+			if len(preview) > 40 {
+				preview = preview[:40] + "..."
+			}
+			fmt.Fprintf(errOut, "  - %s: %s\n", shortID(item.ID), preview)
 		}
-		fmt.Fprintf(os.Stderr, "  - %s: %s\n", item.ID[:6], preview)
-	}
-	fmt.Fprintf(os.Stderr, "\nUse more characters to disambiguate:\n")
-	for _, item := range matches {
-		fmt.Fprintf(os.Stderr, "  ck done %s\n", item.ID)
+		fmt.Fprintln(errOut, "Use --cascade to close them as well.")
+		return 0, nil
 	}
 
-	return fmt.Errorf("ambiguous ID: %s", prefix)
+	now := time.Now()
+	err := stor.Batch(func() error {
+		for _, item := range open {
+			item.SetState(state, "", now)
+			if err := stor.Update(item); err != nil {
+				return fmt.Errorf("failed to update subtask %q: %w", item.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(open), nil
 }
 
-// doneSyncFlag triggers sync to AI agent files after marking complete
-var doneSyncFlag bool
+// Command flags for the done command.
+var (
+	// doneSyncFlag triggers sync to AI agent files after marking complete
+	doneSyncFlag bool
+	// doneCascadeFlag also completes the item's open subtasks
+	doneCascadeFlag bool
+	// doneBulk selects several items to complete
+	doneBulk bulkFlags
+)
 
 // init registers the done command with the root command.
 func init() {
 	doneCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
 	doneCmd.Flags().BoolVar(&doneSyncFlag, "sync", false, "Sync to AI agent rule files after marking complete")
+	doneCmd.Flags().BoolVar(&doneCascadeFlag, "cascade", false, "Also complete all open subtasks")
+	doneBulk.register(doneCmd)
 	// Add command to root
 	RootCmd.AddCommand(doneCmd)
 }
@@ -2,9 +2,12 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ondrahracek/contextkeeper/internal/models"
 	"github.com/ondrahracek/contextkeeper/internal/storage"
@@ -318,3 +321,115 @@ func TestDoneCommandSyncFlag(t *testing.T) {
 		}
 	})
 }
+
+func TestSubtasks(t *testing.T) {
+	_, storagePath := testStore(t)
+	stor := storage.NewStorage(storagePath)
+	stor.Add(models.ContextItem{ID: "parent11-item", Content: "Migrate auth to OIDC", Project: "api", CreatedAt: time.Now()})
+	stor.Add(models.ContextItem{ID: "other222-item", Content: "Unrelated note", CreatedAt: time.Now()})
+
+	run := func(t *testing.T, args ...string) (string, string) {
+		t.Helper()
+		out, errOut, err := execute(t, "", args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		return out, errOut
+	}
+	children := func(t *testing.T) []models.ContextItem {
+		t.Helper()
+		stor.Load()
+		return models.Descendants(stor.GetAll(), "parent11-item")
+	}
+
+	t.Run("add --parent links and inherits the project", func(t *testing.T) {
+		run(t, "add", "Register the OIDC client", "--parent", "parent11")
+		run(t, "add", "Switch the login page", "--parent", "parent11")
+		run(t, "add", "Remove the old session code", "--parent", "parent11")
+
+		subtasks := children(t)
+		if len(subtasks) != 3 {
+			t.Fatalf("Expected 3 subtasks, got %d", len(subtasks))
+		}
+		if subtasks[0].Project != "api" {
+			t.Errorf("Expected subtask to inherit project api, got %q", subtasks[0].Project)
+		}
+
+		RootCmd.SetErr(new(bytes.Buffer))
+		RootCmd.SetArgs([]string{"add", "Orphan", "--parent", "nope"})
+		if err := RootCmd.Execute(); err == nil {
+			t.Error("Expected an unknown parent to fail")
+		}
+		resetFlags()
+	})
+
+	t.Run("list --tree nests subtasks and shows rollups", func(t *testing.T) {
+		run(t, "done", children(t)[0].ID)
+
+		out, _ := run(t, "list", "--tree")
+		lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("Expected parent, two open subtasks and the other item, got:\n%s", out)
+		}
+		if !strings.Contains(lines[0], "Migrate auth") || !strings.Contains(lines[0], "(1/3 done)") {
+			t.Errorf("Expected parent with rollup first, got %q", lines[0])
+		}
+		if !strings.HasPrefix(lines[1], "  [ ]") {
+			t.Errorf("Expected indented subtask, got %q", lines[1])
+		}
+
+		var items []map[string]interface{}
+		out, _ = run(t, "list", "--json")
+		if err := json.Unmarshal([]byte(out), &items); err != nil {
+			t.Fatal(err)
+		}
+		subtasks, ok := items[0]["subtasks"].(map[string]interface{})
+		if !ok || subtasks["done"] != 1.0 || subtasks["total"] != 3.0 {
+			t.Errorf("Expected subtasks rollup in JSON, got %v", items[0])
+		}
+		if items[2]["parentId"] != "parent11-item" {
+			t.Errorf("Expected parentId in JSON, got %v", items[2])
+		}
+	})
+
+	t.Run("status and sync show rollups", func(t *testing.T) {
+		out, _ := run(t, "status")
+		if !strings.Contains(out, "Subtasks:    1/3 done across 1 open parents") {
+			t.Errorf("Expected subtask rollup in status, got:\n%s", out)
+		}
+
+		stor.Load()
+		content, err := buildSyncContent(stor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(content, "1/3 done") || !strings.Contains(content, "subtask of parent11") {
+			t.Errorf("Expected rollup and parent in sync content, got:\n%s", content)
+		}
+	})
+
+	t.Run("done warns about open subtasks", func(t *testing.T) {
+		_, errOut := run(t, "done", "parent11")
+		if !strings.Contains(errOut, "2 open subtasks") || !strings.Contains(errOut, "--cascade") {
+			t.Errorf("Expected warning about open subtasks, got %q", errOut)
+		}
+		for _, item := range children(t)[1:] {
+			if item.CompletedAt != nil {
+				t.Errorf("Expected subtask %s to stay open", item.ID)
+			}
+		}
+		run(t, "reopen", "parent11")
+	})
+
+	t.Run("done --cascade completes open subtasks", func(t *testing.T) {
+		out, _ := run(t, "done", "parent11", "--cascade")
+		if !strings.Contains(out, "Also completed 2 subtasks") {
+			t.Errorf("Unexpected output: %q", out)
+		}
+		for _, item := range children(t) {
+			if item.EffectiveState() != models.StateDone {
+				t.Errorf("Expected subtask %s to be done, got %s", item.ID, item.EffectiveState())
+			}
+		}
+	})
+}
@@ -5,10 +5,15 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
 	"github.com/ondrahracek/contextkeeper/internal/storage"
 	"github.com/ondrahracek/contextkeeper/internal/utils"
 	"github.com/spf13/cobra"
@@ -16,23 +21,73 @@ import (
 
 // editCmd edits an existing context item.
 //
-// The command opens the system editor with the current content,
-// allowing modifications to the item's text.
+// The command changes the fields given as flags, or opens the system
+// editor with the whole item, allowing modifications to all of its fields.
 var editCmd = &cobra.Command{
 	Use:   "edit <id>",
 	Short: "Edit a context item",
-	Long:  "Edit a context item using the system editor. Opens the current content for modification.",
-	Example: `  # Edit an item
-  ck edit abc12345`,
+	Long: `Edit a context item. Flags change single fields without an editor, for
+scripts and agents; without them, or with --editor, the item opens in the
+system editor as front matter followed by the content:
+
+  ---
+  project: api
+  tags: auth, decision
+  priority: high
+  due: 2026-11-01
+  state: todo
+  pinned: no
+  branch:
+  ---
+
+  Switch the login to OIDC.
+
+Empty values clear a field and removed lines leave it unchanged. Text
+without front matter replaces the content only.
+
+` + itemRefHelp,
+	Example: `  # Edit an item in the editor
+  ck edit abc12345
+
+  # Change fields without an editor
+  ck edit 12 --project web --add-tag ui --remove-tag backend --due friday
+  ck edit 12 --content "Switch the login to OIDC" --priority none
+
+  # Read the content from standard input
+  git log -1 --format=%B | ck edit 12 --content -`,
 	Args: cobra.ExactArgs(1),
 	RunE: editCommand,
 }
 
-// editSyncFlag triggers sync to AI agent files after editing
-var editSyncFlag bool
+// Command flags for the edit command.
+var (
+	// editSyncFlag triggers sync to AI agent files after editing
+	editSyncFlag bool
+	// editEditorFlag opens the editor even when fields are given as flags
+	editEditorFlag bool
+	// editContentFlag replaces the content; "-" reads it from standard input
+	editContentFlag string
+	// editProjectFlag replaces the project; "" removes it
+	editProjectFlag string
+	// editAddTagFlag lists tags to add
+	editAddTagFlag string
+	// editRemoveTagFlag lists tags to remove
+	editRemoveTagFlag string
+	// editPriorityFlag replaces the priority; "none" removes it
+	editPriorityFlag string
+	// editDueFlag replaces the due date; "none" removes it
+	editDueFlag string
+)
+
+// editFieldFlags are the flags that change fields of the item.
+var editFieldFlags = []string{"content", "project", "add-tag", "remove-tag", "priority", "due"}
+
+// openEditor lets the user edit text. Tests replace it.
+var openEditor = utils.OpenEditor
 
 // editCommand is the execution function for the edit command.
-// It finds an item, opens it in the editor, and saves changes.
+// It finds an item, applies the changes given as flags or made in the
+// editor, and saves them.
 func editCommand(cmd *cobra.Command, args []string) error {
 	id := args[0]
 
@@ -42,45 +97,64 @@ func editCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load storage: %w", err)
 	}
 
-	// Get all items and find the item index
-	allItems := stor.GetAll()
-	var itemIndex = -1
-	var originalContent string
-
-	for i, item := range allItems {
-		// Match by prefix
-		if strings.HasPrefix(item.ID, id) {
-			itemIndex = i
-			originalContent = item.Content
-			break
-		}
+	item, err := lookupItem(cmd, stor, id)
+	if err != nil {
+		return err
 	}
+	original := item
+	now := time.Now()
 
-	if itemIndex == -1 {
-		return fmt.Errorf("item not found: %s", id)
+	flagged := false
+	for _, name := range editFieldFlags {
+		flagged = flagged || cmd.Flags().Changed(name)
 	}
-
-	// Open editor with current content
-	newContent, err := utils.OpenEditor(originalContent)
-	if err != nil {
-		return fmt.Errorf("failed to open editor: %w", err)
+	if item, err = applyEditFlags(cmd, item, now); err != nil {
+		return err
 	}
 
-	// Update the item
-	allItems[itemIndex].Content = newContent
+	if editEditorFlag || !flagged {
+		// Open editor with the whole item
+		text, err := openEditor(formatItemFile(item))
+		if err != nil {
+			return fmt.Errorf("failed to open editor: %w", err)
+		}
+		if item, err = parseItemFile(text, item, now); err != nil {
+			return err
+		}
+	}
 
-	// Save the storage
-	stor.SetItems(allItems)
-	if err := stor.Save(); err != nil {
-		return fmt.Errorf("failed to save storage: %w", err)
+	changed := changedFields(original, item)
+	if item.Project != original.Project {
+		warnSimilarProject(cmd, stor.GetAll(), item.Project)
+	}
+	if len(changed) > 0 {
+		if err := stor.Update(item); err != nil {
+			return fmt.Errorf("failed to save storage: %w", err)
+		}
 	}
 
-	cmd.Printf("Updated item: %s\n", id[:8])
+	if jsonOutput {
+		result := map[string]interface{}{
+			"id":      shortID(item.ID),
+			"status":  "updated",
+			"changed": changed,
+		}
+		if len(changed) == 0 {
+			result["status"] = "unchanged"
+			result["changed"] = []string{}
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	} else if len(changed) == 0 {
+		cmd.Printf("No changes to item: %s\n", shortID(item.ID))
+	} else {
+		cmd.Printf("Updated item: %s (%s)\n", shortID(item.ID), strings.Join(changed, ", "))
+	}
 
 	// Sync to files if --sync flag is set
-	if editSyncFlag {
+	if editSyncFlag && len(changed) > 0 {
 		synced := syncAfterCRUD(cmd.OutOrStdout())
-		if synced > 0 {
+		if synced > 0 && !jsonOutput {
 			cmd.Printf("Synced %d files\n", synced)
 		}
 	}
@@ -88,10 +162,233 @@ func editCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// applyEditFlags applies the field flags given to the edit command to item.
+func applyEditFlags(cmd *cobra.Command, item models.ContextItem, now time.Time) (models.ContextItem, error) {
+	flags := cmd.Flags()
+	if flags.Changed("content") {
+		content := editContentFlag
+		if content == "-" {
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return item, err
+			}
+			content = string(data)
+		}
+		if content = strings.TrimSpace(content); content == "" {
+			return item, fmt.Errorf("content cannot be empty")
+		}
+		item.Content = content
+	}
+	if flags.Changed("project") {
+		item.Project = strings.TrimSpace(editProjectFlag)
+	}
+	if flags.Changed("add-tag") || flags.Changed("remove-tag") {
+		add, err := parseTagInput(editAddTagFlag)
+		if err != nil {
+			return item, err
+		}
+		remove, err := parseTagInput(editRemoveTagFlag)
+		if err != nil {
+			return item, err
+		}
+		if err := utils.ValidateTags(add); err != nil {
+			return item, err
+		}
+		item.Tags, _ = retag(item.Tags, add, remove)
+	}
+	if flags.Changed("priority") {
+		priority, err := utils.ParsePriority(editPriorityFlag)
+		if err != nil {
+			return item, err
+		}
+		item.Priority = priority
+	}
+	if flags.Changed("due") {
+		due, err := parseEditDue(editDueFlag, now)
+		if err != nil {
+			return item, err
+		}
+		item.DueAt = due
+	}
+	return item, nil
+}
+
+// parseEditDue parses a due date given to the edit command, where "none" or
+// an empty value removes it.
+func parseEditDue(s string, now time.Time) (*time.Time, error) {
+	if s = strings.TrimSpace(s); s == "" || strings.EqualFold(s, "none") {
+		return nil, nil
+	}
+	due, err := utils.ParseDue(s, now)
+	if err != nil {
+		return nil, err
+	}
+	return &due, nil
+}
+
+// formatItemFile formats item for the editor: its fields as front matter,
+// then its content.
+func formatItemFile(item models.ContextItem) string {
+	due := ""
+	if item.DueAt != nil {
+		due = item.DueAt.Format("2006-01-02")
+	}
+	pinned := "no"
+	if item.Pinned {
+		pinned = "yes"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, field := range [][2]string{
+		{"project", item.Project},
+		{"tags", strings.Join(item.Tags, ", ")},
+		{"priority", item.Priority},
+		{"due", due},
+		{"state", item.EffectiveState()},
+		{"pinned", pinned},
+		{"branch", item.Branch},
+	} {
+		fmt.Fprintf(&sb, "%s: %s\n", field[0], field[1])
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString(item.Content)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// parseItemFile applies text edited in the format of formatItemFile to
+// item, validating every field before changing any.
+func parseItemFile(text string, item models.ContextItem, now time.Time) (models.ContextItem, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	body := text
+	fields := make(map[string]string)
+	if strings.HasPrefix(text, "---\n") {
+		header, rest, ok := strings.Cut(text[len("---\n"):], "\n---")
+		if !ok {
+			return item, fmt.Errorf("front matter is not closed with ---")
+		}
+		for n, line := range strings.Split(header, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return item, fmt.Errorf("front matter line %d: expected \"field: value\", got %q", n+2, line)
+			}
+			fields[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+		body = strings.TrimPrefix(rest, "\n")
+	}
+
+	if body = strings.TrimSpace(body); body == "" {
+		return item, fmt.Errorf("content cannot be empty")
+	}
+	item.Content = body
+
+	for key, value := range fields {
+		switch key {
+		case "project":
+			item.Project = value
+		case "tags":
+			tags, err := parseTagInput(value)
+			if err != nil {
+				return item, err
+			}
+			if err := utils.ValidateTags(tags); err != nil {
+				return item, err
+			}
+			item.Tags = tags
+		case "priority":
+			priority, err := utils.ParsePriority(value)
+			if err != nil {
+				return item, err
+			}
+			item.Priority = priority
+		case "due":
+			if item.DueAt != nil && value == item.DueAt.Format("2006-01-02") {
+				continue
+			}
+			due, err := parseEditDue(value, now)
+			if err != nil {
+				return item, err
+			}
+			item.DueAt = due
+		case "state":
+			if _, ok := stateLabels[value]; !ok {
+				return item, fmt.Errorf("invalid state %q: use todo, in-progress, blocked, done or wontfix", value)
+			}
+		case "pinned":
+			pinned, err := parseYesNo(value)
+			if err != nil {
+				return item, fmt.Errorf("invalid pinned value %q: use yes or no", value)
+			}
+			item.Pinned = pinned
+		case "branch":
+			item.Branch = value
+		default:
+			return item, fmt.Errorf("unknown field %q in front matter", key)
+		}
+	}
+	// The state last, once everything is valid, as it records a transition
+	if state, ok := fields["state"]; ok && state != item.EffectiveState() {
+		item.SetState(state, "", now)
+	}
+	return item, nil
+}
+
+// parseYesNo parses a yes/no value, also accepting true/false and 1/0.
+func parseYesNo(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y":
+		return true, nil
+	case "no", "n", "":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// changedFields returns the names of the fields that differ between the
+// items, as used in the editor's front matter.
+func changedFields(old, new models.ContextItem) []string {
+	day := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	}
+	var changed []string
+	for _, f := range []struct {
+		name string
+		diff bool
+	}{
+		{"content", old.Content != new.Content},
+		{"project", old.Project != new.Project},
+		{"tags", strings.Join(old.Tags, ",") != strings.Join(new.Tags, ",")},
+		{"priority", old.Priority != new.Priority},
+		{"due", day(old.DueAt) != day(new.DueAt)},
+		{"state", old.EffectiveState() != new.EffectiveState()},
+		{"pinned", old.Pinned != new.Pinned},
+		{"branch", old.Branch != new.Branch},
+	} {
+		if f.diff {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
+
 // init registers the edit command with the root command.
 func init() {
 	editCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
 	editCmd.Flags().BoolVar(&editSyncFlag, "sync", false, "Sync to AI agent rule files after editing")
+	editCmd.Flags().BoolVarP(&editEditorFlag, "editor", "e", false, "Open the whole item in the editor, after applying other flags")
+	editCmd.Flags().StringVarP(&editContentFlag, "content", "c", "", "New content, or - to read it from standard input")
+	editCmd.Flags().StringVarP(&editProjectFlag, "project", "p", "", "New project, or \"\" to remove it")
+	editCmd.Flags().StringVar(&editAddTagFlag, "add-tag", "", "Tags to add (comma or space separated)")
+	editCmd.Flags().StringVar(&editRemoveTagFlag, "remove-tag", "", "Tags to remove (comma or space separated)")
+	editCmd.Flags().StringVar(&editPriorityFlag, "priority", "", "New priority: high, medium, low or none")
+	editCmd.Flags().StringVar(&editDueFlag, "due", "", "New due date: YYYY-MM-DD, today, tomorrow, +3d, +2w, a weekday or none")
 	// Add command to root
 	RootCmd.AddCommand(editCmd)
 }
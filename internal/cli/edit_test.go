@@ -2,9 +2,12 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ondrahracek/contextkeeper/internal/models"
 	"github.com/ondrahracek/contextkeeper/internal/storage"
@@ -246,3 +249,138 @@ func setupEditSyncTestForFunc(t *testing.T) (string, string, func()) {
 	os.Setenv("CK_STORAGE_PATH", storagePath)
 	return tmpDir, storagePath, cleanup
 }
+
+func TestEditFields(t *testing.T) {
+	defer func(open func(string) (string, error)) { openEditor = open }(openEditor)
+	defer RootCmd.SetIn(nil)
+
+	_, storagePath := testStore(t)
+	due := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Add(models.ContextItem{
+		ID: "login111-item", Content: "Use OIDC", Project: "api", Tags: []string{"auth", "backend"}, DueAt: &due, CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(t *testing.T) models.ContextItem {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		item, err := stor.GetByID("login111-item")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return item
+	}
+	openEditor = func(string) (string, error) {
+		t.Fatal("Unexpected editor")
+		return "", nil
+	}
+
+	t.Run("flags change fields without an editor", func(t *testing.T) {
+		out, err := runInput(t, "", "edit", "login", "--project", "web", "--add-tag", "ui", "--remove-tag", "backend", "--due", "none", "--priority", "high")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != "Updated item: login111 (project, tags, priority, due)\n" {
+			t.Errorf("Unexpected output: %q", out)
+		}
+		item := get(t)
+		if item.Project != "web" || strings.Join(item.Tags, ",") != "auth,ui" || item.Priority != models.PriorityHigh || item.DueAt != nil || item.Content != "Use OIDC" {
+			t.Errorf("Unexpected item: %+v", item)
+		}
+
+		out, err = runInput(t, "Use OIDC for login\n", "edit", "login", "--content", "-", "--json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result struct {
+			Status  string   `json:"status"`
+			Changed []string `json:"changed"`
+		}
+		if err := json.Unmarshal([]byte(out), &result); err != nil || result.Status != "updated" || strings.Join(result.Changed, ",") != "content" {
+			t.Errorf("Unexpected JSON %q: %v", out, err)
+		}
+		if item := get(t); item.Content != "Use OIDC for login" {
+			t.Errorf("Content = %q", item.Content)
+		}
+	})
+
+	t.Run("invalid flags change nothing", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"--add-tag", "not valid!"},
+			{"--priority", "someday"},
+			{"--due", "someday"},
+			{"--content", " "},
+		} {
+			if _, err := runInput(t, "", append([]string{"edit", "login", "--project", "other"}, args...)...); err == nil {
+				t.Errorf("%v: expected an error", args)
+			}
+		}
+		if item := get(t); item.Project != "web" {
+			t.Errorf("Project = %q, want web", item.Project)
+		}
+	})
+
+	t.Run("editor with front matter", func(t *testing.T) {
+		var shown string
+		openEditor = func(text string) (string, error) {
+			shown = text
+			text = strings.Replace(text, "tags: auth, ui\n", "tags: auth, ui, oidc\n", 1)
+			text = strings.Replace(text, "state: todo\n", "state: in-progress\n", 1)
+			text = strings.Replace(text, "pinned: no\n", "pinned: yes\n", 1)
+			text = strings.Replace(text, "due: \n", "due: 2026-12-24\n", 1)
+			return strings.Replace(text, "Use OIDC for login", "# Login\n\nUse OIDC for login", 1), nil
+		}
+		out, err := runInput(t, "", "edit", "login")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "---\nproject: web\ntags: auth, ui\npriority: high\ndue: \nstate: todo\npinned: no\nbranch: \n---\n\nUse OIDC for login\n"
+		if shown != want {
+			t.Errorf("Editor text = %q, want %q", shown, want)
+		}
+		if out != "Updated item: login111 (content, tags, due, state, pinned)\n" {
+			t.Errorf("Unexpected output: %q", out)
+		}
+		item := get(t)
+		if item.Content != "# Login\n\nUse OIDC for login" || item.EffectiveState() != models.StateInProgress || !item.Pinned ||
+			item.DueAt == nil || item.DueAt.Format("2006-01-02") != "2026-12-24" || len(item.Transitions) != 1 {
+			t.Errorf("Unexpected item: %+v", item)
+		}
+
+		openEditor = func(string) (string, error) { return "Only new content\n", nil }
+		if _, err := runInput(t, "", "edit", "login"); err != nil {
+			t.Fatal(err)
+		}
+		if item := get(t); item.Content != "Only new content" || item.Project != "web" || !item.Pinned {
+			t.Errorf("Expected only the content changed, got %+v", item)
+		}
+
+		openEditor = func(text string) (string, error) { return text, nil }
+		if out, err := runInput(t, "", "edit", "login"); err != nil || out != "No changes to item: login111\n" {
+			t.Errorf("Expected no changes, got %q, %v", out, err)
+		}
+	})
+
+	t.Run("invalid front matter changes nothing", func(t *testing.T) {
+		for _, text := range []string{
+			"---\ntags: not valid!\n---\n\nText",
+			"---\nstate: finished\n---\n\nText",
+			"---\ncolour: red\n---\n\nText",
+			"---\nproject: x\n\nText",
+			"---\nproject: x\n---\n\n",
+		} {
+			openEditor = func(string) (string, error) { return text, nil }
+			if _, err := runInput(t, "", "edit", "login"); err == nil {
+				t.Errorf("%q: expected an error", text)
+			}
+		}
+		if item := get(t); item.Content != "Only new content" || item.Project != "web" {
+			t.Errorf("Unexpected item: %+v", item)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/query"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// graphCmd exports the relationships between items as a graph.
+var graphCmd = &cobra.Command{
+	Use:   "graph [query]",
+	Short: "Export the item relationship graph",
+	Long: `Export the links between items (see 'ck link') and their subtask relationships
+as a Graphviz DOT or Mermaid graph.
+
+Only items with at least one relationship to another included item are
+shown. As with 'ck list', closed items are left out unless --all is given or
+the query mentions status.`,
+	Example: `  # Render with Graphviz
+  ck graph | dot -Tsvg > graph.svg
+
+  # Mermaid, for Markdown design docs
+  ck graph --format mermaid
+
+  # Only one project, including closed items
+  ck graph project:api --all`,
+	Args: cobra.ArbitraryArgs,
+	RunE: graphCommand,
+}
+
+// Command flags for the graph command.
+var (
+	graphFormatFlag string // --format: dot or mermaid
+	graphAllFlag    bool   // --all: Include closed items
+)
+
+// Graph output formats.
+const (
+	graphFormatDot     = "dot"
+	graphFormatMermaid = "mermaid"
+)
+
+// graphEdge is a relationship from one item to another.
+type graphEdge struct {
+	From, To string
+	Label    string
+	Subtask  bool // The edge links a subtask to its parent
+}
+
+// graphCommand is the execution function for the graph command.
+func graphCommand(cmd *cobra.Command, args []string) error {
+	if graphFormatFlag != graphFormatDot && graphFormatFlag != graphFormatMermaid {
+		return fmt.Errorf("invalid format %q: must be dot or mermaid", graphFormatFlag)
+	}
+	node, err := parseItemQuery(args)
+	if err != nil {
+		return err
+	}
+
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	items := stor.GetAll()
+	if !graphAllFlag && !query.HasField(node, query.FieldStatus) {
+		items = filterActive(items)
+	}
+	items = query.Filter(items, node)
+
+	nodes, edges := buildGraph(items)
+	if graphFormatFlag == graphFormatMermaid {
+		fmt.Fprint(cmd.OutOrStdout(), formatMermaid(nodes, edges))
+	} else {
+		fmt.Fprint(cmd.OutOrStdout(), formatDot(nodes, edges))
+	}
+	return nil
+}
+
+// buildGraph returns the edges between items and the items that have at
+// least one of them, in their original order.
+func buildGraph(items []models.ContextItem) ([]models.ContextItem, []graphEdge) {
+	included := make(map[string]bool, len(items))
+	for _, item := range items {
+		included[item.ID] = true
+	}
+
+	var edges []graphEdge
+	connected := make(map[string]bool)
+	add := func(e graphEdge) {
+		if !included[e.From] || !included[e.To] || e.From == e.To {
+			return
+		}
+		edges = append(edges, e)
+		connected[e.From] = true
+		connected[e.To] = true
+	}
+	for _, item := range items {
+		if item.ParentID != "" {
+			add(graphEdge{From: item.ID, To: item.ParentID, Label: "subtask of", Subtask: true})
+		}
+		for _, l := range item.Links {
+			add(graphEdge{From: item.ID, To: l.Target, Label: l.Type})
+		}
+	}
+
+	var nodes []models.ContextItem
+	for _, item := range items {
+		if connected[item.ID] {
+			nodes = append(nodes, item)
+		}
+	}
+	return nodes, edges
+}
+
+// graphLabel returns the label of an item's node: its short ID and the
+// start of its content.
+func graphLabel(item models.ContextItem) string {
+	content := strings.Join(strings.Fields(item.Content), " ")
+	if len(content) > 40 {
+		content = content[:37] + "..."
+	}
+	return shortID(item.ID) + ": " + content
+}
+
+// formatDot formats the graph in the Graphviz DOT language. Closed items are
+// drawn in gray and subtask edges dashed.
+func formatDot(nodes []models.ContextItem, edges []graphEdge) string {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph contextkeeper {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, item := range nodes {
+		attrs := "label=" + quote(graphLabel(item))
+		if item.CompletedAt != nil {
+			attrs += ", color=gray, fontcolor=gray"
+		}
+		fmt.Fprintf(&sb, "  %s [%s];\n", quote(shortID(item.ID)), attrs)
+	}
+	for _, e := range edges {
+		attrs := "label=" + quote(e.Label)
+		if e.Subtask {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&sb, "  %s -> %s [%s];\n", quote(shortID(e.From)), quote(shortID(e.To)), attrs)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// formatMermaid formats the graph as a Mermaid flowchart. Subtask edges are
+// dotted.
+func formatMermaid(nodes []models.ContextItem, edges []graphEdge) string {
+	id := func(itemID string) string {
+		return "n" + strings.ReplaceAll(shortID(itemID), "-", "_")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	for _, item := range nodes {
+		label := strings.ReplaceAll(graphLabel(item), `"`, "#quot;")
+		fmt.Fprintf(&sb, "  %s[\"%s\"]\n", id(item.ID), label)
+	}
+	for _, e := range edges {
+		arrow := "-->"
+		if e.Subtask {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&sb, "  %s %s|%s| %s\n", id(e.From), arrow, e.Label, id(e.To))
+	}
+	return sb.String()
+}
+
+// init registers the graph command with the root command.
+func init() {
+	graphCmd.Flags().StringVarP(&graphFormatFlag, "format", "f", graphFormatDot, "Output format: dot or mermaid")
+	graphCmd.Flags().BoolVarP(&graphAllFlag, "all", "a", false, "Include closed items")
+	RootCmd.AddCommand(graphCmd)
+}
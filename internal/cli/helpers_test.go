@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// testStore points the commands at a new, empty store in the default layout:
+// a .contextkeeper directory in a temporary project root, as
+// config.FindStoragePath finds it in a repository. It returns the project
+// root and the store directory.
+func testStore(t *testing.T) (root, storagePath string) {
+	t.Helper()
+	root = t.TempDir()
+	storagePath = filepath.Join(root, ".contextkeeper")
+	if err := os.MkdirAll(storagePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CK_STORAGE_PATH", storagePath)
+	t.Cleanup(resetFlags)
+	return root, storagePath
+}
+
+// resetFlags sets the flags of all commands back to their defaults. Cobra
+// keeps the values of flags between runs of RootCmd.
+func resetFlags() {
+	var reset func(cmd *cobra.Command)
+	reset = func(cmd *cobra.Command) {
+		for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) {
+				if v, ok := f.Value.(pflag.SliceValue); ok {
+					v.Replace(nil)
+				} else {
+					f.Value.Set(f.DefValue)
+				}
+				f.Changed = false
+			})
+		}
+		for _, child := range cmd.Commands() {
+			reset(child)
+		}
+	}
+	reset(RootCmd)
+}
+
+// execute runs ck with args, and input on its standard input, after
+// resetting the flags. It returns the standard output and standard error.
+func execute(t *testing.T, input string, args ...string) (string, string, error) {
+	t.Helper()
+	resetFlags()
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	RootCmd.SetOut(out)
+	RootCmd.SetErr(errOut)
+	RootCmd.SetIn(strings.NewReader(input))
+	RootCmd.SetArgs(args)
+	err := RootCmd.Execute()
+	return out.String(), errOut.String(), err
+}
+
+// runInput runs ck with args and input on its standard input, and returns
+// its standard output.
+func runInput(t *testing.T, input string, args ...string) (string, error) {
+	t.Helper()
+	out, _, err := execute(t, input, args...)
+	return out, err
+}
+
+// mustExecute is execute for commands that must succeed; it returns the
+// standard output.
+func mustExecute(t *testing.T, input string, args ...string) string {
+	t.Helper()
+	out, _, err := execute(t, input, args...)
+	if err != nil {
+		t.Fatalf("%v failed: %v", args, err)
+	}
+	return out
+}
+
+// run runs ck with args and returns its standard output.
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	out, _, err := execute(t, "", args...)
+	return out, err
+}
+
+// mustRun runs ck with args, which must succeed, and returns its standard
+// output.
+func mustRun(t *testing.T, args ...string) string {
+	t.Helper()
+	return mustExecute(t, "", args...)
+}
@@ -0,0 +1,176 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/history"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd shows how an item changed over the git history of the store.
+var historyCmd = &cobra.Command{
+	Use:   "history <id>",
+	Short: "Show how an item changed in the git history",
+	Long: `Show how an item changed over time, reconstructed from the git history of
+the store file (.contextkeeper/items.json): when it was created, by which
+commit and author, each edit of its fields, and when it was completed,
+reopened or deleted.
+
+Changes to the store that are not committed yet are listed last. Deleted
+items can be shown too: the ID is also resolved against every item found in
+the history.`,
+	Example: `  # How did this decision evolve?
+  ck history 5299c5
+
+  # Output as JSON
+  ck history 5299c5 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: historyCommand,
+}
+
+// historyEventJSON is the JSON form of a history event.
+type historyEventJSON struct {
+	Commit  string              `json:"commit,omitempty"`
+	Author  string              `json:"author,omitempty"`
+	Date    *time.Time          `json:"date,omitempty"`
+	Subject string              `json:"subject,omitempty"`
+	Kind    string              `json:"kind"`
+	Changes []historyChangeJSON `json:"changes,omitempty"`
+}
+
+// historyChangeJSON is the JSON form of a changed field.
+type historyChangeJSON struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// historyJSON is the JSON output of the history command.
+type historyJSON struct {
+	ID      string             `json:"id"`
+	Content string             `json:"content"`
+	Events  []historyEventJSON `json:"events"`
+}
+
+// loadHistory returns the snapshots of the items file of the store at
+// storagePath, which may be the store directory or the items file.
+func loadHistory(storagePath string) ([]history.Snapshot, error) {
+	snapshots, err := history.Load(itemsFilePath(storagePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history of the store: %w", err)
+	}
+	return snapshots, nil
+}
+
+// historyItemID resolves id like lookupItem, falling back to the IDs of the
+// items found in the history so that deleted items can be looked up.
+func historyItemID(cmd *cobra.Command, stor storage.Storage, snapshots []history.Snapshot, id string) (string, error) {
+	item, err := lookupItem(cmd, stor, id)
+	if errors.Is(err, storage.ErrItemNotFound) {
+		if full, ok := history.Resolve(snapshots, id); ok {
+			return full, nil
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return item.ID, nil
+}
+
+// commitLabel describes the commit of an event or snapshot in text output.
+func commitLabel(c *git.Commit) string {
+	if c == nil {
+		return "uncommitted"
+	}
+	return fmt.Sprintf("%s  %s  %s", c.ShortHash(), c.Date.Local().Format("2006-01-02 15:04"), c.Author)
+}
+
+// historyCommand is the execution function for the history command.
+func historyCommand(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	snapshots, err := loadHistory(storagePath)
+	if err != nil {
+		return err
+	}
+	id, err := historyItemID(cmd, stor, snapshots, args[0])
+	if err != nil {
+		return err
+	}
+
+	var content string
+	for _, s := range snapshots {
+		if item, ok := s.Items[id]; ok {
+			content = item.Content
+		}
+	}
+	events := history.ItemHistory(snapshots, id)
+
+	if jsonOutput {
+		out := historyJSON{ID: id, Content: content, Events: make([]historyEventJSON, 0, len(events))}
+		for _, e := range events {
+			j := historyEventJSON{Kind: e.Kind}
+			if e.Commit != nil {
+				date := e.Commit.Date
+				j.Commit, j.Author, j.Date, j.Subject = e.Commit.Hash, e.Commit.Author, &date, e.Commit.Subject()
+			}
+			for _, c := range e.Changes {
+				j.Changes = append(j.Changes, historyChangeJSON{Field: c.Field, Old: c.Old, New: c.New})
+			}
+			out.Events = append(out.Events, j)
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal history to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	cmd.Printf("History of %s: %s\n", shortID(id), content)
+	if len(events) == 0 {
+		cmd.Println("\nNo changes found. Commit .contextkeeper/items.json to record the history of items.")
+		return nil
+	}
+	for _, e := range events {
+		cmd.Printf("\n%s  %s\n", commitLabel(e.Commit), e.Kind)
+		if e.Commit != nil {
+			cmd.Printf("    %s\n", e.Commit.Subject())
+		}
+		for _, c := range e.Changes {
+			if e.Kind == history.KindCreated || e.Kind == history.KindRestored {
+				cmd.Printf("    %s: %s\n", c.Field, c.New)
+				continue
+			}
+			cmd.Printf("    %s: %s -> %s\n", c.Field, orNone(c.Old), orNone(c.New))
+		}
+	}
+	return nil
+}
+
+// orNone returns value, or "(none)" if it is empty.
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// init registers the history command with the root command.
+func init() {
+	historyCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	RootCmd.AddCommand(historyCmd)
+}
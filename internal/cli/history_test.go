@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestHistoryAndBlame(t *testing.T) {
+	root, storagePath := testStore(t)
+
+	commit := func(t *testing.T, author, msg string) {
+		t.Helper()
+		for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", msg}} {
+			args = append([]string{"-c", "user.name=" + author, "-c", "user.email=dev@example.com"}, args...)
+			if _, err := git.Run(root, args...); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	setContent := func(t *testing.T, id, content string) {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		item, err := stor.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		item.Content = content
+		if err := stor.Update(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	idOf := func(t *testing.T, content string) string {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range stor.GetAll() {
+			if item.Content == content {
+				return item.ID
+			}
+		}
+		t.Fatalf("No item %q", content)
+		return ""
+	}
+
+	mustRun(t, "add", "Use OIDC")
+	if _, err := run(t, "blame"); err == nil || !strings.Contains(err.Error(), "not a git repository") {
+		t.Errorf("Expected a not a git repository error, got %v", err)
+	}
+
+	if _, err := git.Run(root, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+	commit(t, "Ada", "Add login decision")
+	login := idOf(t, "Use OIDC")
+	mustRun(t, "add", "Temporary note")
+	note := idOf(t, "Temporary note")
+	commit(t, "Ada", "Add note")
+	setContent(t, login, "Use OIDC for login")
+	commit(t, "Bob", "Clarify login decision")
+	mustRun(t, "done", shortID(login))
+	mustRun(t, "remove", shortID(note), "--force")
+	commit(t, "Cy", "Close login, drop note")
+	mustRun(t, "add", "Rate limit the API")
+
+	t.Run("history", func(t *testing.T) {
+		out := mustRun(t, "history", shortID(login)[:6])
+		for _, want := range []string{
+			"History of " + shortID(login) + ": Use OIDC for login",
+			"Ada  created",
+			"Bob  changed",
+			"content: Use OIDC -> Use OIDC for login",
+			"Cy  completed",
+			"state: todo -> done",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected %q in output:\n%s", want, out)
+			}
+		}
+
+		// Deleted items are found in the history
+		out = mustRun(t, "history", shortID(note))
+		if !strings.Contains(out, "Cy  deleted") {
+			t.Errorf("Expected the deletion in output:\n%s", out)
+		}
+
+		out = mustRun(t, "history", login, "--json")
+		var got historyJSON
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, out)
+		}
+		if got.ID != login || len(got.Events) != 3 || got.Events[2].Kind != "completed" || got.Events[1].Author != "Bob" {
+			t.Errorf("Unexpected JSON: %+v", got)
+		}
+	})
+
+	t.Run("blame", func(t *testing.T) {
+		setContent(t, login, "Use OIDC for login and SSO")
+		mustRun(t, "reopen", shortID(login))
+		out := mustRun(t, "blame")
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected 2 active items, got:\n%s", out)
+		}
+		for _, line := range lines {
+			if !strings.Contains(line, "uncommitted") {
+				t.Errorf("Expected uncommitted changes, got %q", line)
+			}
+		}
+
+		commit(t, "Dee", "Reopen login")
+		var got []blameJSON
+		if err := json.Unmarshal([]byte(mustRun(t, "blame", "--json")), &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].Author != "Dee" || got[0].Date == nil {
+			t.Errorf("Unexpected JSON: %+v", got)
+		}
+	})
+}
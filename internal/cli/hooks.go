@@ -0,0 +1,354 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/scan"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// hooksCmd groups the commands that manage git hooks.
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage git hooks",
+	Long: `Manage the git hooks that connect commits to context items.
+
+'ck hooks install' sets up three hooks in the local repository:
+
+  pre-commit   runs 'ck doctor --no-history' and stops the commit if the store
+               or the code refers to missing items
+  commit-msg   stops the commit if a Closes or Refs trailer refers to an
+               unknown item
+  post-commit  marks the items in "Closes: ck:<id>" trailers done and records
+               the commit on the items in "Refs: ck:<id>" trailers
+
+Close trailers are Closes, Fixes and Resolves; reference trailers are Refs
+and See. A trailer may list several items, e.g. "Refs: ck:5299c5, ck:0c9d88".
+The hooks need ck on the PATH and do nothing without it. No remote is
+involved: everything happens in the local repository and store.`,
+}
+
+// hooksInstallCmd installs the git hooks.
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the git hooks",
+	Long: `Install the pre-commit, commit-msg and post-commit hooks in the git repository
+that contains the store (see 'ck hooks').
+
+Existing hooks that were not installed by ck are left alone unless --force is
+given, in which case they are kept as <hook>.bak and restored by
+'ck hooks uninstall'.`,
+	Example: `  # Install the hooks
+  ck hooks install
+
+  # Also sync AI agent files after each commit that changes items
+  ck hooks install --sync`,
+	Args: cobra.NoArgs,
+	RunE: runHooksInstall,
+}
+
+// hooksUninstallCmd removes the git hooks.
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the git hooks",
+	Long:  "Remove the git hooks installed by 'ck hooks install', restoring hooks they replaced.",
+	Args:  cobra.NoArgs,
+	RunE:  runHooksUninstall,
+}
+
+// hooksRunCmd is what the installed hooks call.
+var hooksRunCmd = &cobra.Command{
+	Use:          "run <hook> [args]",
+	Short:        "Run a git hook (called by the installed hooks)",
+	Hidden:       true,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runHook,
+}
+
+// Command flags for the hooks commands.
+var (
+	hooksForceFlag bool // --force: Replace existing hooks
+	hooksSyncFlag  bool // --sync: Sync after the post-commit hook changed items
+)
+
+// hookMarker identifies the hooks installed by ck.
+const hookMarker = "Installed by ContextKeeper"
+
+// hookNames are the hooks installed, in the order git runs them.
+var hookNames = []string{"pre-commit", "commit-msg", "post-commit"}
+
+// Trailer keys, lowercased, that close or reference items.
+var (
+	closeTrailers = map[string]bool{"closes": true, "close": true, "closed": true, "fixes": true, "fix": true, "fixed": true, "resolves": true, "resolve": true, "resolved": true}
+	refTrailers   = map[string]bool{"refs": true, "ref": true, "references": true, "see": true, "see-also": true}
+)
+
+// hookScript returns the script for the named hook.
+func hookScript(name string, sync bool) string {
+	command := "ck hooks run " + name
+	switch name {
+	case "pre-commit":
+		command = "ck doctor --no-history"
+	case "commit-msg":
+		command += ` "$1"`
+	case "post-commit":
+		if sync {
+			command += " --sync"
+		}
+	}
+	return fmt.Sprintf(`#!/bin/sh
+# %s (ck hooks install). Remove with 'ck hooks uninstall'.
+command -v ck >/dev/null 2>&1 || exit 0
+exec %s
+`, hookMarker, command)
+}
+
+// repoHooksDir returns the hooks directory of the git repository containing
+// the store.
+func repoHooksDir() (string, error) {
+	root := projectRoot(config.FindStoragePath(pathFlag))
+	if !git.IsRepo(root) {
+		return "", fmt.Errorf("not a git repository: %s", root)
+	}
+	return git.HooksDir(root)
+}
+
+// isOwnHook reports whether the hook file at path was installed by ck.
+func isOwnHook(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(data), hookMarker)
+}
+
+// runHooksInstall is the execution function for the hooks install command.
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	dir, err := repoHooksDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory %q: %w", dir, err)
+	}
+
+	// Check for foreign hooks first, so that nothing is installed if any is in the way
+	for _, name := range hookNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil && !isOwnHook(path) && !hooksForceFlag {
+			return fmt.Errorf("%s already exists; use --force to replace it (it is kept as %s.bak)", path, name)
+		}
+	}
+
+	for _, name := range hookNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil && !isOwnHook(path) {
+			if err := os.Rename(path, path+".bak"); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", path, err)
+			}
+			cmd.Printf("Backed up existing %s hook to %s.bak\n", name, name)
+		}
+		if err := os.WriteFile(path, []byte(hookScript(name, hooksSyncFlag)), 0755); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(path, 0755); err != nil {
+			return fmt.Errorf("failed to make %s executable: %w", path, err)
+		}
+	}
+	cmd.Printf("Installed %s hooks in %s\n", strings.Join(hookNames, ", "), dir)
+	return nil
+}
+
+// runHooksUninstall is the execution function for the hooks uninstall command.
+func runHooksUninstall(cmd *cobra.Command, args []string) error {
+	dir, err := repoHooksDir()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, name := range hookNames {
+		path := filepath.Join(dir, name)
+		if !isOwnHook(path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed++
+		if _, err := os.Stat(path + ".bak"); err == nil {
+			if err := os.Rename(path+".bak", path); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", path, err)
+			}
+			cmd.Printf("Restored the previous %s hook\n", name)
+		}
+	}
+	if removed == 0 {
+		cmd.Println("No hooks installed by ck found.")
+		return nil
+	}
+	cmd.Printf("Removed %d hooks from %s\n", removed, dir)
+	return nil
+}
+
+// runHook is the execution function for the hooks run command.
+func runHook(cmd *cobra.Command, args []string) error {
+	switch args[0] {
+	case "commit-msg":
+		if len(args) != 2 {
+			return fmt.Errorf("commit-msg needs the commit message file")
+		}
+		return runCommitMsgHook(cmd, args[1])
+	case "post-commit":
+		return runPostCommitHook(cmd)
+	}
+	return fmt.Errorf("unknown hook %q: must be commit-msg or post-commit", args[0])
+}
+
+// trailerRef is an item referenced by a commit message trailer.
+type trailerRef struct {
+	ID    string // The ID as written
+	Close bool   // The trailer closes the item
+}
+
+// trailerRefs returns the items referenced in the close and reference
+// trailers of a commit message.
+func trailerRefs(message string) []trailerRef {
+	var refs []trailerRef
+	for _, t := range git.Trailers(message) {
+		key := strings.ToLower(t.Key)
+		if !closeTrailers[key] && !refTrailers[key] {
+			continue
+		}
+		for _, id := range scan.FindIDs(t.Value) {
+			refs = append(refs, trailerRef{ID: id, Close: closeTrailers[key]})
+		}
+	}
+	return refs
+}
+
+// runCommitMsgHook rejects commit messages whose trailers refer to unknown
+// or ambiguous items.
+func runCommitMsgHook(cmd *cobra.Command, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read commit message: %w", err)
+	}
+	refs := trailerRefs(string(data))
+	if len(refs) == 0 {
+		return nil
+	}
+
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	var problems []string
+	for _, r := range refs {
+		_, err := resolveRef(stor, r.ID)
+		switch {
+		case errors.Is(err, storage.ErrItemNotFound):
+			problems = append(problems, fmt.Sprintf("ck:%s refers to no item", r.ID))
+		case errors.Is(err, storage.ErrAmbiguousID):
+			problems = append(problems, fmt.Sprintf("ck:%s is ambiguous (use more characters)", r.ID))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("commit message: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// runPostCommitHook applies the trailers of the commit just made: items in
+// close trailers are marked done, and the commit is recorded on all items
+// it refers to. Running it again for the same commit changes nothing.
+func runPostCommitHook(cmd *cobra.Command) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	commits, err := git.Log(projectRoot(storagePath), "-1", "HEAD")
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return nil
+	}
+	commit := commits[0]
+	refs := trailerRefs(commit.Message)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	now := time.Now()
+	changed := 0
+	for _, r := range refs {
+		item, err := resolveRef(stor, r.ID)
+		if err != nil {
+			cmd.PrintErrf("Warning: ck:%s: %v\n", r.ID, err)
+			continue
+		}
+		if recordCommit(&item, commit, r.Close, now) {
+			if err := stor.Update(item); err != nil {
+				return fmt.Errorf("failed to update item %q: %w", item.ID, err)
+			}
+			changed++
+			if r.Close {
+				cmd.Printf("Closed %s: %s\n", shortID(item.ID), item.Content)
+			} else {
+				cmd.Printf("Referenced %s in %s\n", shortID(item.ID), commit.ShortHash())
+			}
+		}
+	}
+
+	if hooksSyncFlag && changed > 0 {
+		synced := syncAfterCRUD(cmd.OutOrStdout())
+		if synced > 0 {
+			cmd.Printf("Synced %d files\n", synced)
+		}
+	}
+	return nil
+}
+
+// recordCommit records commit on item and, if close is set, marks the item
+// done. It reports whether the item changed; a commit is recorded only once.
+func recordCommit(item *models.ContextItem, commit git.Commit, close bool, now time.Time) bool {
+	for _, c := range item.Commits {
+		if c.Hash == commit.Hash {
+			return false
+		}
+	}
+	item.Commits = append(append([]models.CommitRef(nil), item.Commits...), models.CommitRef{
+		Hash:    commit.Hash,
+		Subject: commit.Subject(),
+		At:      commit.Date,
+		Closed:  close && item.CompletedAt == nil,
+	})
+	if close && item.CompletedAt == nil {
+		item.SetState(models.StateDone, "closed by commit "+commit.ShortHash(), now)
+	}
+	return true
+}
+
+// init registers the hooks commands with the root command.
+func init() {
+	hooksInstallCmd.Flags().BoolVar(&hooksForceFlag, "force", false, "Replace existing hooks, keeping them as <hook>.bak")
+	hooksInstallCmd.Flags().BoolVar(&hooksSyncFlag, "sync", false, "Sync AI agent files after commits that change items")
+	hooksRunCmd.Flags().BoolVar(&hooksSyncFlag, "sync", false, "Sync AI agent files if items changed")
+
+	hooksCmd.AddCommand(hooksInstallCmd, hooksUninstallCmd, hooksRunCmd)
+	RootCmd.AddCommand(hooksCmd)
+}
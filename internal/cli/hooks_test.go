@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestHooks(t *testing.T) {
+	root, storagePath := testStore(t)
+
+	gitRun := func(t *testing.T, args ...string) {
+		t.Helper()
+		// The hooks under test are run by hand, not by git
+		args = append([]string{"-c", "user.name=Ada", "-c", "user.email=ada@example.com", "-c", "core.hooksPath=/dev/null"}, args...)
+		if _, err := git.Run(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func(t *testing.T) map[string]models.ContextItem {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		items := make(map[string]models.ContextItem)
+		for _, item := range stor.GetAll() {
+			items[item.Content] = item
+		}
+		return items
+	}
+
+	t.Run("install needs a git repository", func(t *testing.T) {
+		if _, err := run(t, "hooks", "install"); err == nil || !strings.Contains(err.Error(), "not a git repository") {
+			t.Errorf("Expected a not a git repository error, got %v", err)
+		}
+	})
+
+	gitRun(t, "init", "-q")
+	mustRun(t, "add", "Use OIDC for login")
+	mustRun(t, "add", "Rate limit the API")
+	items := load(t)
+	login, api := items["Use OIDC for login"].ID, items["Rate limit the API"].ID
+	hooksDir := filepath.Join(root, ".git", "hooks")
+
+	t.Run("install and uninstall", func(t *testing.T) {
+		mustRun(t, "hooks", "install")
+		for _, name := range hookNames {
+			info, err := os.Stat(filepath.Join(hooksDir, name))
+			if err != nil {
+				t.Fatalf("Hook %s not installed: %v", name, err)
+			}
+			if info.Mode()&0100 == 0 {
+				t.Errorf("Hook %s is not executable", name)
+			}
+			if !isOwnHook(filepath.Join(hooksDir, name)) {
+				t.Errorf("Hook %s is missing the marker", name)
+			}
+		}
+
+		// Installing again replaces our own hooks
+		mustRun(t, "hooks", "install", "--sync")
+		data, _ := os.ReadFile(filepath.Join(hooksDir, "post-commit"))
+		if !strings.Contains(string(data), "ck hooks run post-commit --sync") {
+			t.Errorf("post-commit hook does not sync:\n%s", data)
+		}
+
+		out := mustRun(t, "hooks", "uninstall")
+		if !strings.Contains(out, "Removed 3 hooks") {
+			t.Errorf("Unexpected output: %s", out)
+		}
+	})
+
+	t.Run("existing hooks are kept", func(t *testing.T) {
+		foreign := filepath.Join(hooksDir, "pre-commit")
+		if err := os.WriteFile(foreign, []byte("#!/bin/sh\nmake lint\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := run(t, "hooks", "install"); err == nil {
+			t.Fatal("Expected install to refuse replacing a foreign hook")
+		}
+		if _, err := os.Stat(filepath.Join(hooksDir, "commit-msg")); err == nil {
+			t.Error("No hook should be installed when one is in the way")
+		}
+
+		mustRun(t, "hooks", "install", "--force")
+		if data, _ := os.ReadFile(foreign + ".bak"); string(data) != "#!/bin/sh\nmake lint\n" {
+			t.Errorf("Foreign hook not backed up, got %q", data)
+		}
+		mustRun(t, "hooks", "uninstall")
+		if data, _ := os.ReadFile(foreign); string(data) != "#!/bin/sh\nmake lint\n" {
+			t.Errorf("Foreign hook not restored, got %q", data)
+		}
+	})
+
+	t.Run("commit-msg rejects unknown items", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+		write := func(msg string) {
+			if err := os.WriteFile(file, []byte(msg), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		write("Fix login\n\nCloses: ck:" + shortID(login)[:6] + "\n# Comment\n")
+		mustRun(t, "hooks", "run", "commit-msg", file)
+
+		write("Fix login\n\nCloses: ck:" + strings.Repeat("0", 8) + "\n")
+		if strings.HasPrefix(login, "0000") || strings.HasPrefix(api, "0000") {
+			t.Skip("Item ID happens to start with the unknown prefix")
+		}
+		if _, err := run(t, "hooks", "run", "commit-msg", file); err == nil || !strings.Contains(err.Error(), "refers to no item") {
+			t.Errorf("Expected an unknown item error, got %v", err)
+		}
+	})
+
+	t.Run("post-commit closes and references items", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(root, "login.go"), []byte("package login\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		gitRun(t, "add", "login.go")
+		gitRun(t, "commit", "-q", "-m", "Switch login to OIDC\n\nCloses: ck:"+shortID(login)[:6]+"\nRefs: ck:"+shortID(api))
+
+		out := mustRun(t, "hooks", "run", "post-commit")
+		if !strings.Contains(out, "Closed "+shortID(login)) || !strings.Contains(out, "Referenced "+shortID(api)) {
+			t.Errorf("Unexpected output: %s", out)
+		}
+
+		items := load(t)
+		closed := items["Use OIDC for login"]
+		if closed.CompletedAt == nil || !strings.HasPrefix(closed.StateReason(), "closed by commit ") {
+			t.Errorf("Expected the login item closed by the commit, got %+v", closed)
+		}
+		if len(closed.Commits) != 1 || !closed.Commits[0].Closed || closed.Commits[0].Subject != "Switch login to OIDC" {
+			t.Errorf("Unexpected commits on closed item: %+v", closed.Commits)
+		}
+		referenced := items["Rate limit the API"]
+		if referenced.CompletedAt != nil || len(referenced.Commits) != 1 || referenced.Commits[0].Closed {
+			t.Errorf("Expected the API item referenced but open, got %+v", referenced)
+		}
+
+		// Running the hook again for the same commit changes nothing
+		if out := mustRun(t, "hooks", "run", "post-commit"); out != "" {
+			t.Errorf("Expected no output on a second run, got: %s", out)
+		}
+
+		out = mustRun(t, "show", login)
+		if !strings.Contains(out, "Commits:") || !strings.Contains(out, "Switch login to OIDC  (closed)") {
+			t.Errorf("Expected the commit in show output: %s", out)
+		}
+	})
+}
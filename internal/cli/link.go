@@ -0,0 +1,168 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// linkCmd adds a typed link between two items.
+var linkCmd = &cobra.Command{
+	Use:   "link <id> <type> <id>",
+	Short: "Link two context items",
+	Long: `Link two context items with a typed relationship. The types are:
+
+  blocks       the first item must be done before the second
+  relates-to   the items are related
+  duplicates   the first item duplicates the second
+  supersedes   the first item replaces the second, e.g. a newer decision
+
+Items blocked by an open item are listed in 'ck agenda', and superseded
+items are left out of synced agent files. Use 'ck show' to see an item's
+links and 'ck graph' to export them.`,
+	Example: `  # The OIDC client must be registered before the login page can switch
+  ck link abc12345 blocks def67890
+
+  # A new decision replaces an old one
+  ck link abc12345 supersedes def67890`,
+	Args: cobra.ExactArgs(3),
+	RunE: linkCommand,
+}
+
+// unlinkCmd removes links between two items.
+var unlinkCmd = &cobra.Command{
+	Use:   "unlink <id> [type] <id>",
+	Short: "Remove links between two context items",
+	Long:  "Remove the link of the given type from the first item to the second, or without a type, all links between the two items in either direction.",
+	Example: `  # Remove a specific link
+  ck unlink abc12345 blocks def67890
+
+  # Remove all links between two items
+  ck unlink abc12345 def67890`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: unlinkCommand,
+}
+
+// linkSyncFlag triggers sync to AI agent files after linking or unlinking
+var linkSyncFlag bool
+
+// linkCommand is the execution function for the link command.
+func linkCommand(cmd *cobra.Command, args []string) error {
+	linkType, err := utils.ParseLinkType(args[1])
+	if err != nil {
+		return err
+	}
+
+	stor, source, target, err := loadLinkedItems(cmd, args[0], args[2])
+	if err != nil {
+		return err
+	}
+	if source.ID == target.ID {
+		return fmt.Errorf("cannot link item %s to itself", shortID(source.ID))
+	}
+	if !source.AddLink(linkType, target.ID) {
+		return fmt.Errorf("%s already %s %s", shortID(source.ID), linkType, shortID(target.ID))
+	}
+	if err := stor.Update(source); err != nil {
+		return fmt.Errorf("failed to update item %q: %w", source.ID, err)
+	}
+
+	return reportLinkChange(cmd, "linked", source, linkType, target)
+}
+
+// unlinkCommand is the execution function for the unlink command.
+func unlinkCommand(cmd *cobra.Command, args []string) error {
+	linkType := ""
+	if len(args) == 3 {
+		var err error
+		if linkType, err = utils.ParseLinkType(args[1]); err != nil {
+			return err
+		}
+	}
+
+	stor, source, target, err := loadLinkedItems(cmd, args[0], args[len(args)-1])
+	if err != nil {
+		return err
+	}
+
+	removed := source.RemoveLinks(linkType, target.ID)
+	if linkType == "" {
+		removed += target.RemoveLinks("", source.ID)
+	}
+	if removed == 0 {
+		return fmt.Errorf("no links between %s and %s", shortID(source.ID), shortID(target.ID))
+	}
+	for _, item := range []models.ContextItem{source, target} {
+		if err := stor.Update(item); err != nil {
+			return fmt.Errorf("failed to update item %q: %w", item.ID, err)
+		}
+	}
+
+	return reportLinkChange(cmd, "unlinked", source, linkType, target)
+}
+
+// loadLinkedItems loads storage and looks up the two items of a link.
+func loadLinkedItems(cmd *cobra.Command, sourceID, targetID string) (storage.Storage, models.ContextItem, models.ContextItem, error) {
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return nil, models.ContextItem{}, models.ContextItem{}, fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	source, err := lookupItem(cmd, stor, sourceID)
+	if err != nil {
+		return nil, models.ContextItem{}, models.ContextItem{}, err
+	}
+	target, err := lookupItem(cmd, stor, targetID)
+	if err != nil {
+		return nil, models.ContextItem{}, models.ContextItem{}, err
+	}
+	return stor, source, target, nil
+}
+
+// reportLinkChange prints the result of a link or unlink command and syncs
+// if requested.
+func reportLinkChange(cmd *cobra.Command, status string, source models.ContextItem, linkType string, target models.ContextItem) error {
+	if jsonOutput {
+		result := map[string]string{
+			"id":     shortID(source.ID),
+			"target": shortID(target.ID),
+			"type":   linkType,
+			"status": status,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	} else if linkType == "" {
+		cmd.Printf("Unlinked %s and %s\n", shortID(source.ID), shortID(target.ID))
+	} else if status == "linked" {
+		cmd.Printf("Linked: %s %s %s\n", shortID(source.ID), linkType, shortID(target.ID))
+	} else {
+		cmd.Printf("Unlinked: %s %s %s\n", shortID(source.ID), linkType, shortID(target.ID))
+	}
+
+	if linkSyncFlag {
+		synced := syncAfterCRUD(cmd.OutOrStdout())
+		if synced > 0 {
+			cmd.Printf("Synced %d files\n", synced)
+		}
+	}
+
+	return nil
+}
+
+// init registers the link and unlink commands with the root command.
+func init() {
+	for _, c := range []*cobra.Command{linkCmd, unlinkCmd} {
+		c.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+		c.Flags().BoolVar(&linkSyncFlag, "sync", false, "Sync to AI agent rule files afterwards")
+		RootCmd.AddCommand(c)
+	}
+}
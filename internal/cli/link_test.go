@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestLinkCommands(t *testing.T) {
+	_, storagePath := testStore(t)
+	stor := storage.NewStorage(storagePath)
+	stor.Add(models.ContextItem{ID: "client11-item", Content: "Register the OIDC client", CreatedAt: time.Now()})
+	stor.Add(models.ContextItem{ID: "login222-item", Content: "Switch the login page", CreatedAt: time.Now()})
+	stor.Add(models.ContextItem{ID: "olddec33-item", Content: "Decision: use sessions", CreatedAt: time.Now()})
+	stor.Add(models.ContextItem{ID: "newdec44-item", Content: "Decision: use \"OIDC\" tokens", CreatedAt: time.Now()})
+
+	t.Run("link stores typed links", func(t *testing.T) {
+		out := mustRun(t, "link", "client11", "blocks", "login222")
+		if !strings.Contains(out, "Linked: client11 blocks login222") {
+			t.Errorf("Unexpected output: %q", out)
+		}
+		mustRun(t, "link", "newdec44", "replaces", "olddec33")
+
+		stor.Load()
+		item, _ := stor.GetByID("client11-item")
+		if len(item.Links) != 1 || item.Links[0] != (models.Link{Type: models.LinkBlocks, Target: "login222-item"}) {
+			t.Errorf("Unexpected links: %v", item.Links)
+		}
+
+		if _, err := run(t, "link", "client11", "blocks", "login222"); err == nil {
+			t.Error("Expected duplicate link to fail")
+		}
+		if _, err := run(t, "link", "client11", "blocks", "client11"); err == nil {
+			t.Error("Expected self link to fail")
+		}
+		if _, err := run(t, "link", "client11", "parent-of", "login222"); err == nil {
+			t.Error("Expected unknown link type to fail")
+		}
+	})
+
+	t.Run("show lists links in both directions", func(t *testing.T) {
+		out := mustRun(t, "show", "login222")
+		if !strings.Contains(out, "blocked by  [client11] Register the OIDC client (todo)") {
+			t.Errorf("Expected incoming link, got:\n%s", out)
+		}
+
+		var detail map[string]interface{}
+		if err := json.Unmarshal([]byte(mustRun(t, "show", "client11", "--json")), &detail); err != nil {
+			t.Fatal(err)
+		}
+		links, _ := detail["links"].([]interface{})
+		if len(links) != 1 || links[0].(map[string]interface{})["relation"] != "blocks" {
+			t.Errorf("Unexpected links in JSON: %v", detail["links"])
+		}
+	})
+
+	t.Run("agenda lists blocked items", func(t *testing.T) {
+		out := mustRun(t, "agenda")
+		if !strings.Contains(out, "Blocked (1)") || !strings.Contains(out, "(blocked by client)") {
+			t.Errorf("Expected blocked section, got:\n%s", out)
+		}
+		var agenda map[string][]agendaBlockedJSON
+		if err := json.Unmarshal([]byte(mustRun(t, "agenda", "--json")), &agenda); err != nil {
+			t.Fatal(err)
+		}
+		if blocked := agenda["blocked"]; len(blocked) != 1 || len(blocked[0].BlockedBy) != 1 || blocked[0].BlockedBy[0] != "client11" {
+			t.Errorf("Expected the blocker in agenda JSON, got %+v", agenda["blocked"])
+		}
+
+		// Completing the blocker unblocks the item
+		mustRun(t, "done", "client11")
+		out = mustRun(t, "agenda")
+		if strings.Contains(out, "Blocked") {
+			t.Errorf("Expected no blocked items, got:\n%s", out)
+		}
+		mustRun(t, "reopen", "client11")
+	})
+
+	t.Run("sync hides superseded items", func(t *testing.T) {
+		stor.Load()
+		content, err := buildSyncContent(stor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(content, "use sessions") {
+			t.Errorf("Expected superseded decision to be hidden:\n%s", content)
+		}
+		if !strings.Contains(content, "blocked by client11") {
+			t.Errorf("Expected blocker in sync content:\n%s", content)
+		}
+	})
+
+	t.Run("graph exports dot and mermaid", func(t *testing.T) {
+		out := mustRun(t, "graph")
+		for _, want := range []string{
+			"digraph contextkeeper {",
+			`"client11" -> "login222" [label="blocks"];`,
+			`"newdec44" [label="newdec44: Decision: use \"OIDC\" tokens"];`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected %q in DOT output:\n%s", want, out)
+			}
+		}
+
+		out = mustRun(t, "graph", "--format", "mermaid")
+		if !strings.HasPrefix(out, "graph LR\n") || !strings.Contains(out, "nnewdec44 -->|supersedes| nolddec33") {
+			t.Errorf("Unexpected Mermaid output:\n%s", out)
+		}
+
+		if _, err := run(t, "graph", "--format", "svg"); err == nil {
+			t.Error("Expected unknown format to fail")
+		}
+	})
+
+	t.Run("unlink removes links", func(t *testing.T) {
+		mustRun(t, "unlink", "client11", "login222")
+		if _, err := run(t, "unlink", "client11", "blocks", "login222"); err == nil {
+			t.Error("Expected unlinking a missing link to fail")
+		}
+		stor.Load()
+		item, _ := stor.GetByID("client11-item")
+		if len(item.Links) != 0 {
+			t.Errorf("Expected no links, got %v", item.Links)
+		}
+	})
+}
@@ -7,10 +7,13 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/git"
 	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/query"
 	"github.com/ondrahracek/contextkeeper/internal/storage"
 	"github.com/ondrahracek/contextkeeper/internal/utils"
 	"github.com/spf13/cobra"
@@ -21,9 +24,26 @@ import (
 // The command supports filtering by project, tags, and completion status.
 // Output can be displayed in a formatted table or as JSON.
 var listCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [query]",
 	Short: "List context items",
-	Long:  "List context items, optionally filtered by project or tags. Use --all to include completed items.",
+	Long: `List context items, optionally filtered by project, tags or a query.
+Use --all to include completed items.
+
+The query uses the same syntax as 'ck search' and 'ck sync --query':
+
+  project:api tag:bug -tag:wontfix created:>2026-01-01 status:open "rate limit"
+
+Terms are combined with AND unless separated by OR; prefix a term with "-"
+or NOT to negate it and use parentheses for grouping. Fields are project,
+tag, status, priority (high, medium or low) and the dates created and due
+(with >, >=, <, <= and YYYY-MM-DD or relative dates such as today, -7d or
+friday). Bare words and quoted phrases match content and tags. Status is
+open, closed, any, or a workflow state: todo, in-progress, blocked, done or
+wontfix. A query that mentions status replaces the default of hiding closed
+items. Use @name to refer to a view saved with 'ck view save'.
+
+Projects are hierarchical: --project platform and project:platform also
+select the items of platform/api and platform/api/auth.`,
 	Example: `  # List all active items
   ck list
 
@@ -36,32 +56,72 @@ var listCmd = &cobra.Command{
   # Include completed items
   ck list --all
 
+  # Filter with a query
+  ck list 'tag:bug -tag:wontfix (project:api OR project:web)'
+
+  # Completed items created this year
+  ck list status:done created:>=2026-01-01
+
+  # Use a saved view
+  ck list @triage
+
+  # Show subtasks under their parents
+  ck list --tree
+
+  # Show the code locations items are attached to
+  ck list --anchors
+
+  # Most urgent first
+  ck list --sort priority
+  ck list --sort due priority:high
+
+  # Include items bound to other git branches
+  ck list --all-branches
+
   # Output as JSON
   ck list --json`,
-	Args: cobra.NoArgs,
+	Args: cobra.ArbitraryArgs,
 	RunE: listCommand,
 }
 
-
 // Command flags for the list command.
 var (
-	projectFilter string
-	tagFilter     string
-	showAll       bool
-	jsonOutput    bool
+	projectFilter   string
+	tagFilter       string
+	showAll         bool
+	jsonOutput      bool
+	listSortFlag    string
+	listTreeFlag    bool
+	listAnchorsFlag bool
+	listAllBranches bool
+)
+
+// Sort orders accepted by list --sort.
+const (
+	sortCreated  = "created"
+	sortPriority = "priority"
+	sortDue      = "due"
 )
 
 // listCommand is the execution function for the list command.
 // It retrieves and filters context items from storage.
 func listCommand(cmd *cobra.Command, args []string) error {
+	node, err := parseItemQuery(args)
+	if err != nil {
+		return err
+	}
+
 	// Initialize storage and load items
-	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
 	if err := stor.Load(); err != nil {
 		return fmt.Errorf("failed to load storage: %w", err)
 	}
 
 	// Get all items
 	items := stor.GetAll()
+	progress := models.SubtaskProgress(items)
+	blockers := models.Blockers(items)
 
 	// Filter by project if specified
 	if projectFilter != "" {
@@ -70,37 +130,38 @@ func listCommand(cmd *cobra.Command, args []string) error {
 
 	// Filter by tags if specified
 	if tagFilter != "" {
-		items = filterByTags(items, tagFilter)
+		tags, err := parseTagInput(tagFilter)
+		if err != nil {
+			return err
+		}
+		items = filterByTags(items, tags)
 	}
 
-	// Filter out completed items unless --all is set
-	if !showAll {
+	// Filter out completed items unless --all is set or the query decides
+	if !showAll && !query.HasField(node, query.FieldStatus) {
 		items = filterActive(items)
 	}
 
+	// Items of other git branches are hidden unless asked for
+	if !listAllBranches && !query.HasField(node, query.FieldBranch) {
+		items = filterBranch(items, git.CurrentBranch(projectRoot(storagePath)))
+	}
+
+	items = query.Filter(items, node)
+
+	if err := sortItems(items, listSortFlag); err != nil {
+		return err
+	}
+
 	// Output in requested format
 	if jsonOutput {
-		type jsonItem struct {
-			ID          string     `json:"id"`
-			FullID      string     `json:"fullId"`
-			Content     string     `json:"content"`
-			Project     string     `json:"project"`
-			Tags        []string   `json:"tags"`
-			CompletedAt *time.Time `json:"completedAt"`
-			CreatedAt   time.Time  `json:"createdAt"`
-		}
-
-		jsonItems := make([]jsonItem, 0, len(items))
+		jsonItems := make([]listItemJSON, 0, len(items))
 		for _, item := range items {
-			jsonItems = append(jsonItems, jsonItem{
-				ID:          item.ID[:8],
-				FullID:      item.ID,
-				Content:     item.Content,
-				Project:     item.Project,
-				Tags:        item.Tags,
-				CompletedAt: item.CompletedAt,
-				CreatedAt:   item.CreatedAt,
-			})
+			jsonItem := newListItemJSON(item)
+			if p, ok := progress[item.ID]; ok {
+				jsonItem.Subtasks = &p
+			}
+			jsonItems = append(jsonItems, jsonItem)
 		}
 
 		data, err := json.MarshalIndent(jsonItems, "", "  ")
@@ -109,17 +170,63 @@ func listCommand(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Fprintln(cmd.OutOrStdout(), string(data))
 	} else {
-		fmt.Fprint(cmd.OutOrStdout(), utils.FormatItemList(items, showAll))
+		fmt.Fprint(cmd.OutOrStdout(), utils.FormatItems(items, utils.ListOptions{
+			ShowCompleted: showAll,
+			Progress:      progress,
+			Tree:          listTreeFlag,
+			BlockedBy:     blockers,
+			Anchors:       listAnchorsFlag,
+		}))
 	}
 
 	return nil
 }
 
-// filterByProject filters items by the specified project name.
+// listItemJSON is the JSON form of an item in list output.
+type listItemJSON struct {
+	ID          string           `json:"id"`
+	FullID      string           `json:"fullId"`
+	Number      int              `json:"number,omitempty"`
+	Content     string           `json:"content"`
+	Project     string           `json:"project"`
+	Tags        []string         `json:"tags"`
+	CompletedAt *time.Time       `json:"completedAt"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	State       string           `json:"state"`
+	Priority    string           `json:"priority,omitempty"`
+	DueAt       *time.Time       `json:"dueAt,omitempty"`
+	Branch      string           `json:"branch,omitempty"`
+	ParentID    string           `json:"parentId,omitempty"`
+	Anchors     []models.Anchor  `json:"anchors,omitempty"`
+	Subtasks    *models.Progress `json:"subtasks,omitempty"`
+}
+
+// newListItemJSON converts an item to its JSON list form.
+func newListItemJSON(item models.ContextItem) listItemJSON {
+	return listItemJSON{
+		ID:          shortID(item.ID),
+		FullID:      item.ID,
+		Number:      item.Number,
+		Content:     item.Content,
+		Project:     item.Project,
+		Tags:        item.Tags,
+		CompletedAt: item.CompletedAt,
+		CreatedAt:   item.CreatedAt,
+		State:       item.EffectiveState(),
+		Priority:    item.Priority,
+		DueAt:       item.DueAt,
+		Branch:      item.Branch,
+		ParentID:    item.ParentID,
+		Anchors:     item.Anchors,
+	}
+}
+
+// filterByProject filters items by the specified project name, including
+// its subprojects.
 func filterByProject(items []models.ContextItem, project string) []models.ContextItem {
 	filtered := make([]models.ContextItem, 0)
 	for _, item := range items {
-		if item.Project == project {
+		if models.InProject(item.Project, project) {
 			filtered = append(filtered, item)
 		}
 	}
@@ -127,28 +234,68 @@ func filterByProject(items []models.ContextItem, project string) []models.Contex
 }
 
 // filterByTags filters items by the specified tags.
-func filterByTags(items []models.ContextItem, tags string) []models.ContextItem {
-	filterTags := utils.ParseTags(tags)
+func filterByTags(items []models.ContextItem, tags []string) []models.ContextItem {
 	filtered := make([]models.ContextItem, 0)
 	for _, item := range items {
-		if containsTags(item.Tags, filterTags) {
+		if containsTags(item.Tags, tags) {
 			filtered = append(filtered, item)
 		}
 	}
 	return filtered
 }
 
-// filterActive filters out completed items.
+// filterActive filters out closed (done and wontfix) items.
 func filterActive(items []models.ContextItem) []models.ContextItem {
 	active := make([]models.ContextItem, 0)
 	for _, item := range items {
-		if item.CompletedAt == nil {
-			active = append(active, item)
+		switch item.EffectiveState() {
+		case models.StateDone, models.StateWontfix:
+			continue
 		}
+		active = append(active, item)
 	}
 	return active
 }
 
+// sortItems sorts items in place by the given order. Items are stored in
+// creation order, so sortCreated leaves them as they are. Sorting by
+// priority breaks ties by due date and vice versa; items without a priority
+// or due date come last. The sort is stable.
+func sortItems(items []models.ContextItem, by string) error {
+	switch by {
+	case "", sortCreated:
+	case sortPriority:
+		sort.SliceStable(items, func(i, j int) bool {
+			if ri, rj := models.PriorityRank(items[i].Priority), models.PriorityRank(items[j].Priority); ri != rj {
+				return ri < rj
+			}
+			return dueBefore(items[i], items[j])
+		})
+	case sortDue:
+		sort.SliceStable(items, func(i, j int) bool {
+			if before, after := dueBefore(items[i], items[j]), dueBefore(items[j], items[i]); before != after {
+				return before
+			}
+			return models.PriorityRank(items[i].Priority) < models.PriorityRank(items[j].Priority)
+		})
+	default:
+		return fmt.Errorf("invalid sort order %q: must be created, priority or due", by)
+	}
+	return nil
+}
+
+// dueBefore reports whether a is due before b. Items without a due date sort
+// after all items with one.
+func dueBefore(a, b models.ContextItem) bool {
+	switch {
+	case a.DueAt == nil:
+		return false
+	case b.DueAt == nil:
+		return true
+	}
+	return a.DueAt.Before(*b.DueAt)
+}
+
 // containsTags checks if itemTags contains all filterTags.
 func containsTags(itemTags, filterTags []string) bool {
 	if len(filterTags) == 0 {
@@ -172,10 +319,14 @@ func containsTags(itemTags, filterTags []string) bool {
 // init registers the list command with the root command.
 func init() {
 	// Register command flags
-	listCmd.Flags().StringVarP(&projectFilter, "project", "P", "", "Filter by project name")
+	listCmd.Flags().StringVarP(&projectFilter, "project", "P", "", "Filter by project, including its subprojects")
 	listCmd.Flags().StringVarP(&tagFilter, "tags", "t", "", "Filter by tags (comma or space separated)")
 	listCmd.Flags().BoolVarP(&showAll, "all", "a", false, "Show all items including completed")
 	listCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	listCmd.Flags().StringVarP(&listSortFlag, "sort", "s", sortCreated, "Sort by created, priority or due")
+	listCmd.Flags().BoolVar(&listTreeFlag, "tree", false, "Show subtasks indented under their parents")
+	listCmd.Flags().BoolVar(&listAnchorsFlag, "anchors", false, "Show the code locations items are attached to")
+	listCmd.Flags().BoolVar(&listAllBranches, "all-branches", false, "Include items bound to other git branches")
 
 	// Add command to root
 	RootCmd.AddCommand(listCmd)
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestListQuery(t *testing.T) {
+	_, cleanup := createSearchTestStorage(t)
+	defer cleanup()
+
+	runList := func(t *testing.T, args ...string) ([]map[string]interface{}, error) {
+		resetFlags()
+		buf := new(bytes.Buffer)
+		RootCmd.SetOut(buf)
+		RootCmd.SetErr(new(bytes.Buffer))
+		RootCmd.SetArgs(append([]string{"list", "--json"}, args...))
+		if err := RootCmd.Execute(); err != nil {
+			return nil, err
+		}
+		var output []map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+			t.Fatalf("Failed to unmarshal: %v\nOutput: %s", err, buf.String())
+		}
+		return output, nil
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		count int
+	}{
+		{"field filter", []string{"project:carscoring-app"}, 2},
+		{"negation", []string{"project:carscoring-app -tag:auth"}, 1},
+		{"or and grouping", []string{"(tag:auth OR tag:api) -project:webapp"}, 2},
+		{"status overrides active default", []string{"status:done"}, 1},
+		{"query split across arguments", []string{"tag:ui", "status:done"}, 1},
+		{"combined with flags", []string{"--project", "carscoring-app", "whatsapp"}, 1},
+		{"date comparison", []string{"created:>=2026-02-09"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := runList(t, tt.args...)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if len(output) != tt.count {
+				t.Errorf("Expected %d items, got %d: %v", tt.count, len(output), output)
+			}
+		})
+	}
+
+	t.Run("parse errors are reported", func(t *testing.T) {
+		_, err := runList(t, "(tag:auth")
+		if err == nil || !strings.Contains(err.Error(), "invalid query") {
+			t.Errorf("Expected invalid query error, got %v", err)
+		}
+	})
+}
+
+func TestSyncQuery(t *testing.T) {
+	defer func() { syncQueryFlag = "" }()
+
+	tmpDir, storagePath := testStore(t)
+	stor := storage.NewStorage(storagePath)
+	stor.Add(models.ContextItem{ID: "bug-item-12345", Content: "A bug to fix", Tags: []string{"bug"}, CreatedAt: time.Now()})
+	stor.Add(models.ContextItem{ID: "idea-item-12345", Content: "An idea", Tags: []string{"idea"}, CreatedAt: time.Now()})
+
+	oldWd, _ := os.Getwd()
+	os.Chdir(tmpDir)
+	defer os.Chdir(oldWd)
+
+	RootCmd.SetOut(new(bytes.Buffer))
+	RootCmd.SetArgs([]string{"sync", "--query", "tag:bug"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(".contextkeeper", "instructions.md"))
+	if err != nil {
+		t.Fatalf("Failed to read sync file: %v", err)
+	}
+	if !bytes.Contains(content, []byte("A bug to fix")) || bytes.Contains(content, []byte("An idea")) {
+		t.Errorf("Expected only the bug to be synced, got: %s", content)
+	}
+}
@@ -0,0 +1,186 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// mergeDriverCmd merges versions of the store for git.
+var mergeDriverCmd = &cobra.Command{
+	Use:   "merge-driver <base> <ours> <theirs> [path]",
+	Short: "Merge versions of the store (run by git)",
+	Long: `Merge two versions of the store file, as a git merge driver. Git runs it
+when merging branches that both changed .contextkeeper/items.json, once
+'ck merge-driver install' has set it up.
+
+Items are matched by ID and merged field by field, so that one branch
+completing an item and another editing it merge cleanly. Items added on both
+branches may have been given the same number; the item created first keeps
+it and the others are renumbered, the same way in every clone. The counter
+file is merged by adding up the numbers both branches allocated, so that it
+covers the renumbered items.
+
+Fields that both branches changed differently keep our version and are
+reported as conflicts, as are items deleted on one branch and changed on the
+other, which are kept. Git then marks the file as conflicted; check the
+reported items and 'git add' the file to finish the merge.`,
+	Args:         cobra.RangeArgs(3, 4),
+	SilenceUsage: true,
+	RunE:         runMergeDriver,
+}
+
+// mergeDriverInstallCmd sets up the merge driver.
+var mergeDriverInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Set up git to merge the store with ck",
+	Long: `Set up the merge driver for the git repository that contains the store:
+define it in .git/config and assign it to the store files in .gitattributes.
+
+Commit .gitattributes to share the assignment. The definition is local, so
+every clone runs 'ck merge-driver install' once; clones without it merge the
+store as text.`,
+	Args: cobra.NoArgs,
+	RunE: runMergeDriverInstall,
+}
+
+// mergeDriverName is the name of the merge driver in git's configuration.
+const mergeDriverName = "ck"
+
+// runMergeDriver is the execution function for the merge-driver command.
+// git passes the common ancestor, our version, which receives the result,
+// and their version, and the path of the merged file, whose name tells the
+// counter file from the items file.
+func runMergeDriver(cmd *cobra.Command, args []string) error {
+	var versions [3][]byte
+	for i, path := range args[:3] {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		versions[i] = data
+	}
+	name := storage.ItemsFileName
+	if len(args) == 4 {
+		name = args[3]
+	}
+
+	// The counter file holds a number. Numbers allocated on both sides
+	// since the base clash at most once each, and the items merge gives
+	// every clashing item a number above both sides'
+	if filepath.Base(name) == storage.CounterFileName {
+		var counters [3]int
+		for i, data := range versions {
+			if n, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+				counters[i] = n
+			}
+		}
+		base, ours, theirs := counters[0], counters[1], counters[2]
+		merged := max(ours, theirs, ours+theirs-base)
+		return os.WriteFile(args[1], []byte(strconv.Itoa(merged)+"\n"), storage.DefaultFilePerms)
+	}
+
+	merged, conflicts, err := storage.Merge(versions[0], versions[1], versions[2])
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := os.WriteFile(args[1], merged, storage.DefaultFilePerms); err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	errOut := cmd.ErrOrStderr()
+	for _, c := range conflicts {
+		if c.Field == "" {
+			fmt.Fprintf(errOut, "CONFLICT (modify/delete): %s: item %s was deleted on one side and changed on the other; kept it\n", name, shortID(c.ID))
+		} else {
+			fmt.Fprintf(errOut, "CONFLICT (content): %s: both sides changed %q of item %s; kept ours\n", name, c.Field, shortID(c.ID))
+		}
+	}
+	return fmt.Errorf("%d conflicting changes in %s", len(conflicts), name)
+}
+
+// runMergeDriverInstall is the execution function for the merge-driver
+// install command.
+func runMergeDriverInstall(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	root := projectRoot(storagePath)
+	if !git.IsRepo(root) {
+		return fmt.Errorf("not a git repository: %s", root)
+	}
+	top, err := git.TopLevel(root)
+	if err != nil {
+		return err
+	}
+	storeDir, err := filepath.Abs(config.StoreDir(storagePath))
+	if err != nil {
+		return err
+	}
+	// git reports the top level with symlinks resolved
+	if resolved, err := filepath.EvalSymlinks(storeDir); err == nil {
+		storeDir = resolved
+	}
+	dir, err := filepath.Rel(top, storeDir)
+	if err != nil || strings.HasPrefix(dir, "..") {
+		return fmt.Errorf("the store %s is not in the git repository %s", storagePath, top)
+	}
+
+	for key, value := range map[string]string{
+		"name":   "ContextKeeper store",
+		"driver": "ck merge-driver %O %A %B %P",
+	} {
+		if _, err := git.Run(root, "config", "merge."+mergeDriverName+"."+key, value); err != nil {
+			return err
+		}
+	}
+
+	attributesPath := filepath.Join(top, ".gitattributes")
+	data, err := os.ReadFile(attributesPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		existing[strings.Join(strings.Fields(line), " ")] = true
+	}
+	var added []string
+	for _, file := range []string{storage.ItemsFileName, storage.CounterFileName} {
+		line := filepath.ToSlash(filepath.Join(dir, file)) + " merge=" + mergeDriverName
+		if !existing[line] {
+			added = append(added, line)
+		}
+	}
+	if len(added) > 0 {
+		content := string(data)
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += strings.Join(added, "\n") + "\n"
+		if err := os.WriteFile(attributesPath, []byte(content), storage.DefaultFilePerms); err != nil {
+			return err
+		}
+		cmd.Printf("Added %d lines to %s; commit it to share them\n", len(added), attributesPath)
+	}
+	cmd.Println("Installed the merge driver for the store")
+	return nil
+}
+
+// init registers the merge-driver command with the root command.
+func init() {
+	mergeDriverCmd.AddCommand(mergeDriverInstallCmd)
+	RootCmd.AddCommand(mergeDriverCmd)
+}
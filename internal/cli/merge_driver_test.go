@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+func TestMergeDriver(t *testing.T) {
+	dir := t.TempDir()
+	run := func(t *testing.T, args ...string) (string, string, error) {
+		t.Helper()
+		out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+		RootCmd.SetOut(out)
+		RootCmd.SetErr(errOut)
+		RootCmd.SetArgs(args)
+		err := RootCmd.Execute()
+		return out.String(), errOut.String(), err
+	}
+	write := func(t *testing.T, name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("items", func(t *testing.T) {
+		base := write(t, "base", `[{"id": "5299c5ab-1", "number": 1, "content": "Use OIDC"}]`)
+		ours := write(t, "ours", `[{"id": "5299c5ab-1", "number": 1, "content": "Use OIDC for login"}]`)
+		theirs := write(t, "theirs", `[{"id": "5299c5ab-1", "number": 1, "content": "Use SAML"}, {"id": "77a1e0f2-2", "number": 2, "content": "New"}]`)
+
+		_, errOut, err := run(t, "merge-driver", base, ours, theirs, ".contextkeeper/items.json")
+		if err == nil || !strings.Contains(err.Error(), "1 conflicting changes in .contextkeeper/items.json") {
+			t.Errorf("Expected a conflict error, got %v", err)
+		}
+		if !strings.Contains(errOut, `both sides changed "content" of item 5299c5ab; kept ours`) {
+			t.Errorf("Unexpected conflict report: %s", errOut)
+		}
+		data, _ := os.ReadFile(ours)
+		if !strings.Contains(string(data), "Use OIDC for login") || !strings.Contains(string(data), "77a1e0f2-2") {
+			t.Errorf("Unexpected merge result:\n%s", data)
+		}
+	})
+
+	t.Run("counter", func(t *testing.T) {
+		base, ours, theirs := write(t, "base", "3\n"), write(t, "ours", "5\n"), write(t, "theirs", "7\n")
+		if _, _, err := run(t, "merge-driver", base, ours, theirs, ".contextkeeper/counter"); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(ours); string(data) != "9\n" {
+			t.Errorf("Merged counter = %q, want 9", data)
+		}
+
+		// Only the counter file is merged as a number
+		ours = write(t, "ours", "5\n")
+		if _, _, err := run(t, "merge-driver", base, ours, theirs, ".contextkeeper/items.json"); err == nil {
+			t.Error("Expected an items file holding a number to fail to merge")
+		}
+	})
+
+	t.Run("counter covers renumbered items", func(t *testing.T) {
+		base := write(t, "base", `[{"id": "5299c5ab-1", "number": 1, "content": "Use OIDC"}]`)
+		ours := write(t, "ours", `[{"id": "5299c5ab-1", "number": 1, "content": "Use OIDC"}, {"id": "11aa0000-2", "number": 2, "content": "Ours", "created_at": "2026-01-01T00:00:00Z"}]`)
+		theirs := write(t, "theirs", `[{"id": "5299c5ab-1", "number": 1, "content": "Use OIDC"}, {"id": "22bb0000-2", "number": 2, "content": "Theirs", "created_at": "2026-01-02T00:00:00Z"}]`)
+		if _, _, err := run(t, "merge-driver", base, ours, theirs, ".contextkeeper/items.json"); err != nil {
+			t.Fatal(err)
+		}
+		var items []models.ContextItem
+		data, _ := os.ReadFile(ours)
+		if err := json.Unmarshal(data, &items); err != nil {
+			t.Fatal(err)
+		}
+		highest := 0
+		for _, item := range items {
+			highest = max(highest, item.Number)
+		}
+
+		base, ours, theirs = write(t, "base", "1\n"), write(t, "ours", "2\n"), write(t, "theirs", "2\n")
+		if _, _, err := run(t, "merge-driver", base, ours, theirs, ".contextkeeper/counter"); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(ours); string(data) != fmt.Sprintf("%d\n", highest) || highest != 3 {
+			t.Errorf("Merged counter = %q, want the highest merged number %d", data, highest)
+		}
+	})
+
+	t.Run("install", func(t *testing.T) {
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Chdir(wd)
+
+		// The store as found in the current directory, as a directory given
+		// in the environment and as the items file
+		for _, form := range []string{"", ".contextkeeper", ".contextkeeper/items.json"} {
+			root := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(root, ".contextkeeper"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if form == "" {
+				os.Unsetenv("CK_STORAGE_PATH")
+				if err := os.Chdir(root); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				os.Setenv("CK_STORAGE_PATH", filepath.Join(root, filepath.FromSlash(form)))
+			}
+			if _, err := git.Run(root, "init", "-q"); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, ".gitattributes"), []byte("*.png binary"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 2; i++ {
+				if _, _, err := run(t, "merge-driver", "install"); err != nil {
+					t.Fatalf("install with store %q: %v", form, err)
+				}
+			}
+			data, _ := os.ReadFile(filepath.Join(root, ".gitattributes"))
+			want := "*.png binary\n.contextkeeper/items.json merge=ck\n.contextkeeper/counter merge=ck\n"
+			if string(data) != want {
+				t.Errorf(".gitattributes with store %q = %q, want %q", form, data, want)
+			}
+			if out, _ := git.Run(root, "config", "merge.ck.driver"); strings.TrimSpace(out) != "ck merge-driver %O %A %B %P" {
+				t.Errorf("Driver = %q", out)
+			}
+			os.Chdir(wd)
+		}
+		os.Unsetenv("CK_STORAGE_PATH")
+	})
+}
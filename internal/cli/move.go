@@ -0,0 +1,82 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// moveCmd moves context items to another project.
+var moveCmd = &cobra.Command{
+	Use:   "move [id...] --to <project>",
+	Short: "Move context items to another project",
+	Long:  "Move context items to another project, or out of any project with --to \"\".\n\n" + bulkHelp + "\n\n" + itemRefHelp,
+	Example: `  # Move an item to the web project
+  ck move 12 --to web
+
+  # Move everything tagged frontend out of the api project
+  ck move --project api --tags frontend --to web`,
+	Args: cobra.ArbitraryArgs,
+	RunE: moveCommand,
+}
+
+// Command flags for the move command.
+var (
+	// moveToFlag is the project to move the items to
+	moveToFlag string
+	// moveBulk selects the items to move
+	moveBulk bulkFlags
+)
+
+// moveCommand is the execution function for the move command.
+func moveCommand(cmd *cobra.Command, args []string) error {
+	if !cmd.Flags().Changed("to") {
+		return fmt.Errorf("no project given: use --to <project>, or --to \"\" to remove the project")
+	}
+	project := strings.TrimSpace(moveToFlag)
+
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	items, err := moveBulk.selectItems(cmd, stor, args)
+	if err != nil {
+		return err
+	}
+	warnSimilarProject(cmd, stor.GetAll(), project)
+
+	var changed []models.ContextItem
+	for _, item := range items {
+		if item.Project != project {
+			item.Project = project
+			changed = append(changed, item)
+		}
+	}
+	target := "project " + strings.ReplaceAll(project, "%", "%%")
+	if project == "" {
+		target = "no project"
+	}
+	_, err = moveBulk.run(cmd, stor, changed, bulkAction{
+		name:     "move",
+		question: "Move %d items to " + target + "?",
+		result:   "Moved %d items to " + target,
+		apply:    stor.Update,
+	})
+	return err
+}
+
+// init registers the move command with the root command.
+func init() {
+	moveCmd.Flags().StringVar(&moveToFlag, "to", "", "Project to move the items to")
+	moveCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	moveBulk.register(moveCmd)
+	RootCmd.AddCommand(moveCmd)
+}
@@ -0,0 +1,94 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// pinCmd pins a context item so it is listed first in sync output.
+var pinCmd = &cobra.Command{
+	Use:   "pin <id>",
+	Short: "Pin a context item",
+	Long:  "Pin a context item so it is listed first in its section of the synced AI agent files.",
+	Example: `  # Pin an item
+  ck pin abc12345`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPinned(cmd, args[0], true)
+	},
+}
+
+// unpinCmd removes the pin from a context item.
+var unpinCmd = &cobra.Command{
+	Use:   "unpin <id>",
+	Short: "Unpin a context item",
+	Long:  "Remove the pin from a context item.",
+	Example: `  # Unpin an item
+  ck unpin abc12345`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setPinned(cmd, args[0], false)
+	},
+}
+
+// pinSyncFlag triggers sync to AI agent files after pinning or unpinning
+var pinSyncFlag bool
+
+// setPinned sets the Pinned flag of the item matching id and saves it.
+func setPinned(cmd *cobra.Command, id string, pinned bool) error {
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	item, err := lookupItem(cmd, stor, id)
+	if err != nil {
+		return err
+	}
+
+	item.Pinned = pinned
+	if err := stor.Update(item); err != nil {
+		return fmt.Errorf("failed to update item %q: %w", item.ID, err)
+	}
+
+	status := "pinned"
+	if !pinned {
+		status = "unpinned"
+	}
+	if jsonOutput {
+		result := map[string]string{
+			"id":     shortID(item.ID),
+			"status": status,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	} else {
+		cmd.Printf("Item %s: %s\n", status, shortID(item.ID))
+	}
+
+	if pinSyncFlag {
+		synced := syncAfterCRUD(cmd.OutOrStdout())
+		if synced > 0 {
+			cmd.Printf("Synced %d files\n", synced)
+		}
+	}
+
+	return nil
+}
+
+// init registers the pin and unpin commands with the root command.
+func init() {
+	for _, c := range []*cobra.Command{pinCmd, unpinCmd} {
+		c.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+		c.Flags().BoolVar(&pinSyncFlag, "sync", false, "Sync to AI agent rule files afterwards")
+		RootCmd.AddCommand(c)
+	}
+}
@@ -0,0 +1,388 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// projectsCmd lists the projects in use.
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "List projects with their progress and last activity",
+	Long: `List the projects in use as a tree, with the number of items, how many are
+open, the share of closed items and when an item was last added or changed
+state. Counts include subprojects.
+
+Project names are hierarchical, separated by slashes: platform/api/auth is a
+subproject of platform/api, itself one of platform, and --project platform
+or project:platform selects all three.
+
+Names spelled alike, such as webapp and web-app, are pointed out; fix them
+with 'ck project merge'.
+
+Projects can have defaults in .contextkeeper/config.json: tags added to
+their new items, and a directory whose agent rule files receive their items
+on ck sync (see 'ck sync --help'). They apply to subprojects too:
+
+  {
+    "projects": {
+      "platform": {"tags": ["backend"]},
+      "platform/api": {"sync_dir": "services/api"}
+    }
+  }`,
+	Example: `  ck projects
+  ck project rename webapp web-app
+  ck project merge webapp web_app web-app
+  ck project rename api platform/api`,
+	Args: cobra.NoArgs,
+	RunE: runProjects,
+}
+
+// projectCmd groups the project management commands.
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Rename and merge projects",
+	Long:  "Rename and merge projects on all items, including closed ones, along with their subprojects and settings. Use 'ck projects' to list them.",
+}
+
+// projectRenameCmd renames a project.
+var projectRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a project and its subprojects",
+	Long:  "Rename a project on all items, including closed ones. Its subprojects and settings move along, so renaming platform to core turns platform/api into core/api. To rename a project to one already in use, merge them instead.",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runProjectRename,
+}
+
+// projectMergeCmd merges projects into another one.
+var projectMergeCmd = &cobra.Command{
+	Use:   "merge <project>... <into>",
+	Short: "Merge projects into another project",
+	Long:  "Move the items of the given projects to the last one, on all items, including closed ones. Subprojects move along, so merging webapp into web-app turns webapp/ui into web-app/ui. Settings of the merged projects are kept only where the target has none.",
+	Example: `  # Fix spellings of the same project
+  ck project merge webapp web_app web-app`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runProjectMerge,
+}
+
+// projectStats summarizes the items of a project and its subprojects.
+type projectStats struct {
+	Project      string    `json:"project"`
+	Items        int       `json:"items"`
+	Open         int       `json:"open"`
+	Completion   float64   `json:"completion"`
+	LastActivity time.Time `json:"lastActivity"`
+}
+
+// countProjects returns the statistics of every project of items and the
+// projects they are subprojects of, in tree order. Items without a project
+// come last, as the project "".
+func countProjects(items []models.ContextItem) []projectStats {
+	byName := make(map[string]*projectStats)
+	add := func(name string, item models.ContextItem) {
+		s, ok := byName[name]
+		if !ok {
+			s = &projectStats{Project: name}
+			byName[name] = s
+		}
+		s.Items++
+		if item.CompletedAt == nil {
+			s.Open++
+		}
+		if last := lastActivity(item); last.After(s.LastActivity) {
+			s.LastActivity = last
+		}
+	}
+	for _, item := range items {
+		if item.Project == "" {
+			add("", item)
+			continue
+		}
+		for name := item.Project; name != ""; name = models.ParentProject(name) {
+			add(name, item)
+		}
+	}
+
+	stats := make([]projectStats, 0, len(byName))
+	for _, s := range byName {
+		s.Completion = float64(s.Items-s.Open) / float64(s.Items)
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i].Project, stats[j].Project
+		if a == "" || b == "" {
+			return b == "" && a != ""
+		}
+		// Separators sort first, so that subprojects follow their parent
+		return strings.ReplaceAll(a, models.ProjectSeparator, "\x00") < strings.ReplaceAll(b, models.ProjectSeparator, "\x00")
+	})
+	return stats
+}
+
+// lastActivity returns when item was last added or changed state.
+func lastActivity(item models.ContextItem) time.Time {
+	last := item.CreatedAt
+	if item.CompletedAt != nil && item.CompletedAt.After(last) {
+		last = *item.CompletedAt
+	}
+	for _, t := range item.Transitions {
+		if t.At.After(last) {
+			last = t.At
+		}
+	}
+	return last
+}
+
+// similarProjects returns the groups of projects of items whose names are
+// spelled alike (see models.ProjectKey), each sorted.
+func similarProjects(items []models.ContextItem) [][]string {
+	byKey := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if item.Project == "" || seen[item.Project] {
+			continue
+		}
+		seen[item.Project] = true
+		key := models.ProjectKey(item.Project)
+		byKey[key] = append(byKey[key], item.Project)
+	}
+	var groups [][]string
+	for _, names := range byKey {
+		if len(names) > 1 {
+			sort.Strings(names)
+			groups = append(groups, names)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
+
+// warnSimilarProject warns when project is not in use yet but is spelled
+// like a project that is, which is most likely a typo.
+func warnSimilarProject(cmd *cobra.Command, items []models.ContextItem, project string) {
+	if project == "" {
+		return
+	}
+	key := models.ProjectKey(project)
+	for _, item := range items {
+		if item.Project == project {
+			return
+		}
+	}
+	for _, item := range items {
+		if item.Project != "" && models.ProjectKey(item.Project) == key {
+			cmd.PrintErrf("Warning: new project %q is spelled like the existing project %q\n", project, item.Project)
+			return
+		}
+	}
+}
+
+// validateProject checks a project name given to rename or merge to.
+func validateProject(name string) error {
+	for _, part := range strings.Split(name, models.ProjectSeparator) {
+		if strings.TrimSpace(part) == "" {
+			return fmt.Errorf("invalid project %q: parts separated by %q cannot be empty", name, models.ProjectSeparator)
+		}
+	}
+	return nil
+}
+
+// runProjects is the execution function for the projects command.
+func runProjects(cmd *cobra.Command, args []string) error {
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	items := stor.GetAll()
+	stats := countProjects(items)
+	similar := similarProjects(items)
+
+	if jsonOutput {
+		result := struct {
+			Projects []projectStats `json:"projects"`
+			Similar  [][]string     `json:"similar"`
+		}{stats, similar}
+		if result.Similar == nil {
+			result.Similar = [][]string{}
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal projects to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	if len(stats) == 0 {
+		cmd.Println("No items yet.")
+		return nil
+	}
+	labels := make([]string, len(stats))
+	width := 0
+	for i, s := range stats {
+		labels[i] = "(no project)"
+		if s.Project != "" {
+			depth := strings.Count(s.Project, models.ProjectSeparator)
+			labels[i] = strings.Repeat("  ", depth) + s.Project[strings.LastIndex(s.Project, models.ProjectSeparator)+1:]
+		}
+		width = max(width, len(labels[i]))
+	}
+	now := time.Now()
+	for i, s := range stats {
+		active := utils.FormatAge(s.LastActivity, now)
+		if active != "today" {
+			active += " ago"
+		}
+		cmd.Printf("%-*s  %d items, %d open, %d%% done, active %s\n",
+			width, labels[i], s.Items, s.Open, int(s.Completion*100), active)
+	}
+
+	for _, names := range similar {
+		cmd.Printf("\nSpelled alike: %s; merge them with 'ck project merge %s <into>'\n",
+			strings.Join(names, ", "), strings.Join(names, " "))
+	}
+	return nil
+}
+
+// runProjectRename is the execution function for the project rename command.
+func runProjectRename(cmd *cobra.Command, args []string) error {
+	old, project := args[0], args[1]
+	if old == project {
+		return fmt.Errorf("the old and new project are the same")
+	}
+	return moveProjects(cmd, []string{old}, project, func(inUse bool) error {
+		if inUse {
+			return fmt.Errorf("project %q is already in use: merge the projects with 'ck project merge %s %s'", project, old, project)
+		}
+		return nil
+	})
+}
+
+// runProjectMerge is the execution function for the project merge command.
+func runProjectMerge(cmd *cobra.Command, args []string) error {
+	into := args[len(args)-1]
+	var projects []string
+	for _, project := range args[:len(args)-1] {
+		if project != into {
+			projects = append(projects, project)
+		}
+	}
+	if len(projects) == 0 {
+		return fmt.Errorf("no projects to merge into %q", into)
+	}
+	return moveProjects(cmd, projects, into, nil)
+}
+
+// moveProjects moves the items of projects and their subprojects to
+// project, along with the projects' settings. check, if set, is called
+// with whether project is in use before anything changes.
+func moveProjects(cmd *cobra.Command, projects []string, project string, check func(inUse bool) error) error {
+	if err := validateProject(project); err != nil {
+		return err
+	}
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+
+	// rename returns the new name of a project, if it moves
+	rename := func(name string) (string, bool) {
+		for _, old := range projects {
+			if renamed, ok := models.RenameProject(name, old, project); ok {
+				return renamed, true
+			}
+		}
+		return name, false
+	}
+
+	items := stor.GetAll()
+	inUse := false
+	used := make(map[string]bool)
+	for _, item := range items {
+		if _, ok := rename(item.Project); !ok && models.InProject(item.Project, project) {
+			inUse = true
+		}
+		for _, old := range projects {
+			used[old] = used[old] || models.InProject(item.Project, old)
+		}
+	}
+	if check != nil {
+		if err := check(inUse); err != nil {
+			return err
+		}
+	}
+	for _, old := range projects {
+		if !used[old] {
+			return fmt.Errorf("no items are in project %q", old)
+		}
+	}
+
+	changed := 0
+	err = stor.Batch(func() error {
+		for _, item := range items {
+			renamed, ok := rename(item.Project)
+			if !ok || renamed == item.Project {
+				continue
+			}
+			item.Project = renamed
+			if err := stor.Update(item); err != nil {
+				return fmt.Errorf("failed to update item %s: %w", shortID(item.ID), err)
+			}
+			changed++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Settings follow the projects, unless the target already has its own
+	if len(cfg.Projects) > 0 {
+		moved := make(map[string]config.ProjectConfig, len(cfg.Projects))
+		for name, settings := range cfg.Projects {
+			if _, ok := rename(name); !ok {
+				moved[name] = settings
+			}
+		}
+		for name, settings := range cfg.Projects {
+			if renamed, ok := rename(name); ok {
+				if _, exists := moved[renamed]; !exists {
+					moved[renamed] = settings
+				}
+			}
+		}
+		cfg.Projects = moved
+		if err := config.SaveStoreConfig(storagePath, cfg); err != nil {
+			return err
+		}
+	}
+
+	cmd.Printf("Moved project %s to %s on %d items\n", strings.Join(projects, ", "), project, changed)
+	return nil
+}
+
+// init registers the project management commands.
+func init() {
+	projectsCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+
+	projectCmd.AddCommand(projectRenameCmd, projectMergeCmd)
+	RootCmd.AddCommand(projectsCmd, projectCmd)
+}
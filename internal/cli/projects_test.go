@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestProjectCommands(t *testing.T) {
+	dir, storagePath := testStore(t)
+
+	created := time.Now().Add(-72 * time.Hour)
+	closed := created.Add(time.Hour)
+	stor := storage.NewStorage(storagePath)
+	for _, item := range []models.ContextItem{
+		{ID: "aaaa1111-item", Content: "Rate limit the API", Project: "platform/api", CreatedAt: created},
+		{ID: "bbbb2222-item", Content: "Rotate the tokens", Project: "platform/api/auth", CreatedAt: created, CompletedAt: &closed},
+		{ID: "cccc3333-item", Content: "Upgrade the cluster", Project: "platform", CreatedAt: created},
+		{ID: "dddd4444-item", Content: "Restyle the header", Project: "webapp", CreatedAt: created},
+		{ID: "eeee5555-item", Content: "Fix the footer", Project: "web-app", CreatedAt: created},
+		{ID: "ffff6666-item", Content: "Unfiled", CreatedAt: created},
+	} {
+		if err := stor.Add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.SaveStoreConfig(storagePath, &config.StoreConfig{Projects: map[string]config.ProjectConfig{
+		"platform":     {Tags: []string{"backend"}},
+		"platform/api": {Tags: []string{"api"}, SyncDir: "services/api"},
+		"webapp":       {Tags: []string{"frontend"}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	projectOf := func(t *testing.T, id string) string {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		item, err := stor.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return item.Project
+	}
+	listIDs := func(t *testing.T, args ...string) []string {
+		t.Helper()
+		var items []listItemJSON
+		if err := json.Unmarshal([]byte(mustRun(t, append([]string{"list", "--all", "--json"}, args...)...)), &items); err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(items))
+		for i, item := range items {
+			ids[i] = item.ID[:4]
+		}
+		return ids
+	}
+
+	t.Run("projects lists a tree", func(t *testing.T) {
+		out := mustRun(t, "projects")
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		want := []string{
+			"platform      3 items, 2 open, 33% done, active 2d ago",
+			"  api         2 items, 1 open, 50% done, active 2d ago",
+			"    auth      1 items, 0 open, 100% done, active 2d ago",
+			"web-app       1 items, 1 open, 0% done, active 3d ago",
+			"webapp        1 items, 1 open, 0% done, active 3d ago",
+			"(no project)  1 items, 1 open, 0% done, active 3d ago",
+		}
+		if !reflect.DeepEqual(lines[:len(want)], want) {
+			t.Errorf("Unexpected projects:\n%s", out)
+		}
+		if !strings.Contains(out, "Spelled alike: web-app, webapp") {
+			t.Errorf("Expected the similar names to be pointed out:\n%s", out)
+		}
+	})
+
+	t.Run("project filters include subprojects", func(t *testing.T) {
+		if got := listIDs(t, "--project", "platform"); !reflect.DeepEqual(got, []string{"aaaa", "bbbb", "cccc"}) {
+			t.Errorf("--project platform listed %v", got)
+		}
+		if got := listIDs(t, "project:platform/api"); !reflect.DeepEqual(got, []string{"aaaa", "bbbb"}) {
+			t.Errorf("project:platform/api listed %v", got)
+		}
+	})
+
+	t.Run("new items get project tags", func(t *testing.T) {
+		mustRun(t, "add", "Add pagination", "--project", "platform/api/v2", "--tags", "feature")
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		items := stor.GetAll()
+		if got := items[len(items)-1].Tags; !reflect.DeepEqual(got, []string{"feature", "backend", "api"}) {
+			t.Errorf("Expected [feature backend api], got %v", got)
+		}
+	})
+
+	t.Run("new project spelled like another warns", func(t *testing.T) {
+		_, errOut, err := execute(t, "", "move", "ffff6666", "--to", "Web_App")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(errOut, `new project "Web_App" is spelled like the existing project`) {
+			t.Errorf("Expected a warning, got %q", errOut)
+		}
+	})
+
+	t.Run("rename to a project in use suggests merge", func(t *testing.T) {
+		_, _, err := execute(t, "", "project", "rename", "webapp", "web-app")
+		if err == nil || !strings.Contains(err.Error(), "ck project merge webapp web-app") {
+			t.Errorf("Expected a merge suggestion, got %v", err)
+		}
+	})
+
+	t.Run("merge moves items and settings", func(t *testing.T) {
+		out := mustRun(t, "project", "merge", "webapp", "Web_App", "web-app")
+		if !strings.Contains(out, "Moved project webapp, Web_App to web-app on 2 items") {
+			t.Errorf("Unexpected output:\n%s", out)
+		}
+		if got := projectOf(t, "dddd4444-item"); got != "web-app" {
+			t.Errorf("Expected web-app, got %q", got)
+		}
+		cfg, err := config.LoadStoreConfig(storagePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := cfg.Projects["webapp"]; ok || len(cfg.Projects["web-app"].Tags) != 1 {
+			t.Errorf("Expected the settings to move to web-app, got %v", cfg.Projects)
+		}
+	})
+
+	t.Run("rename moves subprojects", func(t *testing.T) {
+		mustRun(t, "project", "rename", "platform", "core")
+		if got := projectOf(t, "bbbb2222-item"); got != "core/api/auth" {
+			t.Errorf("Expected core/api/auth, got %q", got)
+		}
+		cfg, err := config.LoadStoreConfig(storagePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Projects["core/api"].SyncDir != "services/api" {
+			t.Errorf("Expected the settings of platform/api to move, got %v", cfg.Projects)
+		}
+		if _, _, err := execute(t, "", "project", "rename", "platform", "core2"); err == nil {
+			t.Error("Expected an error for an unused project")
+		}
+		if _, _, err := execute(t, "", "project", "rename", "core", "core//x"); err == nil {
+			t.Error("Expected an error for an invalid name")
+		}
+	})
+
+	t.Run("sync writes project directories", func(t *testing.T) {
+		wd, _ := os.Getwd()
+		defer os.Chdir(wd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatal(err)
+		}
+		os.MkdirAll(filepath.Join(dir, "services", "api", ".claude", "rules"), 0755)
+
+		out := mustRun(t, "sync")
+		if !strings.Contains(out, "Synced core/api to") {
+			t.Errorf("Expected the project directory to be synced:\n%s", out)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "services", "api", ".claude", "rules", "ck-context.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "Rate limit the API") || strings.Contains(string(data), "Upgrade the cluster") {
+			t.Errorf("Expected only the items of core/api:\n%s", data)
+		}
+	})
+
+	t.Run("sync pull reads project directories", func(t *testing.T) {
+		wd, _ := os.Getwd()
+		defer os.Chdir(wd)
+		if err := os.Chdir(dir); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { syncPullFlag, syncYesFlag = false, false }()
+
+		path := filepath.Join(dir, "services", "api", ".claude", "rules", "ck-context.md")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		edited := strings.Replace(string(data), "Rate limit the API", "Rate limit the public API", 1)
+		edited = strings.Replace(edited, "\n\n<!-- ck:items", "\n- Cache the responses\n\n<!-- ck:items", 1)
+		if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		out := mustRun(t, "sync", "--pull", "--yes")
+		if !strings.Contains(out, "Applied 2 changes") {
+			t.Fatalf("Expected the project file to be pulled:\n%s", out)
+		}
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		item, err := stor.GetByID("aaaa1111-item")
+		if err != nil || item.Content != "Rate limit the public API" {
+			t.Errorf("Expected the edit to be applied, got %q (%v)", item.Content, err)
+		}
+		items := stor.GetAll()
+		if added := items[len(items)-1]; added.Content != "Cache the responses" || added.Project != "core/api" {
+			t.Errorf("Expected a new item in core/api, got %+v", added)
+		}
+	})
+
+	t.Run("sync_dir must stay inside the repository", func(t *testing.T) {
+		for _, syncDir := range []string{".", "../elsewhere", "/tmp/elsewhere"} {
+			cfg := &config.StoreConfig{Projects: map[string]config.ProjectConfig{"core": {SyncDir: syncDir}}}
+			if err := config.SaveStoreConfig(storagePath, cfg); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := execute(t, "", "sync"); err == nil || !strings.Contains(err.Error(), "sync_dir") {
+				t.Errorf("Expected sync_dir %q to be rejected, got %v", syncDir, err)
+			}
+		}
+	})
+}
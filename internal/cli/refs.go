@@ -0,0 +1,144 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/scan"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// refsCmd lists the references to an item in code and commit messages.
+var refsCmd = &cobra.Command{
+	Use:   "refs <id>",
+	Short: "Find references to an item in code and commits",
+	Long: `Find the places that reference an item as "ck:<id>", such as a
+"// see ck:5299c5" comment in the code or "refs ck:5299c5" in a commit
+message.
+
+The working tree is searched, skipping files ignored by .gitignore, as well as
+the messages of all commits in the local git history. References may use any
+prefix of at least four characters of the ID; they are resolved like IDs
+given on the command line. Use 'ck doctor' to find references to items that
+do not exist.`,
+	Example: `  # Where is this decision referenced?
+  ck refs 5299c5
+
+  # Output as JSON
+  ck refs 5299c5 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: refsCommand,
+}
+
+// refJSON is the JSON form of a reference.
+type refJSON struct {
+	ID     string `json:"id"`
+	Path   string `json:"path,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Commit string `json:"commit,omitempty"`
+	Author string `json:"author,omitempty"`
+	Date   string `json:"date,omitempty"`
+	Text   string `json:"text"`
+}
+
+// newRefJSON converts a reference to its JSON form.
+func newRefJSON(r scan.Ref) refJSON {
+	j := refJSON{ID: r.ID, Path: r.Path, Line: r.Line, Text: r.Text}
+	if r.Commit != nil {
+		j.Commit = r.Commit.Hash
+		j.Author = r.Commit.Author
+		j.Date = r.Commit.Date.Format("2006-01-02")
+	}
+	return j
+}
+
+// refsCommand is the execution function for the refs command.
+func refsCommand(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	item, err := lookupItem(cmd, stor, args[0])
+	if err != nil {
+		return err
+	}
+
+	refs, err := collectRefs(projectRoot(storagePath))
+	if err != nil {
+		return err
+	}
+	refs = refsTo(stor, item.ID, refs)
+
+	if jsonOutput {
+		out := make([]refJSON, 0, len(refs))
+		for _, r := range refs {
+			out = append(out, newRefJSON(r))
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal references to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	if len(refs) == 0 {
+		cmd.Printf("No references to %s. Mention it as ck:%s in code or commit messages.\n", shortID(item.ID), shortID(item.ID))
+		return nil
+	}
+	for _, r := range refs {
+		cmd.Printf("%s  %s\n", r.Location(), r.Text)
+	}
+	cmd.Printf("%d references to %s\n", len(refs), shortID(item.ID))
+	return nil
+}
+
+// collectRefs returns the references in the working tree under root,
+// followed by those in the git history.
+func collectRefs(root string) ([]scan.Ref, error) {
+	refs, err := scan.TreeRefs(root)
+	if err != nil {
+		return nil, err
+	}
+	history, err := scan.HistoryRefs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git history: %w", err)
+	}
+	return append(refs, history...), nil
+}
+
+// refsTo returns the references in refs that resolve to the item with the
+// given ID.
+func refsTo(stor storage.Storage, id string, refs []scan.Ref) []scan.Ref {
+	var out []scan.Ref
+	for _, r := range refs {
+		if item, err := resolveRef(stor, r.ID); err == nil && item.ID == id {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// resolveRef returns the item a reference points to, resolving the ID like
+// lookupItem but returning the storage errors, ErrItemNotFound and
+// ErrAmbiguousID, unwrapped.
+func resolveRef(stor storage.Storage, id string) (models.ContextItem, error) {
+	if item, err := stor.GetByID(id); err == nil {
+		return item, nil
+	}
+	return stor.GetByPrefix(id)
+}
+
+// init registers the refs command with the root command.
+func init() {
+	refsCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	RootCmd.AddCommand(refsCmd)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestRefsAndDoctor(t *testing.T) {
+	root, storagePath := testStore(t)
+
+	mustRun(t, "add", "Use OIDC for login")
+	mustRun(t, "add", "Rate limit the API")
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		t.Fatal(err)
+	}
+	items := stor.GetAll()
+	login, api := items[0].ID, items[1].ID
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("auth/login.go", "package auth\n\n// See ck:"+shortID(login)[:6]+" for the decision\nfunc Login() {}\n")
+	writeFile("docs/notes.md", "Mentions ck:"+shortID(api)+".\n")
+
+	gitRun := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=Ada", "-c", "user.email=ada@example.com"}, args...)
+		if _, err := git.Run(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitRun("init", "-q")
+	gitRun("add", "-A")
+	gitRun("commit", "-q", "-m", "Switch login to OIDC\n\nrefs ck:"+shortID(login))
+
+	t.Run("refs lists code and commit references", func(t *testing.T) {
+		out := mustRun(t, "refs", shortID(login)[:6])
+		if !strings.Contains(out, filepath.FromSlash("auth/login.go:3")) {
+			t.Errorf("Expected the code reference, got: %s", out)
+		}
+		if !strings.Contains(out, "Switch login to OIDC") {
+			t.Errorf("Expected the commit reference, got: %s", out)
+		}
+		if strings.Contains(out, "notes.md") {
+			t.Errorf("Reference to another item listed: %s", out)
+		}
+		if !strings.Contains(out, "2 references to "+shortID(login)) {
+			t.Errorf("Unexpected summary: %s", out)
+		}
+	})
+
+	t.Run("refs --json", func(t *testing.T) {
+		out := mustRun(t, "refs", api, "--json")
+		var refs []refJSON
+		if err := json.Unmarshal([]byte(out), &refs); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, out)
+		}
+		if len(refs) != 1 || refs[0].Path != "docs/notes.md" || refs[0].Line != 1 {
+			t.Errorf("Unexpected references: %+v", refs)
+		}
+	})
+
+	t.Run("show lists references", func(t *testing.T) {
+		out := mustRun(t, "show", login)
+		if !strings.Contains(out, "Referenced in:") || !strings.Contains(out, "See ck:"+shortID(login)[:6]) {
+			t.Errorf("Expected references in show output: %s", out)
+		}
+	})
+
+	t.Run("doctor passes", func(t *testing.T) {
+		out := mustRun(t, "doctor")
+		if !strings.Contains(out, "No problems found (3 references checked)") {
+			t.Errorf("Unexpected output: %s", out)
+		}
+	})
+
+	t.Run("doctor reports dangling references", func(t *testing.T) {
+		mustRun(t, "remove", login, "--force")
+		// A link to the removed item, as merged in from another clone
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		item, err := stor.GetByID(api)
+		if err != nil {
+			t.Fatal(err)
+		}
+		item.AddLink(models.LinkBlocks, login)
+		if err := stor.Update(item); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := run(t, "doctor")
+		if err == nil {
+			t.Fatal("Expected doctor to fail")
+		}
+		if !strings.Contains(out, filepath.FromSlash("auth/login.go:3")+"  ck:"+shortID(login)[:6]+" refers to no item") {
+			t.Errorf("Expected the dangling code reference, got: %s", out)
+		}
+		if !strings.Contains(out, "commit ") {
+			t.Errorf("Expected the dangling commit reference, got: %s", out)
+		}
+		if !strings.Contains(out, "blocks deleted item "+shortID(login)) {
+			t.Errorf("Expected the dangling link, got: %s", out)
+		}
+	})
+}
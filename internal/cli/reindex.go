@@ -0,0 +1,63 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/search"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// reindexCmd rebuilds the persisted search index from scratch.
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the search index",
+	Long: `Rebuild the search index used by 'ck search'.
+
+The index is stored as search-index.gob next to items.json and is kept up to
+date on every change made through ck. When items.json changes behind ck's
+back, for example after a git pull, the index is detected as stale and
+rebuilt automatically by the next search, so running this command is rarely
+necessary. It is useful after upgrading ck or to recover from a corrupt index.
+
+The index file is a cache: it is listed in .contextkeeper/.gitignore and can
+be deleted at any time.`,
+	Example: `  # Rebuild the index
+  ck reindex`,
+	Args: cobra.NoArgs,
+	RunE: runReindex,
+}
+
+// runReindex builds a new index from all items and persists it.
+func runReindex(cmd *cobra.Command, args []string) error {
+	path := itemsFilePath(config.FindStoragePath(pathFlag))
+	stor := storage.NewStorage(path)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	idx := search.Build(stor.GetAll())
+	if err := search.SaveIndex(path, idx); err != nil {
+		return err
+	}
+
+	cmd.Printf("Indexed %d items (%d terms) in %s\n", idx.Len(), len(idx.Postings), search.IndexPath(path))
+	return nil
+}
+
+// itemsFilePath returns the path of the items file for a storage path, which
+// may name either the items file or the directory containing it.
+func itemsFilePath(storagePath string) string {
+	return filepath.Join(config.StoreDir(storagePath), storage.ItemsFileName)
+}
+
+// init registers the reindex command with the root command.
+func init() {
+	RootCmd.AddCommand(reindexCmd)
+}
@@ -6,27 +6,31 @@ package cli
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
 	"github.com/ondrahracek/contextkeeper/internal/storage"
 	"github.com/spf13/cobra"
 )
 
-// removeCmd removes a context item from storage.
+// removeCmd removes context items from storage.
 //
-// The command requires an item ID and can optionally skip confirmation
-// with the --force flag.
+// The command takes item IDs, or filters that select the items, and can
+// optionally skip confirmation with the --force flag.
 var removeCmd = &cobra.Command{
-	Use:   "remove <id>",
-	Short: "Remove a context item",
-	Long:  "Remove a context item by its ID. Use --force to skip the confirmation prompt.",
+	Use:   "remove [id...]",
+	Short: "Remove context items",
+	Long:  "Remove context items by their ID. Use --force to skip the confirmation prompt.\n\nThe subtasks of a removed item become subtasks of its parent, and links to\nit are dropped.\n\n" + bulkHelp + " --force skips it as well.\n\n" + itemRefHelp,
 	Example: `  # Remove with confirmation
   ck remove abc12345
 
   # Remove without confirmation
-  ck remove abc12345 --force`,
-	Args: cobra.ExactArgs(1),
+  ck remove abc12345 --force
+
+  # Remove the items completed before this year, listing them first
+  ck remove --query 'status:done created:<2026-01-01' --dry-run
+  ck remove --query 'status:done created:<2026-01-01'`,
+	Args: cobra.ArbitraryArgs,
 	RunE: removeCommand,
 }
 
@@ -36,57 +40,66 @@ var forceDelete bool
 // removeSyncFlag triggers sync to AI agent files after removing
 var removeSyncFlag bool
 
+// removeBulk selects several items to remove
+var removeBulk bulkFlags
+
 // removeCommand is the execution function for the remove command.
-// It finds and removes a context item from storage.
+// It finds and removes context items from storage.
 func removeCommand(cmd *cobra.Command, args []string) error {
-	id := args[0]
-
 	// Initialize storage and load items
 	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
 	if err := stor.Load(); err != nil {
 		return fmt.Errorf("failed to load storage: %w", err)
 	}
 
-	// Get all items and find the full ID
-	allItems := stor.GetAll()
-	var itemID string
-
-	for _, item := range allItems {
-		// Match by prefix
-		if strings.HasPrefix(item.ID, id) {
-			itemID = item.ID
-			break
+	if !removeBulk.single(args) {
+		items, err := removeBulk.selectItems(cmd, stor, args)
+		if err != nil {
+			return err
+		}
+		bulk := removeBulk
+		bulk.yes = bulk.yes || forceDelete
+		removed, err := bulk.run(cmd, stor, items, bulkAction{
+			name:     "remove",
+			question: "Remove %d items?",
+			result:   "Removed %d items",
+			apply: func(item models.ContextItem) error {
+				return deleteItem(stor, item)
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if removed > 0 && removeSyncFlag {
+			if synced := syncAfterCRUD(cmd.OutOrStdout()); synced > 0 && !jsonOutput {
+				cmd.Printf("Synced %d files\n", synced)
+			}
 		}
+		return nil
 	}
 
-	if itemID == "" {
-		return fmt.Errorf("item not found: %s", id)
+	item, err := lookupItem(cmd, stor, args[0])
+	if err != nil {
+		return err
 	}
+	itemID := item.ID
 
 	// Confirm removal unless --force is set
 	if !forceDelete {
-		cmd.Printf("Remove item: %s\n", itemID[:8])
-		fmt.Print("Are you sure? (y/N): ")
-		var response string
-		fmt.Scanln(&response)
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
-			fmt.Println("Cancelled.")
+		cmd.Printf("Remove item: %s  %s\n", shortID(itemID), previewContent(item.Content, 60))
+		if !confirm(cmd, "Are you sure?") {
+			cmd.Println("Cancelled.")
 			return nil
 		}
 	}
 
 	// Delete the item from storage
-	if err := stor.Delete(itemID); err != nil {
+	if err := deleteItem(stor, item); err != nil {
 		return fmt.Errorf("failed to delete item %q: %w", itemID, err)
 	}
 
 	// Display result
-	displayID := id
-	if len(displayID) > 8 {
-		displayID = displayID[:8]
-	}
-	cmd.Printf("Removed item: %s\n", displayID)
+	cmd.Printf("Removed item: %s\n", shortID(itemID))
 
 	// Sync to files if --sync flag is set
 	if removeSyncFlag {
@@ -99,12 +112,36 @@ func removeCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// deleteItem deletes item and, in the same write, moves its subtasks to its
+// parent and drops the links to it, so no item refers to a deleted one.
+func deleteItem(stor storage.Storage, item models.ContextItem) error {
+	return stor.Batch(func() error {
+		if err := stor.Delete(item.ID); err != nil {
+			return err
+		}
+		for _, other := range stor.GetAll() {
+			removed := other.RemoveLinks("", item.ID)
+			if other.ParentID != item.ID && removed == 0 {
+				continue
+			}
+			if other.ParentID == item.ID {
+				other.ParentID = item.ParentID
+			}
+			if err := stor.Update(other); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // init registers the remove command with the root command.
 func init() {
 	// Register command flags
 	removeCmd.Flags().BoolVarP(&forceDelete, "force", "f", false, "Skip confirmation and permanently delete")
 	removeCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
 	removeCmd.Flags().BoolVar(&removeSyncFlag, "sync", false, "Sync to AI agent rule files after removing")
+	removeBulk.register(removeCmd)
 
 	// Add command to root
 	RootCmd.AddCommand(removeCmd)
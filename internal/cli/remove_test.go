@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ondrahracek/contextkeeper/internal/models"
@@ -309,3 +310,57 @@ func TestRemoveCommandSyncFlag(t *testing.T) {
 		}
 	})
 }
+
+func TestRemoveReferences(t *testing.T) {
+	_, storagePath := testStore(t)
+	stor := storage.NewStorage(storagePath)
+	for _, item := range []models.ContextItem{
+		{ID: "epic1111-item", Content: "Ship the login"},
+		{ID: "task2222-item", Content: "Build the form", ParentID: "epic1111-item"},
+		{ID: "step3333-item", Content: "Validate the email", ParentID: "task2222-item"},
+		{ID: "note4444-item", Content: "Form design", Links: []models.Link{{Type: models.LinkRelatesTo, Target: "task2222-item"}}},
+		{ID: "idea5555-item", Content: "Passkeys", Links: []models.Link{{Type: models.LinkBlocks, Target: "epic1111-item"}}},
+	} {
+		if err := stor.Add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	byID := func(t *testing.T) map[string]models.ContextItem {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		items := make(map[string]models.ContextItem)
+		for _, item := range stor.GetAll() {
+			items[item.ID] = item
+		}
+		return items
+	}
+
+	t.Run("remove moves subtasks up and drops links", func(t *testing.T) {
+		mustRun(t, "remove", "task2222", "--force")
+		items := byID(t)
+		if parent := items["step3333-item"].ParentID; parent != "epic1111-item" {
+			t.Errorf("ParentID = %q, want epic1111-item", parent)
+		}
+		if links := items["note4444-item"].Links; len(links) != 0 {
+			t.Errorf("Expected the link to the removed item to be dropped, got %v", links)
+		}
+	})
+
+	t.Run("bulk remove", func(t *testing.T) {
+		mustRun(t, "remove", "epic1111", "step3333", "--yes")
+		items := byID(t)
+		if len(items) != 2 {
+			t.Fatalf("Expected 2 items left, got %d", len(items))
+		}
+		if links := items["idea5555-item"].Links; len(links) != 0 {
+			t.Errorf("Expected the link to the removed item to be dropped, got %v", links)
+		}
+		out := mustRun(t, "doctor", "--no-history")
+		if !strings.Contains(out, "No problems found") {
+			t.Errorf("Expected no problems after removing, got:\n%s", out)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// Item references are accepted wherever a command takes an item ID:
+//
+//	5299c5ab-...  a full ID
+//	5299c5        a prefix of an ID or of its short form (models.ShortID)
+//	12, #12       the item numbered 12 (see models.ContextItem.Number); a
+//	              bare number that is no item's number is taken as an ID prefix
+//	@last, @3     the most recently created item, or the 3rd most recent
+//	~"auth bug"   the items whose content contains the text, ignoring case
+//
+// A reference that matches more than one item is an error, listing the
+// matches; when standard input is a terminal, the user is asked to choose
+// one instead. References written in code and commit messages (ck:<id>)
+// only use full IDs and prefixes, see resolveRef.
+
+// itemRefHelp describes the item references for command help texts.
+const itemRefHelp = `Items can be given by full ID, ID prefix, number (12 or #12, as shown by
+ck list), recency (@last, or @2 for the second most recent) or content
+(~"auth bug").`
+
+// stdinIsTerminal reports whether standard input is a terminal, so that
+// ambiguous references can be resolved by asking the user. Tests replace it.
+var stdinIsTerminal = func() bool {
+	stat, err := os.Stdin.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// ambiguousError reports a reference that matches more than one item.
+type ambiguousError struct {
+	ref     string
+	matches []models.ContextItem
+}
+
+func (e *ambiguousError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ambiguous ID: %s matches %d items:", e.ref, len(e.matches))
+	for _, item := range e.matches {
+		fmt.Fprintf(&b, "\n  %s  %s", shortID(item.ID), previewContent(item.Content, 60))
+	}
+	b.WriteString("\nUse more characters or the full ID")
+	return b.String()
+}
+
+// Unwrap makes errors.Is(err, storage.ErrAmbiguousID) hold.
+func (e *ambiguousError) Unwrap() error {
+	return storage.ErrAmbiguousID
+}
+
+// lookupItem resolves an item reference (see above) given on the command
+// line. Unknown references return an error wrapping storage.ErrItemNotFound,
+// ambiguous ones an error wrapping storage.ErrAmbiguousID.
+func lookupItem(cmd *cobra.Command, stor storage.Storage, ref string) (models.ContextItem, error) {
+	matches, err := matchItems(stor.GetAll(), ref)
+	if err != nil {
+		return models.ContextItem{}, err
+	}
+	switch len(matches) {
+	case 0:
+		return models.ContextItem{}, fmt.Errorf("%w: %s", storage.ErrItemNotFound, ref)
+	case 1:
+		return matches[0], nil
+	}
+	if cmd != nil && stdinIsTerminal() {
+		return chooseItem(cmd, ref, matches)
+	}
+	return models.ContextItem{}, &ambiguousError{ref: ref, matches: matches}
+}
+
+// matchItems returns the items matching the reference ref, in store order.
+func matchItems(items []models.ContextItem, ref string) ([]models.ContextItem, error) {
+	switch {
+	case ref == "":
+		return nil, fmt.Errorf("empty item ID")
+
+	case strings.HasPrefix(ref, "~"):
+		text := strings.ToLower(strings.Trim(strings.TrimSpace(ref[1:]), `"'`))
+		if text == "" {
+			return nil, fmt.Errorf("invalid item reference %q: no text after ~", ref)
+		}
+		var matches []models.ContextItem
+		for _, item := range items {
+			if strings.Contains(strings.ToLower(item.Content), text) {
+				matches = append(matches, item)
+			}
+		}
+		return matches, nil
+
+	case strings.HasPrefix(ref, "@"):
+		n := 1
+		if ref != "@last" {
+			var err error
+			if n, err = strconv.Atoi(ref[1:]); err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid item reference %q: use @last or @N", ref)
+			}
+		}
+		recent := make([]models.ContextItem, len(items))
+		copy(recent, items)
+		sort.SliceStable(recent, func(i, j int) bool {
+			return recent[i].CreatedAt.After(recent[j].CreatedAt)
+		})
+		if n > len(recent) {
+			return nil, nil
+		}
+		return recent[n-1 : n], nil
+
+	case strings.HasPrefix(ref, "#"):
+		n, err := strconv.Atoi(ref[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid item reference %q: use #N", ref)
+		}
+		return itemNumber(items, n), nil
+	}
+
+	if n, err := strconv.Atoi(ref); err == nil {
+		if matches := itemNumber(items, n); len(matches) > 0 {
+			return matches, nil
+		}
+	}
+
+	var matches []models.ContextItem
+	for _, item := range items {
+		if item.ID == ref {
+			return []models.ContextItem{item}, nil
+		}
+		if models.MatchesID(item.ID, ref) {
+			matches = append(matches, item)
+		}
+	}
+	return matches, nil
+}
+
+// itemNumber returns the item with the number n, if any.
+func itemNumber(items []models.ContextItem, n int) []models.ContextItem {
+	for _, item := range items {
+		if n > 0 && item.Number == n {
+			return []models.ContextItem{item}
+		}
+	}
+	return nil
+}
+
+// chooseItem asks the user which of the items matching ref they meant.
+func chooseItem(cmd *cobra.Command, ref string, matches []models.ContextItem) (models.ContextItem, error) {
+	errOut := cmd.ErrOrStderr()
+	fmt.Fprintf(errOut, "%s matches %d items:\n", ref, len(matches))
+	for i, item := range matches {
+		fmt.Fprintf(errOut, "  %d) %s  %s\n", i+1, shortID(item.ID), previewContent(item.Content, 60))
+	}
+	fmt.Fprintf(errOut, "Choose an item [1-%d]: ", len(matches))
+
+	response, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(response))
+	if err != nil || n < 1 || n > len(matches) {
+		return models.ContextItem{}, &ambiguousError{ref: ref, matches: matches}
+	}
+	return matches[n-1], nil
+}
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestMatchItems(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	items := []models.ContextItem{
+		{ID: "ab12cd34-0001", Number: 1, Content: "Fix the auth bug in login", CreatedAt: created},
+		{ID: "ab12ef56-0002", Number: 2, Content: "Rate limit the API", CreatedAt: created.Add(2 * time.Hour)},
+		{ID: "77a1e0f2-0003", Number: 5, Content: "Auth bug: tokens expire early", CreatedAt: created.Add(time.Hour)},
+		{ID: "30000000-0004", Number: 4, Content: "Document the API", CreatedAt: created.Add(3 * time.Hour)},
+	}
+
+	tests := []struct {
+		ref  string
+		want []string // IDs of the matches
+		err  bool
+	}{
+		{ref: "ab12cd34-0001", want: []string{"ab12cd34-0001"}},
+		{ref: "77a1", want: []string{"77a1e0f2-0003"}},
+		{ref: "ab12", want: []string{"ab12cd34-0001", "ab12ef56-0002"}},
+		{ref: "ffff"},
+		{ref: "2", want: []string{"ab12ef56-0002"}},
+		{ref: "#4", want: []string{"30000000-0004"}},
+		{ref: "5", want: []string{"77a1e0f2-0003"}},
+		{ref: "#3"},
+		{ref: "30", want: []string{"30000000-0004"}}, // No item 30, so an ID prefix
+		{ref: "@last", want: []string{"30000000-0004"}},
+		{ref: "@2", want: []string{"ab12ef56-0002"}},
+		{ref: "@3", want: []string{"77a1e0f2-0003"}},
+		{ref: "@9"},
+		{ref: `~"AUTH BUG"`, want: []string{"ab12cd34-0001", "77a1e0f2-0003"}},
+		{ref: "~rate limit", want: []string{"ab12ef56-0002"}},
+		{ref: "@first", err: true},
+		{ref: "#x", err: true},
+		{ref: "~", err: true},
+		{ref: "", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			matches, err := matchItems(items, tt.ref)
+			if (err != nil) != tt.err {
+				t.Fatalf("matchItems(%q) error = %v, want error %v", tt.ref, err, tt.err)
+			}
+			var got []string
+			for _, item := range matches {
+				got = append(got, item.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("matchItems(%q) = %v, want %v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupItem(t *testing.T) {
+	defer func(isTerminal func() bool) { stdinIsTerminal = isTerminal }(stdinIsTerminal)
+	defer RootCmd.SetIn(nil)
+
+	_, storagePath := testStore(t)
+	stor := storage.NewStorage(storagePath)
+	for _, item := range []models.ContextItem{
+		{ID: "ab12cd34-0001", Content: "Fix the auth bug in login"},
+		{ID: "ab12ef56-0002", Content: "Rate limit the API"},
+	} {
+		if err := stor.Add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The prompts go to standard error
+	run := func(t *testing.T, input string, args ...string) (string, error) {
+		t.Helper()
+		out, errOut, err := execute(t, input, args...)
+		return errOut + out, err
+	}
+	exists := func(t *testing.T, id string) bool {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		_, err := stor.GetByID(id)
+		return err == nil
+	}
+
+	t.Run("ambiguous prefixes fail for every command", func(t *testing.T) {
+		stdinIsTerminal = func() bool { return false }
+		for _, args := range [][]string{
+			{"remove", "ab", "--force"},
+			{"edit", "ab"},
+			{"done", "ab"},
+			{"pin", "ab"},
+			{"show", "ab"},
+		} {
+			_, err := run(t, "", args...)
+			if !errors.Is(err, storage.ErrAmbiguousID) {
+				t.Errorf("%v: expected an ambiguous ID error, got %v", args, err)
+				continue
+			}
+			if !strings.Contains(err.Error(), "ab12cd34  Fix the auth bug in login") {
+				t.Errorf("%v: expected the matches in the error, got %v", args, err)
+			}
+		}
+		if !exists(t, "ab12cd34-0001") || !exists(t, "ab12ef56-0002") {
+			t.Error("An item was removed despite the ambiguous ID")
+		}
+	})
+
+	t.Run("unknown and short IDs", func(t *testing.T) {
+		stdinIsTerminal = func() bool { return false }
+		for _, args := range [][]string{{"edit", "f"}, {"remove", "f", "--force"}} {
+			if _, err := run(t, "", args...); !errors.Is(err, storage.ErrItemNotFound) || err.Error() != "item not found: f" {
+				t.Errorf("%v: expected an item not found error, got %v", args, err)
+			}
+		}
+	})
+
+	t.Run("a terminal is asked to choose", func(t *testing.T) {
+		stdinIsTerminal = func() bool { return true }
+		out, err := run(t, "2\n", "remove", "~the", "--force")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "2) ab12ef56  Rate limit the API") || !strings.Contains(out, "Removed item: ab12ef56") {
+			t.Errorf("Unexpected output: %s", out)
+		}
+		if exists(t, "ab12ef56-0002") || !exists(t, "ab12cd34-0001") {
+			t.Error("Expected only the chosen item removed")
+		}
+
+		if _, err := run(t, "\n", "done", "ab"); err != nil {
+			t.Errorf("A single remaining match needs no choice, got %v", err)
+		}
+		mustAdd := storage.NewStorage(storagePath)
+		if err := mustAdd.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if err := mustAdd.Add(models.ContextItem{ID: "ab12ff00-0003", Content: "Another"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := run(t, "\n", "pin", "ab12"); !errors.Is(err, storage.ErrAmbiguousID) {
+			t.Errorf("Expected an ambiguous ID error without a choice, got %v", err)
+		}
+	})
+}
@@ -18,6 +18,8 @@
 package cli
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
 )
 
@@ -63,8 +65,9 @@ Use "ck [command] --help" to get more information about a specific command.`,
 // This function is called from main.go to start the CLI.
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
-		// Error handling is managed by Cobra
-		// which will print the error and exit with appropriate code
+		// Cobra has already printed the error; the exit status lets scripts
+		// and git hooks detect the failure
+		os.Exit(1)
 	}
 }
 
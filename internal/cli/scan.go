@@ -0,0 +1,187 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/scan"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// scanCmd imports marker comments from the source tree as context items.
+var scanCmd = &cobra.Command{
+	Use:   "scan [paths]",
+	Short: "Import TODO and FIXME comments from the code",
+	Long: `Find TODO, FIXME, HACK and XXX comments in the source tree and keep a context
+item for each of them, anchored to the comment and tagged with its marker
+(todo, fixme, ...).
+
+Without paths the whole project (the directory containing .contextkeeper) is
+scanned. Files ignored by .gitignore, binary files and files in languages
+without known comment syntax are skipped.
+
+Scanning again updates the items: comments that moved or were reworded keep
+their item, new comments get a new item, and items whose comment was removed
+from the scanned paths are marked done. Other markers can be configured in
+.contextkeeper/config.json:
+
+  {"scan": {"markers": ["TODO", "FIXME", "NOTE"]}}`,
+	Example: `  # Import the comments of the whole project
+  ck scan
+
+  # Only part of the tree, into a project
+  ck scan internal/auth --project auth
+
+  # Look for other markers
+  ck scan --markers TODO,FIXME,OPTIMIZE
+
+  # Show what would change
+  ck scan --dry-run`,
+	Args: cobra.ArbitraryArgs,
+	RunE: scanCommand,
+}
+
+// Command flags for the scan command.
+var (
+	scanMarkersFlag string // --markers: Comma-separated markers to look for
+	scanProjectFlag string // --project: Project for new items
+	scanDryRunFlag  bool   // --dry-run: Report without updating the store
+	scanJSONFlag    bool   // --json: Output as JSON
+	scanSyncFlag    bool   // --sync: Sync to AI agent files afterwards
+)
+
+// scanChangeJSON is the JSON form of an item created or changed by a scan.
+type scanChangeJSON struct {
+	ID       string `json:"id"`
+	Change   string `json:"change"`
+	Location string `json:"location,omitempty"`
+	Content  string `json:"content"`
+}
+
+// scanResultJSON is the JSON form of the scan command's output.
+type scanResultJSON struct {
+	Files   int              `json:"files"`
+	Found   int              `json:"found"`
+	Changes []scanChangeJSON `json:"changes"`
+	DryRun  bool             `json:"dryRun,omitempty"`
+}
+
+// scanCommand is the execution function for the scan command.
+func scanCommand(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+
+	markers := cfg.Scan.Markers
+	if scanMarkersFlag != "" {
+		markers = utils.ParseTags(scanMarkersFlag)
+	}
+	result, err := scan.Scan(projectRoot(storagePath), args, markers)
+	if err != nil {
+		return err
+	}
+
+	items := stor.GetAll()
+	plan, err := scan.Reconcile(items, result, time.Now())
+	if err != nil {
+		return err
+	}
+
+	project := scanProjectFlag
+	if project == "" {
+		project = os.Getenv("CK_DEFAULT_PROJECT")
+	}
+	for i := range plan.New {
+		plan.New[i].Project = project
+	}
+
+	if !scanDryRunFlag && len(plan.New)+len(plan.Updated)+len(plan.Done) > 0 {
+		changed := make(map[string]models.ContextItem)
+		for _, item := range append(plan.Updated, plan.Done...) {
+			changed[item.ID] = item
+		}
+		for i, item := range items {
+			if c, ok := changed[item.ID]; ok {
+				items[i] = c
+			}
+		}
+		stor.SetItems(append(items, plan.New...))
+		if err := stor.Save(); err != nil {
+			return fmt.Errorf("failed to save items: %w", err)
+		}
+	}
+
+	var changes []scanChangeJSON
+	report := func(change string, list []models.ContextItem) {
+		for _, item := range list {
+			c := scanChangeJSON{ID: shortID(item.ID), Change: change, Content: item.Content}
+			if len(item.Anchors) > 0 {
+				c.Location = item.Anchors[0].String()
+			}
+			changes = append(changes, c)
+		}
+	}
+	report("new", plan.New)
+	report("updated", plan.Updated)
+	report("done", plan.Done)
+
+	if scanJSONFlag {
+		if changes == nil {
+			changes = []scanChangeJSON{}
+		}
+		data, err := json.MarshalIndent(scanResultJSON{
+			Files:   result.Files,
+			Found:   len(result.Findings),
+			Changes: changes,
+			DryRun:  scanDryRunFlag,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal scan result to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	} else {
+		for _, c := range changes {
+			cmd.Printf("%-8s %s  %s  %s\n", c.Change, c.ID, c.Location, previewContent(c.Content, 60))
+		}
+		cmd.Printf("Scanned %d files, found %d comments: %d new, %d updated, %d done\n",
+			result.Files, len(result.Findings), len(plan.New), len(plan.Updated), len(plan.Done))
+		if scanDryRunFlag && len(changes) > 0 {
+			cmd.Println("Dry run: no items were changed.")
+		}
+	}
+
+	if scanSyncFlag && !scanDryRunFlag {
+		synced := syncAfterCRUD(cmd.OutOrStdout())
+		if synced > 0 {
+			cmd.Printf("Synced %d files\n", synced)
+		}
+	}
+	return nil
+}
+
+// init registers the scan command with the root command.
+func init() {
+	scanCmd.Flags().StringVar(&scanMarkersFlag, "markers", "", "Comma-separated markers to look for (default "+strings.Join(scan.DefaultMarkers, ",")+")")
+	scanCmd.Flags().StringVarP(&scanProjectFlag, "project", "p", "", "Project for new items")
+	scanCmd.Flags().BoolVar(&scanDryRunFlag, "dry-run", false, "Report without updating the store")
+	scanCmd.Flags().BoolVar(&scanJSONFlag, "json", false, "Output as JSON")
+	scanCmd.Flags().BoolVar(&scanSyncFlag, "sync", false, "Sync to AI agent files after scanning")
+	RootCmd.AddCommand(scanCmd)
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestScanCommand(t *testing.T) {
+	root, storagePath := testStore(t)
+
+	source := filepath.Join(root, "server", "main.go")
+	if err := os.MkdirAll(filepath.Dir(source), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeSource := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(source, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSource("package main\n\n// TODO: read the port from the environment\nfunc main() {}\n\n// NOTE: keep in sync with the client\n")
+
+	load := func(t *testing.T) []models.ContextItem {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		return stor.GetAll()
+	}
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		out := mustRun(t, "scan", "--dry-run")
+		if !strings.Contains(out, "1 new") || !strings.Contains(out, "Dry run") {
+			t.Errorf("Unexpected output: %s", out)
+		}
+		if items := load(t); len(items) != 0 {
+			t.Errorf("Expected no items after a dry run, got %d", len(items))
+		}
+	})
+
+	t.Run("imports comments with anchors and tags", func(t *testing.T) {
+		out := mustRun(t, "scan", "--project", "api")
+		if !strings.Contains(out, "Scanned 1 files, found 1 comments: 1 new, 0 updated, 0 done") {
+			t.Errorf("Unexpected output: %s", out)
+		}
+		items := load(t)
+		if len(items) != 1 {
+			t.Fatalf("Expected 1 item, got %d", len(items))
+		}
+		item := items[0]
+		if item.Content != "read the port from the environment" || item.Project != "api" {
+			t.Errorf("Unexpected item: %+v", item)
+		}
+		if len(item.Tags) != 1 || item.Tags[0] != "todo" {
+			t.Errorf("Tags = %v, want [todo]", item.Tags)
+		}
+		if len(item.Anchors) != 1 || item.Anchors[0].Path != "server/main.go" || item.Anchors[0].Line != 3 {
+			t.Errorf("Anchors = %+v, want server/main.go:3", item.Anchors)
+		}
+		if item.Source == nil || item.Source.Kind != models.SourceScan {
+			t.Errorf("Source = %+v, want a scan source", item.Source)
+		}
+	})
+
+	t.Run("rescanning does not duplicate", func(t *testing.T) {
+		mustRun(t, "scan")
+		if items := load(t); len(items) != 1 {
+			t.Errorf("Expected 1 item after rescanning, got %d", len(items))
+		}
+	})
+
+	t.Run("custom markers", func(t *testing.T) {
+		out := mustRun(t, "scan", "--markers", "TODO,NOTE", "--json")
+		var result scanResultJSON
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, out)
+		}
+		if result.Found != 2 || len(result.Changes) != 1 || result.Changes[0].Change != "new" {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+		if items := load(t); len(items) != 2 || items[1].Tags[0] != "note" {
+			t.Errorf("Expected a note item, got %+v", items)
+		}
+	})
+
+	t.Run("removed comments are marked done", func(t *testing.T) {
+		writeSource("package main\n\nfunc main() {}\n")
+		out := mustRun(t, "scan")
+		if !strings.Contains(out, "0 new, 0 updated, 1 done") {
+			t.Errorf("Unexpected output: %s", out)
+		}
+		for _, item := range load(t) {
+			// The note is only closed by a scan that looks for notes
+			if done := item.CompletedAt != nil; done != (item.Tags[0] == "todo") {
+				t.Errorf("Item %q done = %v", item.Content, done)
+			}
+		}
+	})
+}
@@ -7,11 +7,13 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
+	"math"
 	"time"
 
 	"github.com/ondrahracek/contextkeeper/internal/config"
 	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/query"
+	"github.com/ondrahracek/contextkeeper/internal/search"
 	"github.com/ondrahracek/contextkeeper/internal/storage"
 	"github.com/ondrahracek/contextkeeper/internal/utils"
 	"github.com/spf13/cobra"
@@ -25,7 +27,7 @@ import (
 //
 // # Usage
 //
-//	ck search <query>              Search by content
+//	ck search <query>              Search by content or query expression
 //	ck search --tag <tag>          Filter by tag
 //	ck search --all                Include completed items
 //	ck search --json               Output as JSON
@@ -53,9 +55,17 @@ var searchCmd = &cobra.Command{
 	Short: "Search context items",
 	Long: `Search context items by content or tags.
 
-The search is case-insensitive and matches against both the item content
-and its tags. Use --all to include completed items in the results.
-If no query is provided, returns all active (non-completed) items.`,
+The search is case-insensitive and matches against the item content, tags
+and project. Results are ranked by relevance (BM25): rarer words and matches
+in tags count for more. Words match inflected forms ("token" finds "tokens"),
+prefixes ("auth" finds "authentication") and small typos ("autentication").
+Quoted phrases must appear verbatim. Use --all to include completed items
+in the results. If no query is provided, returns all active (non-completed)
+items.
+
+The query may also use the filter syntax shared with 'ck list', for example
+project:api tag:bug -tag:wontfix status:open "rate limit", and saved views
+such as @triage. Run 'ck list --help' for the full syntax.`,
 	Example: `  # Search for items containing "auth"
   ck search auth
 
@@ -68,6 +78,12 @@ If no query is provided, returns all active (non-completed) items.`,
   # Include completed items
   ck search --all dashboard
 
+  # Combine text with field filters
+  ck search 'auth (tag:bug OR tag:security) -project:legacy'
+
+  # Search within a saved view
+  ck search @triage auth
+
   # List all active items (no query)
   ck search`,
 	Args: cobra.ArbitraryArgs,
@@ -77,81 +93,101 @@ If no query is provided, returns all active (non-completed) items.`,
 // searchFlags holds the command-line flags for the search command.
 // These are package-level variables to be set by Cobra during flag parsing.
 var (
-	searchTagFilter  string // -t, --tag: Filter by specific tags
+	searchTagFilter string // -t, --tag: Filter by specific tags
 	searchShowAll   bool   // -a, --all: Include completed items
 	searchJsonOut   bool   // --json: Output as JSON
 )
 
 // searchResult represents the JSON structure returned by search --json.
 type searchResult struct {
-	ID          string     `json:"id"`           // 8-character ID prefix
-	FullID      string     `json:"fullId"`       // Full UUID
-	Content     string     `json:"content"`      // Item content
-	Project     string     `json:"project"`      // Project name
-	Tags        []string   `json:"tags"`         // Associated tags
-	CompletedAt *time.Time `json:"completedAt"`  // Completion timestamp or nil
-	CreatedAt   time.Time  `json:"createdAt"`   // Creation timestamp
+	ID          string     `json:"id"`                 // 8-character ID prefix
+	FullID      string     `json:"fullId"`             // Full UUID
+	Content     string     `json:"content"`            // Item content
+	Project     string     `json:"project"`            // Project name
+	Tags        []string   `json:"tags"`               // Associated tags
+	CompletedAt *time.Time `json:"completedAt"`        // Completion timestamp or nil
+	CreatedAt   time.Time  `json:"createdAt"`          // Creation timestamp
+	Score       float64    `json:"score"`              // Relevance score, 0 without a text query
+	State       string     `json:"state"`              // Workflow state
+	Priority    string     `json:"priority,omitempty"` // Priority, if set
+	DueAt       *time.Time `json:"dueAt,omitempty"`    // Due date, if set
 }
 
+// snippetWidth is the length of the content excerpt shown under each result.
+const snippetWidth = 80
+
 // runSearch is the main execution function for the search command.
 // It loads items from storage, applies filters, and outputs results.
+// Items are scored against the persisted search index, which is rebuilt
+// first if it is missing or stale. The index also picks the candidates for
+// the text terms, so that only those are filtered and ranked.
 func runSearch(cmd *cobra.Command, args []string) error {
-	query := ""
-	if len(args) > 0 {
-		query = args[0]
-	}
-
-	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	path := itemsFilePath(config.FindStoragePath(pathFlag))
+	stor := storage.NewStorage(path)
 	if err := stor.Load(); err != nil {
 		return fmt.Errorf("failed to load storage: %w", err)
 	}
 
-	items := stor.GetAll()
-	items = applySearchFilters(items, query, searchTagFilter, searchShowAll)
+	node, err := parseItemQuery(args)
+	if err != nil {
+		return err
+	}
+
+	tags, err := parseTagInput(searchTagFilter)
+	if err != nil {
+		return err
+	}
+
+	all := stor.GetAll()
+	searcher := search.NewSearcher(search.OpenIndex(path, all), query.TextTerms(node))
+	candidates := searcher.Candidates(all, node)
+	results := searcher.Rank(applySearchFilters(candidates, node, searcher, tags, searchShowAll))
 
 	if searchJsonOut {
-		return outputSearchJSON(cmd, items)
+		return outputSearchJSON(cmd, results)
 	}
-	return outputSearchText(cmd, items, searchShowAll)
+	return outputSearchText(cmd, searcher, results, len(query.TextTerms(node)) > 0)
 }
 
 // applySearchFilters applies all search filters to the items slice.
 // The order of operations is: completed filter -> tag filter -> query filter.
-func applySearchFilters(items []models.ContextItem, query, tagFilter string, showAll bool) []models.ContextItem {
+// The completed filter is skipped when the query itself filters by status.
+// Text terms of the query are matched by the searcher, which also accepts
+// prefix and fuzzy matches.
+func applySearchFilters(items []models.ContextItem, node query.Node, searcher *search.Searcher, tagFilter []string, showAll bool) []models.ContextItem {
 	// Filter completed items first (most restrictive)
-	if !showAll {
+	if !showAll && !query.HasField(node, query.FieldStatus) {
 		items = filterActive(items)
 	}
 
 	// Filter by tag if specified
-	if tagFilter != "" {
+	if len(tagFilter) > 0 {
 		items = filterByTags(items, tagFilter)
 	}
 
-	// Filter by query if specified
-	if query != "" {
-		items = filterByQuery(items, query)
-	}
-
-	return items
+	return query.FilterWith(items, node, searcher.Match)
 }
 
 // outputSearchJSON outputs search results as formatted JSON.
-func outputSearchJSON(cmd *cobra.Command, items []models.ContextItem) error {
-	results := make([]searchResult, 0, len(items))
-	for _, item := range items {
-		results = append(results, searchResult{
-			ID:          item.ID[:8],
-			FullID:      item.ID,
-			Content:     item.Content,
-			Project:     item.Project,
-			Tags:        item.Tags,
-			CompletedAt: item.CompletedAt,
-			CreatedAt:   item.CreatedAt,
+func outputSearchJSON(cmd *cobra.Command, results []search.Result) error {
+	out := make([]searchResult, 0, len(results))
+	for _, r := range results {
+		out = append(out, searchResult{
+			ID:          shortID(r.Item.ID),
+			FullID:      r.Item.ID,
+			Content:     r.Item.Content,
+			Project:     r.Item.Project,
+			Tags:        r.Item.Tags,
+			CompletedAt: r.Item.CompletedAt,
+			CreatedAt:   r.Item.CreatedAt,
+			Score:       math.Round(r.Score*1000) / 1000,
+			State:       r.Item.EffectiveState(),
+			Priority:    r.Item.Priority,
+			DueAt:       r.Item.DueAt,
 		})
 	}
 
-	data, err := json.MarshalIndent(results, "", "  ")
+	data, err := json.MarshalIndent(out, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal results: %w", err)
 	}
@@ -159,45 +195,24 @@ func outputSearchJSON(cmd *cobra.Command, items []models.ContextItem) error {
 	return nil
 }
 
-// outputSearchText outputs search results in human-readable format.
-func outputSearchText(cmd *cobra.Command, items []models.ContextItem, showAll bool) error {
-	fmt.Fprint(cmd.OutOrStdout(), utils.FormatItemList(items, showAll))
-	return nil
-}
-
-// filterByQuery filters items by matching the query string
-// against the content and tags (case-insensitive).
-// Returns a new slice containing only matching items.
-func filterByQuery(items []models.ContextItem, query string) []models.ContextItem {
-	if query == "" {
-		return items
-	}
-
-	lowerQuery := strings.ToLower(query)
-	filtered := make([]models.ContextItem, 0, len(items))
-
-	for _, item := range items {
-		if matchesQuery(item, lowerQuery) {
-			filtered = append(filtered, item)
-		}
+// outputSearchText outputs search results in human-readable format, most
+// relevant first. With a text query each result is followed by a snippet of
+// its content with the matching words highlighted.
+func outputSearchText(cmd *cobra.Command, searcher *search.Searcher, results []search.Result, withSnippets bool) error {
+	out := cmd.OutOrStdout()
+	if len(results) == 0 {
+		fmt.Fprint(out, utils.FormatItemList(nil, true))
+		return nil
 	}
-	return filtered
-}
 
-// matchesQuery checks if an item matches the query string
-// by searching in both content and tags.
-func matchesQuery(item models.ContextItem, query string) bool {
-	// Check content first (most common case)
-	if strings.Contains(strings.ToLower(item.Content), query) {
-		return true
-	}
-	// Check tags
-	for _, tag := range item.Tags {
-		if strings.Contains(strings.ToLower(tag), query) {
-			return true
+	for _, r := range results {
+		fmt.Fprint(out, utils.FormatItemList([]models.ContextItem{r.Item}, true))
+		if withSnippets {
+			snippet := searcher.Snippet(r.Item.Content, snippetWidth, utils.HighlightOpen, utils.HighlightClose)
+			fmt.Fprintf(out, "    %s\n", snippet)
 		}
 	}
-	return false
+	return nil
 }
 
 // init registers the search command with the root command.
@@ -212,4 +227,4 @@ func init() {
 
 	// Add command to root
 	RootCmd.AddCommand(searchCmd)
-}
\ No newline at end of file
+}
@@ -85,12 +85,6 @@ func TestSearchCommand(t *testing.T) {
 	_, cleanup := createSearchTestStorage(t)
 	defer cleanup()
 
-	resetFlags := func() {
-		searchTagFilter = ""
-		searchShowAll = false
-		searchJsonOut = false
-	}
-
 	runSearchTest := func(name string, args []string, expectedCount int, contentCheck func(map[string]interface{}) bool) {
 		t.Run(name, func(t *testing.T) {
 			resetFlags()
@@ -293,4 +287,40 @@ func TestSearchCommand(t *testing.T) {
 			return strings.Contains(content, "user")
 		},
 	)
+
+	runSearchTest(
+		"search tolerates typos",
+		[]string{"search", "midleware", "--json"},
+		1,
+		func(item map[string]interface{}) bool {
+			return strings.Contains(item["content"].(string), "middleware")
+		},
+	)
+
+	t.Run("search ranks results and reports scores", func(t *testing.T) {
+		resetFlags()
+		buf := new(bytes.Buffer)
+		RootCmd.SetOut(buf)
+		RootCmd.SetArgs([]string{"search", "tokens OR auth", "--json"})
+
+		if err := RootCmd.Execute(); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+
+		var output []map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+			t.Fatalf("Failed to unmarshal: %v", err)
+		}
+		if len(output) != 2 {
+			t.Fatalf("Expected 2 items, got %d", len(output))
+		}
+		first, ok1 := output[0]["score"].(float64)
+		second, ok2 := output[1]["score"].(float64)
+		if !ok1 || !ok2 {
+			t.Fatalf("Expected numeric scores, got %v and %v", output[0]["score"], output[1]["score"])
+		}
+		if first < second || second <= 0 {
+			t.Errorf("Expected positive scores in descending order, got %v then %v", first, second)
+		}
+	})
 }
@@ -0,0 +1,375 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// showCmd shows the details of context items.
+var showCmd = &cobra.Command{
+	Use:   "show <id>...",
+	Short: "Show the details of context items",
+	Long: `Show the full content and metadata of context items: project, tags, dates,
+the history of their state, subtasks, code anchors, links to and from other
+items, and the places that reference them as ck:<id> in code and commit
+messages.
+
+--render renders the content's Markdown for the terminal. --json prints an
+object, or an array of objects for several items. --format prints each item
+with a Go template instead, using the field names of the JSON output in Go
+form, such as {{.Number}}, {{.FullID}}, {{.Content}}, {{.State}} and
+{{.Tags}}, and the functions join (join .Tags ", ") and date (date
+.CreatedAt).
+
+` + itemRefHelp,
+	Example: `  # Show an item
+  ck show abc12345
+
+  # Show several items, rendering their Markdown
+  ck show 12 14 @last --render
+
+  # Output as JSON
+  ck show abc12345 --json
+
+  # Print the content and tags of an item
+  ck show 12 --format '{{.Content}} [{{join .Tags ", "}}]'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: showCommand,
+}
+
+// Command flags for the show command.
+var (
+	// showRenderFlag renders the content's Markdown
+	showRenderFlag bool
+	// showFormatFlag is a Go template to print each item with
+	showFormatFlag string
+)
+
+// relatedItem is an item related to the shown item, by a link or as a
+// parent or subtask.
+type relatedItem struct {
+	Relation string `json:"relation"`
+	ID       string `json:"id"`
+	Content  string `json:"content,omitempty"`
+	State    string `json:"state,omitempty"`
+	Missing  bool   `json:"missing,omitempty"`
+}
+
+// itemDetail is the JSON form of the show command's output.
+type itemDetail struct {
+	listItemJSON
+	Pinned      bool                `json:"pinned,omitempty"`
+	Archived    bool                `json:"archived,omitempty"`
+	Transitions []models.Transition `json:"transitions,omitempty"`
+
+	Parent   *relatedItem  `json:"parent,omitempty"`
+	Children []relatedItem `json:"children,omitempty"`
+	Links    []relatedItem `json:"links,omitempty"`
+
+	References []refJSON          `json:"references,omitempty"`
+	Commits    []models.CommitRef `json:"commits,omitempty"`
+}
+
+// showTemplateFuncs are the functions available to --format templates.
+var showTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"date": func(t interface{}) string {
+		switch t := t.(type) {
+		case time.Time:
+			return t.Format("2006-01-02")
+		case *time.Time:
+			if t != nil {
+				return t.Format("2006-01-02")
+			}
+		}
+		return ""
+	},
+}
+
+// showCommand is the execution function for the show command.
+func showCommand(cmd *cobra.Command, args []string) error {
+	if jsonOutput && showFormatFlag != "" {
+		return fmt.Errorf("--json and --format cannot be used together")
+	}
+	var tmpl *template.Template
+	if showFormatFlag != "" {
+		var err error
+		if tmpl, err = template.New("format").Funcs(showTemplateFuncs).Parse(showFormatFlag); err != nil {
+			return fmt.Errorf("invalid --format template: %w", err)
+		}
+	}
+
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	var items []models.ContextItem
+	for _, ref := range args {
+		item, err := lookupItem(cmd, stor, ref)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	// References are a convenience here; 'ck refs' reports failures to find them
+	refs, _ := collectRefs(projectRoot(storagePath))
+	details := make([]itemDetail, len(items))
+	for i, item := range items {
+		details[i] = buildItemDetail(item, stor.GetAll())
+		recorded := make(map[string]bool)
+		for _, c := range item.Commits {
+			recorded[c.Hash] = true
+		}
+		for _, r := range refsTo(stor, item.ID, refs) {
+			// Commits recorded by the post-commit hook are listed on their own
+			if r.Commit == nil || !recorded[r.Commit.Hash] {
+				details[i].References = append(details[i].References, newRefJSON(r))
+			}
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	switch {
+	case jsonOutput:
+		var v interface{} = details
+		if len(details) == 1 {
+			v = details[0]
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal item to JSON: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+
+	case tmpl != nil:
+		for _, detail := range details {
+			var sb strings.Builder
+			if err := tmpl.Execute(&sb, detail); err != nil {
+				return fmt.Errorf("failed to format item %s: %w", detail.ID, err)
+			}
+			text := sb.String()
+			if !strings.HasSuffix(text, "\n") {
+				text += "\n"
+			}
+			fmt.Fprint(out, text)
+		}
+
+	default:
+		now := time.Now()
+		for i, item := range items {
+			if i > 0 {
+				fmt.Fprint(out, "\n---\n\n")
+			}
+			writeItemDetail(out, item, details[i], showRenderFlag, now)
+		}
+	}
+	return nil
+}
+
+// buildItemDetail collects the parent, subtasks and links of item from all
+// stored items. Links are listed outgoing first, then incoming ones with
+// their inverse label, such as "blocked by".
+func buildItemDetail(item models.ContextItem, all []models.ContextItem) itemDetail {
+	byID := make(map[string]models.ContextItem, len(all))
+	for _, other := range all {
+		byID[other.ID] = other
+	}
+	related := func(relation, id string) relatedItem {
+		r := relatedItem{Relation: relation, ID: shortID(id)}
+		if other, ok := byID[id]; ok {
+			r.Content = other.Content
+			r.State = other.EffectiveState()
+		} else {
+			r.Missing = true
+		}
+		return r
+	}
+
+	detail := itemDetail{
+		listItemJSON: newListItemJSON(item),
+		Pinned:       item.Pinned,
+		Archived:     item.Archived,
+		Transitions:  item.Transitions,
+		Commits:      item.Commits,
+	}
+	if item.ParentID != "" {
+		parent := related("subtask of", item.ParentID)
+		detail.Parent = &parent
+	}
+	for _, other := range all {
+		if other.ParentID == item.ID {
+			detail.Children = append(detail.Children, related("subtask", other.ID))
+		}
+	}
+	if p, ok := models.SubtaskProgress(all)[item.ID]; ok {
+		detail.Subtasks = &p
+	}
+
+	for _, l := range item.Links {
+		detail.Links = append(detail.Links, related(l.Type, l.Target))
+	}
+	for _, other := range all {
+		for _, l := range other.Links {
+			if l.Target == item.ID {
+				detail.Links = append(detail.Links, related(models.InverseLinkLabel(l.Type), other.ID))
+			}
+		}
+	}
+	return detail
+}
+
+// writeItemDetail prints the text form of the show command's output, with
+// the content's Markdown rendered if render is set.
+func writeItemDetail(out io.Writer, item models.ContextItem, detail itemDetail, render bool, now time.Time) {
+	if item.Number > 0 {
+		fmt.Fprintf(out, "#%d %s\n\n", item.Number, item.ID)
+	} else {
+		fmt.Fprintf(out, "%s\n\n", item.ID)
+	}
+	content := strings.TrimSpace(item.Content)
+	if render {
+		content = utils.RenderMarkdown(content)
+	}
+	fmt.Fprintf(out, "%s\n\n", content)
+
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(out, "%-10s %s\n", name+":", value)
+		}
+	}
+	state := item.EffectiveState()
+	if reason := item.StateReason(); reason != "" {
+		state += " (" + reason + ")"
+	}
+	field("State", state)
+	field("Project", item.Project)
+	field("Branch", item.Branch)
+	field("Tags", strings.Join(item.Tags, ", "))
+	field("Priority", item.Priority)
+	if item.DueAt != nil {
+		due := item.DueAt.Format("2006-01-02")
+		if item.CompletedAt == nil {
+			due += " (" + utils.FormatDue(*item.DueAt, now) + ")"
+		}
+		field("Due", due)
+	}
+	field("Created", item.CreatedAt.Format("2006-01-02 15:04"))
+	if item.CompletedAt != nil {
+		field("Closed", item.CompletedAt.Format("2006-01-02 15:04"))
+	}
+	if item.Pinned {
+		field("Pinned", "yes")
+	}
+	if item.Archived {
+		field("Archived", "yes")
+	}
+	if detail.Parent != nil {
+		field("Parent", formatRelated(*detail.Parent))
+	}
+
+	if len(detail.Children) > 0 {
+		if detail.Subtasks != nil {
+			fmt.Fprintf(out, "\nSubtasks (%s):\n", utils.FormatProgress(*detail.Subtasks))
+		} else {
+			fmt.Fprintln(out, "\nSubtasks:")
+		}
+		for _, child := range detail.Children {
+			fmt.Fprintf(out, "  %s\n", formatRelated(child))
+		}
+	}
+
+	if len(item.Transitions) > 0 {
+		fmt.Fprintln(out, "\nHistory:")
+		for _, t := range item.Transitions {
+			line := fmt.Sprintf("  %s  %s", t.At.Format("2006-01-02 15:04"), t.State)
+			if t.Reason != "" {
+				line += " (" + t.Reason + ")"
+			}
+			fmt.Fprintln(out, line)
+		}
+	}
+
+	if len(item.Anchors) > 0 {
+		fmt.Fprintln(out, "\nAnchors:")
+		for _, a := range item.Anchors {
+			line := "  " + a.String()
+			if a.Text != "" {
+				line += "  " + a.Text
+			}
+			if a.Stale {
+				line += "  (stale)"
+			}
+			fmt.Fprintln(out, line)
+		}
+	}
+
+	if len(detail.References) > 0 {
+		fmt.Fprintln(out, "\nReferenced in:")
+		for _, r := range detail.References {
+			location := filepath.FromSlash(r.Path) + ":" + strconv.Itoa(r.Line)
+			if r.Commit != "" {
+				location = "commit " + r.Commit[:min(7, len(r.Commit))]
+			}
+			fmt.Fprintf(out, "  %s  %s\n", location, r.Text)
+		}
+	}
+
+	if len(item.Commits) > 0 {
+		fmt.Fprintln(out, "\nCommits:")
+		for _, c := range item.Commits {
+			line := fmt.Sprintf("  %s  %s", c.Hash[:min(7, len(c.Hash))], c.Subject)
+			if c.Closed {
+				line += "  (closed)"
+			}
+			fmt.Fprintln(out, line)
+		}
+	}
+
+	if len(detail.Links) > 0 {
+		width := 0
+		for _, l := range detail.Links {
+			if len(l.Relation) > width {
+				width = len(l.Relation)
+			}
+		}
+		fmt.Fprintln(out, "\nLinks:")
+		for _, l := range detail.Links {
+			fmt.Fprintf(out, "  %-*s  %s\n", width, l.Relation, formatRelated(l))
+		}
+	}
+}
+
+// formatRelated formats a related item as "[abc12345] content (state)".
+func formatRelated(r relatedItem) string {
+	if r.Missing {
+		return fmt.Sprintf("[%s] (deleted item)", r.ID)
+	}
+	return fmt.Sprintf("[%s] %s (%s)", r.ID, previewContent(r.Content, 60), r.State)
+}
+
+// init registers the show command with the root command.
+func init() {
+	showCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	showCmd.Flags().BoolVar(&showRenderFlag, "render", false, "Render the content's Markdown")
+	showCmd.Flags().StringVar(&showFormatFlag, "format", "", "Print each item with a Go template")
+	RootCmd.AddCommand(showCmd)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestShowCommand(t *testing.T) {
+	_, storagePath := testStore(t)
+
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	long := "## Login\n\nSwitch the login to **OIDC**. " + strings.Repeat("The session cookies go away. ", 5) + "Run `make keys` first."
+	login := models.ContextItem{ID: "login111-item", Content: long, Project: "api", Tags: []string{"auth", "decision"}, CreatedAt: created}
+	login.SetState(models.StateBlocked, "waiting for the IdP", created.Add(time.Hour))
+	login.SetState(models.StateDone, "", created.Add(48*time.Hour))
+	stor := storage.NewStorage(storagePath)
+	for _, item := range []models.ContextItem{login, {ID: "docs2222-item", Content: "Document the API", CreatedAt: created}} {
+		if err := stor.Add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("full content and state history", func(t *testing.T) {
+		out := mustRun(t, "show", "login")
+		for _, want := range []string{
+			"#1 login111-item\n\n" + long + "\n\n",
+			"Project:   api\n",
+			"Tags:      auth, decision\n",
+			"Closed:    2026-10-03 09:00\n",
+			"History:\n  2026-10-01 10:00  blocked (waiting for the IdP)\n  2026-10-03 09:00  done\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected %q in output:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("several items", func(t *testing.T) {
+		out := mustRun(t, "show", "1", "#2")
+		if !strings.Contains(out, "login111-item") || !strings.Contains(out, "\n---\n\n#2 docs2222-item\n") {
+			t.Errorf("Expected both items, got:\n%s", out)
+		}
+		if _, err := run(t, "show", "login", "nothing"); err == nil || err.Error() != "item not found: nothing" {
+			t.Errorf("Expected an error for an unknown item, got %v", err)
+		}
+	})
+
+	t.Run("rendered Markdown", func(t *testing.T) {
+		out := mustRun(t, "show", "login", "--render")
+		if strings.Contains(out, "## Login") || strings.Contains(out, "**OIDC**") || !strings.Contains(out, "\033[1mLogin\033[0m") {
+			t.Errorf("Expected rendered Markdown, got:\n%s", out)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var detail itemDetail
+		if err := json.Unmarshal([]byte(mustRun(t, "show", "login", "--json")), &detail); err != nil {
+			t.Fatal(err)
+		}
+		if detail.Content != long || len(detail.Transitions) != 2 || detail.Transitions[0].Reason != "waiting for the IdP" {
+			t.Errorf("Unexpected detail: %+v", detail)
+		}
+
+		var details []itemDetail
+		if err := json.Unmarshal([]byte(mustRun(t, "show", "login", "docs", "--json")), &details); err != nil {
+			t.Fatal(err)
+		}
+		if len(details) != 2 || details[1].FullID != "docs2222-item" {
+			t.Errorf("Unexpected details: %+v", details)
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		out := mustRun(t, "show", "1", "2", "--format", `#{{.Number}} {{.State}} {{date .CreatedAt}} [{{join .Tags ", "}}] {{date .CompletedAt}}`)
+		want := "#1 done 2026-10-01 [auth, decision] 2026-10-03\n#2 todo 2026-10-01 [] \n"
+		if out != want {
+			t.Errorf("Output = %q, want %q", out, want)
+		}
+		if _, err := run(t, "show", "1", "--format", "{{.Nope"); err == nil || !strings.Contains(err.Error(), "invalid --format template") {
+			t.Errorf("Expected a template error, got %v", err)
+		}
+		if _, err := run(t, "show", "1", "--format", "{{.ID}}", "--json"); err == nil {
+			t.Error("Expected an error for --format with --json")
+		}
+	})
+}
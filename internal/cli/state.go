@@ -0,0 +1,152 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// startCmd moves an item to the in-progress state.
+var startCmd = &cobra.Command{
+	Use:   "start <id>",
+	Short: "Mark a context item as in progress",
+	Long:  "Mark a context item as in progress. In-progress items are highlighted in list output and synced agent files.",
+	Example: `  # Start working on an item
+  ck start abc12345`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return transitionItem(cmd, args[0], models.StateInProgress, "")
+	},
+}
+
+// blockCmd moves an item to the blocked state.
+var blockCmd = &cobra.Command{
+	Use:   "block <id>",
+	Short: "Mark a context item as blocked",
+	Long:  "Mark a context item as blocked, optionally recording why. Use 'ck start' or 'ck reopen' to unblock it.",
+	Example: `  # Block an item
+  ck block abc12345 --reason "waiting for API keys"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return transitionItem(cmd, args[0], models.StateBlocked, stateReasonFlag)
+	},
+}
+
+// reopenCmd moves an item back to the todo state.
+var reopenCmd = &cobra.Command{
+	Use:   "reopen <id>",
+	Short: "Reopen a context item",
+	Long:  "Move a context item back to todo, whether it was done, dropped as wontfix, blocked or in progress.",
+	Example: `  # Reopen a completed item
+  ck reopen abc12345`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return transitionItem(cmd, args[0], models.StateTodo, stateReasonFlag)
+	},
+}
+
+// wontfixCmd closes an item without completing it.
+var wontfixCmd = &cobra.Command{
+	Use:   "wontfix <id>",
+	Short: "Close a context item as won't fix",
+	Long:  "Close a context item without completing it. Wontfix items are hidden like completed ones and never synced to agent files.",
+	Example: `  # Drop an item
+  ck wontfix abc12345 --reason "superseded by the new auth flow"
+
+  # Drop an item and all of its open subtasks
+  ck wontfix abc12345 --cascade`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return transitionItem(cmd, args[0], models.StateWontfix, stateReasonFlag)
+	},
+}
+
+// Command flags for the state commands.
+var (
+	stateReasonFlag  string // --reason: Why the state changed
+	stateSyncFlag    bool   // --sync: Sync to AI agent files afterwards
+	stateCascadeFlag bool   // --cascade: Also close open subtasks (wontfix)
+)
+
+// stateLabels are the past-tense descriptions of moving into each state.
+var stateLabels = map[string]string{
+	models.StateTodo:       "reopened",
+	models.StateInProgress: "started",
+	models.StateBlocked:    "blocked",
+	models.StateDone:       "completed",
+	models.StateWontfix:    "closed as wontfix",
+}
+
+// transitionItem moves the item matching id to state and saves it.
+func transitionItem(cmd *cobra.Command, id, state, reason string) error {
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	item, err := lookupItem(cmd, stor, id)
+	if err != nil {
+		return err
+	}
+	if current := item.EffectiveState(); current == state && state != models.StateBlocked {
+		return fmt.Errorf("item %s is already %s", shortID(item.ID), current)
+	}
+
+	item.SetState(state, strings.TrimSpace(reason), time.Now())
+	if err := stor.Update(item); err != nil {
+		return fmt.Errorf("failed to update item %q: %w", item.ID, err)
+	}
+
+	closed := 0
+	if state == models.StateWontfix {
+		if closed, err = closeSubtasks(stor, cmd, item, state, stateCascadeFlag); err != nil {
+			return err
+		}
+	}
+
+	if jsonOutput {
+		result := map[string]string{
+			"id":    shortID(item.ID),
+			"state": state,
+		}
+		data, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	} else {
+		cmd.Printf("Item %s: %s\n", stateLabels[state], shortID(item.ID))
+		if closed > 0 {
+			cmd.Printf("Also closed %d subtasks as wontfix\n", closed)
+		}
+	}
+
+	if stateSyncFlag {
+		synced := syncAfterCRUD(cmd.OutOrStdout())
+		if synced > 0 {
+			cmd.Printf("Synced %d files\n", synced)
+		}
+	}
+
+	return nil
+}
+
+// init registers the state commands with the root command.
+func init() {
+	for _, c := range []*cobra.Command{startCmd, blockCmd, reopenCmd, wontfixCmd} {
+		c.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+		c.Flags().BoolVar(&stateSyncFlag, "sync", false, "Sync to AI agent rule files afterwards")
+		if c != startCmd {
+			c.Flags().StringVarP(&stateReasonFlag, "reason", "r", "", "Why the state changed")
+		}
+		RootCmd.AddCommand(c)
+	}
+	wontfixCmd.Flags().BoolVar(&stateCascadeFlag, "cascade", false, "Also close all open subtasks as wontfix")
+}
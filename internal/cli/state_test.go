@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestStateCommands(t *testing.T) {
+	_, storagePath := testStore(t)
+	stor := storage.NewStorage(storagePath)
+	completed := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	stor.Add(models.ContextItem{ID: "aaaa1111-item", Content: "Wire up OAuth", CreatedAt: time.Now()})
+	stor.Add(models.ContextItem{ID: "bbbb2222-item", Content: "Legacy finished item", CreatedAt: time.Now(), CompletedAt: &completed})
+	stor.Add(models.ContextItem{ID: "cccc3333-item", Content: "Try the old SDK", CreatedAt: time.Now()})
+
+	get := func(t *testing.T, id string) models.ContextItem {
+		t.Helper()
+		stor.Load()
+		item, err := stor.GetByPrefix(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return item
+	}
+
+	t.Run("start, block and reopen", func(t *testing.T) {
+		if out, err := run(t, "start", "aaaa1111"); err != nil || !strings.Contains(out, "Item started") {
+			t.Fatalf("start failed: %v %q", err, out)
+		}
+		if _, err := run(t, "start", "aaaa1111"); err == nil {
+			t.Error("Expected error starting an item twice")
+		}
+		if _, err := run(t, "block", "aaaa1111", "--reason", "waiting for keys"); err != nil {
+			t.Fatalf("block failed: %v", err)
+		}
+		item := get(t, "aaaa1111")
+		if item.EffectiveState() != models.StateBlocked || item.StateReason() != "waiting for keys" || len(item.Transitions) != 2 {
+			t.Errorf("Unexpected blocked item: %+v", item)
+		}
+
+		out, _ := run(t, "list")
+		if !strings.Contains(out, "(blocked: waiting for keys)") {
+			t.Errorf("Expected blocked reason in list output, got %q", out)
+		}
+
+		out, _ = run(t, "agenda", "--json")
+		var agenda map[string][]agendaBlockedJSON
+		if err := json.Unmarshal([]byte(out), &agenda); err != nil {
+			t.Fatal(err)
+		}
+		if blocked := agenda["blocked"]; len(blocked) != 1 || blocked[0].FullID != "aaaa1111-item" || blocked[0].Reason != "waiting for keys" {
+			t.Errorf("Expected the blocked item in agenda JSON, got %+v", agenda["blocked"])
+		}
+	})
+
+	t.Run("legacy completed items reopen cleanly", func(t *testing.T) {
+		if _, err := run(t, "reopen", "bbbb2222"); err != nil {
+			t.Fatalf("reopen failed: %v", err)
+		}
+		item := get(t, "bbbb2222")
+		if item.CompletedAt != nil || item.EffectiveState() != models.StateTodo {
+			t.Errorf("Unexpected reopened item: %+v", item)
+		}
+	})
+
+	t.Run("wontfix items are hidden and not synced", func(t *testing.T) {
+		if _, err := run(t, "wontfix", "cccc3333", "-r", "dropped"); err != nil {
+			t.Fatalf("wontfix failed: %v", err)
+		}
+		var items []map[string]interface{}
+		out, _ := run(t, "list", "--json")
+		json.Unmarshal([]byte(out), &items)
+		if len(items) != 2 {
+			t.Errorf("Expected 2 open items, got %d", len(items))
+		}
+
+		out, _ = run(t, "list", "--json", "status:wontfix")
+		items = nil
+		json.Unmarshal([]byte(out), &items)
+		if len(items) != 1 || items[0]["state"] != "wontfix" {
+			t.Errorf("Expected the wontfix item, got %v", items)
+		}
+
+		syncQueryFlag = "status:any"
+		defer func() { syncQueryFlag = "" }()
+		stor.Load()
+		content, err := buildSyncContent(stor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(content, "Try the old SDK") {
+			t.Errorf("Wontfix item should not be synced:\n%s", content)
+		}
+		if !strings.Contains(content, "Wire up OAuth _(today, blocked)_") {
+			t.Errorf("Expected blocked flag in sync output:\n%s", content)
+		}
+	})
+
+	t.Run("done records a transition", func(t *testing.T) {
+		if _, err := run(t, "done", "aaaa1111"); err != nil {
+			t.Fatalf("done failed: %v", err)
+		}
+		item := get(t, "aaaa1111")
+		if item.EffectiveState() != models.StateDone || item.CompletedAt == nil || item.Transitions[len(item.Transitions)-1].State != models.StateDone {
+			t.Errorf("Unexpected done item: %+v", item)
+		}
+
+		transitions, completedAt := len(item.Transitions), *item.CompletedAt
+		if _, err := run(t, "done", "aaaa1111"); err == nil || !strings.Contains(err.Error(), "already done") {
+			t.Errorf("Expected error completing an item twice, got %v", err)
+		}
+		if _, err := run(t, "done", "cccc3333"); err == nil || !strings.Contains(err.Error(), "already wontfix") {
+			t.Errorf("Expected error completing a wontfix item, got %v", err)
+		}
+		if out, err := run(t, "done", "aaaa1111", "cccc3333", "--yes"); err != nil || !strings.Contains(out, "No matching items") {
+			t.Errorf("Expected bulk done to skip closed items: %v %q", err, out)
+		}
+		item = get(t, "aaaa1111")
+		if len(item.Transitions) != transitions || !item.CompletedAt.Equal(completedAt) {
+			t.Errorf("Completing again changed the item: %+v", item)
+		}
+	})
+}
@@ -10,14 +10,16 @@ import (
 	"time"
 
 	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
 	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
 	"github.com/spf13/cobra"
 )
 
 // statusCmd displays a quick overview of context items.
 //
-// The command shows storage path, total item counts, and the age of
-// the oldest item.
+// The command shows storage path, total item counts, overdue items, subtask
+// progress and the age of the oldest item.
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show quick overview",
@@ -46,8 +48,16 @@ func statusCommand(cmd *cobra.Command, args []string) error {
 	// Calculate statistics
 	total := len(allItems)
 	completed := 0
+	overdue := 0
+	dueToday := 0
+	inProgress := 0
+	blocked := 0
+	now := time.Now()
 	var oldest time.Time
 	oldestSet := false
+	progress := models.SubtaskProgress(allItems)
+	var subtasks models.Progress
+	openParents := 0
 	projectsMap := make(map[string]bool)
 	tagsMap := make(map[string]bool)
 
@@ -57,6 +67,27 @@ func statusCommand(cmd *cobra.Command, args []string) error {
 			completed++
 		}
 
+		switch item.EffectiveState() {
+		case models.StateInProgress:
+			inProgress++
+		case models.StateBlocked:
+			blocked++
+		}
+
+		// Count items that need attention
+		if item.IsOverdue(now) {
+			overdue++
+		} else if item.CompletedAt == nil && item.DueAt != nil && utils.DaysUntil(*item.DueAt, now) == 0 {
+			dueToday++
+		}
+
+		// Roll up the subtasks of open parents
+		if p, ok := progress[item.ID]; ok && item.CompletedAt == nil {
+			openParents++
+			subtasks.Done += p.Done
+			subtasks.Total += p.Total
+		}
+
 		// Find oldest item
 		if !item.CreatedAt.IsZero() {
 			if !oldestSet || item.CreatedAt.Before(oldest) {
@@ -86,11 +117,17 @@ func statusCommand(cmd *cobra.Command, args []string) error {
 		}
 
 		status := map[string]interface{}{
-			"totalItems":     total,
-			"completedItems": completed,
-			"activeItems":    active,
-			"projects":       projects,
-			"tags":           tags,
+			"totalItems":      total,
+			"completedItems":  completed,
+			"activeItems":     active,
+			"inProgressItems": inProgress,
+			"blockedItems":    blocked,
+			"overdueItems":    overdue,
+			"dueTodayItems":   dueToday,
+			"openParents":     openParents,
+			"subtasks":        subtasks,
+			"projects":        projects,
+			"tags":            tags,
 		}
 		data, err := json.MarshalIndent(status, "", "  ")
 		if err != nil {
@@ -107,6 +144,22 @@ func statusCommand(cmd *cobra.Command, args []string) error {
 	fmt.Fprintf(cmd.OutOrStdout(), "Total Items: %d\n", total)
 	fmt.Fprintf(cmd.OutOrStdout(), "Active:      %d\n", active)
 	fmt.Fprintf(cmd.OutOrStdout(), "Completed:   %d\n", completed)
+	if inProgress > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "In progress: %d\n", inProgress)
+	}
+	if blocked > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Blocked:     %d\n", blocked)
+	}
+	if overdue > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Overdue:     %d (run 'ck agenda')\n", overdue)
+	}
+	if dueToday > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Due today:   %d\n", dueToday)
+	}
+
+	if openParents > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Subtasks:    %s across %d open parents\n", utils.FormatProgress(subtasks), openParents)
+	}
 
 	if oldestSet {
 		daysAgo := int(time.Since(oldest).Hours() / 24)
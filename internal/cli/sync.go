@@ -9,12 +9,17 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/git"
 	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/query"
 	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -34,6 +39,7 @@ import (
 //	1 - Storage error or file write error
 var syncCmd = &cobra.Command{
 	Use:     "sync",
+	Args:    cobra.NoArgs,
 	Short:   "Sync context to AI agent rule files",
 	Long:    syncLongDesc,
 	Example: syncExample,
@@ -48,7 +54,45 @@ The generated files include a header noting they are auto-generated
 and list all active items with their IDs, content, and tags.
 
 If no agent directories are found, it falls back to .contextkeeper/instructions.md
-if that directory exists.`
+if that directory exists.
+
+Items can be grouped into sections by project, tag or kind (the first tag
+listed in the "kinds" setting), either with --group-by or through the "sync"
+section of .contextkeeper/config.json:
+
+  {
+    "sync": {
+      "group_by": "kind",
+      "order": ["decision", "bug"],
+      "limit": 20,
+      "kinds": ["decision", "bug", "feature", "todo", "idea"]
+    }
+  }
+
+Pinned items (see 'ck pin') are listed first within each section. Items
+closed as wontfix or superseded by another item (see 'ck link') are never
+synced. Items bound to a git branch (see 'ck add --branch') are only synced
+while that branch is checked out.
+
+Use --query, or "filter" in the sync section of config.json, to sync only
+the items matching a query, e.g. "-tag:wontfix -project:legacy".
+
+In a monorepo, a project's items can also be synced to the agent rule
+directories of its own directory, set as "sync_dir" relative to the
+repository root:
+
+  {
+    "projects": {
+      "platform/api": {"sync_dir": "services/api"}
+    }
+  }
+
+With --pull, edits made directly to the generated files are read back
+instead: changed lines become content edits, lines checked off ("- [x]")
+or struck through ("~~...~~") mark items done, lines removed from the file
+mark items done, and new bullets without an ID become new items, in the
+project of the file for files under a project's sync_dir. The proposed
+changes are shown for confirmation before being applied.`
 
 // syncExample provides usage examples for the sync command.
 const syncExample = `  # Sync to AI agents (Claude Code and Cursor)
@@ -57,22 +101,41 @@ const syncExample = `  # Sync to AI agents (Claude Code and Cursor)
   # Output from sync command
   $ ck sync
   Synced to .claude/rules/ck-context.md
-  Synced to .cursor/rules/ck-context.mdc`
+  Synced to .cursor/rules/ck-context.mdc
+
+  # Group items by project, at most 10 per project
+  ck sync --group-by project --limit 10
+
+  # Only sync open bugs
+  ck sync --query 'tag:bug'
+
+  # Pull edits made to the generated files back into the store
+  ck sync --pull
+
+  # Apply pulled edits without confirmation
+  ck sync --pull --yes`
 
 // runSync is the execution function for the sync command.
 // It loads active items from storage and writes them to AI agent rule files.
 func runSync(cmd *cobra.Command, args []string) error {
+	if syncPullFlag {
+		return runSyncPull(cmd)
+	}
+
 	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
 	if err := stor.Load(); err != nil {
 		return fmt.Errorf("failed to load storage: %w", err)
 	}
 
-	items := stor.GetAll()
-	activeItems := filterActive(items)
-
-	content := generateMarkdown(activeItems)
+	content, err := buildSyncContent(stor)
+	if err != nil {
+		return err
+	}
 
-	_, err := SyncToFiles(content, cmd.OutOrStdout())
+	if _, err = SyncToFiles(content, cmd.OutOrStdout()); err != nil {
+		return err
+	}
+	_, err = syncProjectDirs(stor, cmd.OutOrStdout())
 	return err
 }
 
@@ -138,13 +201,7 @@ func SyncToFiles(content string, output io.Writer) (int, error) {
 	syncedCount := 0
 	var lastErr error
 
-	// Define target paths for standard AI agents
-	targets := []string{
-		filepath.Join(".claude", "rules", "ck-context.md"),
-		filepath.Join(".cursor", "rules", "ck-context.mdc"),
-	}
-
-	for _, path := range targets {
+	for _, path := range agentTargets() {
 		dir := filepath.Dir(path)
 		if _, err := os.Stat(dir); os.IsNotExist(err) {
 			continue // Skip non-existent directories
@@ -162,7 +219,7 @@ func SyncToFiles(content string, output io.Writer) (int, error) {
 	// Fallback to .contextkeeper if no agent rules folders found
 	if syncedCount == 0 {
 		if _, err := os.Stat(".contextkeeper"); !os.IsNotExist(err) {
-			fallbackPath := filepath.Join(".contextkeeper", "instructions.md")
+			fallbackPath := fallbackTarget()
 			if err := os.WriteFile(fallbackPath, []byte(content), 0644); err != nil {
 				lastErr = err
 			} else {
@@ -180,6 +237,19 @@ func SyncToFiles(content string, output io.Writer) (int, error) {
 	return syncedCount, lastErr
 }
 
+// agentTargets returns the rule file paths for the supported AI agents.
+func agentTargets() []string {
+	return []string{
+		filepath.Join(".claude", "rules", "ck-context.md"),
+		filepath.Join(".cursor", "rules", "ck-context.mdc"),
+	}
+}
+
+// fallbackTarget returns the sync file path used when no agent directory exists.
+func fallbackTarget() string {
+	return filepath.Join(".contextkeeper", "instructions.md")
+}
+
 // syncAfterCRUD syncs active items to files after a CRUD operation.
 // This is a helper that can be called by add, done, remove, and edit commands.
 //
@@ -196,9 +266,11 @@ func syncAfterCRUD(output io.Writer) int {
 		return 0
 	}
 
-	items := stor.GetAll()
-	activeItems := filterActive(items)
-	content := generateMarkdown(activeItems)
+	content, err := buildSyncContent(stor)
+	if err != nil {
+		fmt.Fprintf(output, "Warning: failed to prepare sync content: %v\n", err)
+		return 0
+	}
 
 	synced, err := SyncToFiles(content, output)
 	if err != nil {
@@ -206,29 +278,154 @@ func syncAfterCRUD(output io.Writer) int {
 		return synced
 	}
 
-	return synced
+	projectSynced, err := syncProjectDirs(stor, output)
+	if err != nil {
+		fmt.Fprintf(output, "Warning: sync failed: %v\n", err)
+	}
+	return synced + projectSynced
+}
+
+// projectTarget is an agent rule file that receives the items of a project.
+type projectTarget struct {
+	Project string
+	Path    string
+}
+
+// projectTargets returns the agent rule files under the sync directories
+// of the projects that have one (see config.ProjectConfig), by project.
+func projectTargets() ([]projectTarget, error) {
+	storagePath := config.FindStoragePath(pathFlag)
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	projects := make([]string, 0, len(cfg.Projects))
+	for project, settings := range cfg.Projects {
+		if settings.SyncDir != "" {
+			projects = append(projects, project)
+		}
+	}
+	sort.Strings(projects)
+
+	var targets []projectTarget
+	for _, project := range projects {
+		dir := filepath.Join(projectRoot(storagePath), cfg.Projects[project].SyncDir)
+		for _, target := range agentTargets() {
+			targets = append(targets, projectTarget{Project: project, Path: filepath.Join(dir, target)})
+		}
+	}
+	return targets, nil
+}
+
+// syncProjectDirs writes the items of each project with a sync directory
+// to the agent rule files under that directory. Like SyncToFiles, it skips
+// agent directories that do not exist, and it returns the number of files
+// written.
+func syncProjectDirs(stor storage.Storage, output io.Writer) (int, error) {
+	targets, err := projectTargets()
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	var lastErr error
+	contents := make(map[string]string)
+	for _, target := range targets {
+		if _, err := os.Stat(filepath.Dir(target.Path)); os.IsNotExist(err) {
+			continue
+		}
+		content, ok := contents[target.Project]
+		if !ok {
+			if content, err = buildProjectSyncContent(stor, target.Project); err != nil {
+				return synced, err
+			}
+			contents[target.Project] = content
+		}
+		if err := os.WriteFile(target.Path, []byte(content), 0644); err != nil {
+			lastErr = err
+			continue
+		}
+		fmt.Fprintf(output, "Synced %s to %s\n", target.Project, target.Path)
+		synced++
+	}
+	return synced, lastErr
+}
+
+// buildSyncContent generates the sync file content for the active items in
+// stor, using the layout and filter from the store configuration and the
+// sync flags. As with list, a filter that mentions status replaces the
+// default of syncing only active items, and one that mentions branch the
+// default of syncing global items and those of the current git branch.
+func buildSyncContent(stor storage.Storage) (string, error) {
+	return buildProjectSyncContent(stor, "")
+}
+
+// buildProjectSyncContent is buildSyncContent for the items of a project
+// and its subprojects, or for all items if project is empty.
+func buildProjectSyncContent(stor storage.Storage, project string) (string, error) {
+	opts, err := loadSyncOptions()
+	if err != nil {
+		return "", err
+	}
+
+	items := stor.GetAll()
+	opts.Progress = models.SubtaskProgress(items)
+	opts.BlockedBy = models.Blockers(items)
+	superseded := models.Superseded(items)
+	if project != "" {
+		items = filterByProject(items, project)
+	}
+	if !query.HasField(opts.Filter, query.FieldStatus) {
+		items = filterActive(items)
+	}
+	if !query.HasField(opts.Filter, query.FieldBranch) {
+		items = filterBranch(items, git.CurrentBranch(projectRoot(config.FindStoragePath(pathFlag))))
+	}
+	items = query.Filter(items, opts.Filter)
+
+	// Items dropped as wontfix or superseded by another item are never
+	// useful context for agents
+	synced := make([]models.ContextItem, 0, len(items))
+	for _, item := range items {
+		if _, ok := superseded[item.ID]; ok {
+			continue
+		}
+		if item.EffectiveState() != models.StateWontfix {
+			synced = append(synced, item)
+		}
+	}
+	return generateMarkdown(synced, opts), nil
 }
 
 // generateMarkdown creates a formatted Markdown string from context items.
 // It produces a header indicating auto-generation and lists all active items
-// with their ID prefixes, content, and tags.
+// with their ID prefixes, content, and tags. When opts.GroupBy is set, the
+// items are split into sections (see groupSyncItems).
 //
 // The output format is designed to be easily readable by AI agents and humans:
 //
 //	# Project Context (via ContextKeeper)
 //	> [!IMPORTANT]
-//	> This file is auto-generated by ContextKeeper. Manual changes will be overwritten.
+//	> This file is auto-generated by ContextKeeper. Manual changes will be overwritten
+//	> unless pulled back into the store with `ck sync --pull` first.
 //	_Last updated: 2026-02-10T18:00:00Z_
 //
 //	## Active Items
 //
-//	- [abc12345] Fix the auth bug (@bug, @security)
-//	- [def67890] Add new feature (@feature)
-func generateMarkdown(items []models.ContextItem) string {
+//	- [abc12345] Fix the auth bug (@bug, @security) _(3d, pinned)_
+//	- [def67890] Add new feature (@feature) _(2w)_
+//
+//	<!-- ck:items abc12345 def67890 -->
+//
+// The trailing comment records which items were written so that
+// `ck sync --pull` can tell a deleted line apart from an item that was
+// added to the store after the file was generated.
+func generateMarkdown(items []models.ContextItem, opts syncOptions) string {
 	var sb strings.Builder
 	sb.WriteString("# Project Context (via ContextKeeper)\n")
 	sb.WriteString("> [!IMPORTANT]\n")
-	sb.WriteString("> This file is auto-generated by ContextKeeper. Manual changes will be overwritten.\n\n")
+	sb.WriteString("> This file is auto-generated by ContextKeeper. Manual changes will be overwritten\n")
+	sb.WriteString("> unless pulled back into the store with `ck sync --pull` first.\n\n")
 	sb.WriteString("_Last updated: " + time.Now().Format(time.RFC3339) + "_\n\n")
 
 	if len(items) == 0 {
@@ -237,27 +434,132 @@ func generateMarkdown(items []models.ContextItem) string {
 	}
 
 	sb.WriteString("## Active Items\n\n")
-	for _, item := range items {
-		sb.WriteString(formatItemLine(item))
+	now := time.Now()
+	var written []models.ContextItem
+	for i, section := range groupSyncItems(items, opts) {
+		if section.Name != "" {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("### " + section.Name + "\n\n")
+		}
+
+		shown := section.Items
+		if opts.Limit > 0 && len(shown) > opts.Limit {
+			shown = shown[:opts.Limit]
+		}
+		for _, item := range shown {
+			sb.WriteString(formatItemLine(item, opts, now))
+		}
+		if hidden := len(section.Items) - len(shown); hidden > 0 {
+			fmt.Fprintf(&sb, "> %d more not shown. Run `ck list` to see all items.\n", hidden)
+		}
+		written = append(written, shown...)
 	}
+	sb.WriteString(formatManifest(written))
 
 	return sb.String()
 }
 
 // formatItemLine formats a single context item as a Markdown list item.
-func formatItemLine(item models.ContextItem) string {
-	id := item.ID
-	if len(id) > 8 {
-		id = id[:8]
-	}
-
-	line := fmt.Sprintf("- [%s] %s", id, item.Content)
+// Unless opts.HideMetadata is set, compact metadata such as the item's age,
+// priority and due date is appended in italics. Items that are in progress,
+// blocked or overdue are flagged either way, as are the items blocking this
+// one and the subtask progress of parent items.
+func formatItemLine(item models.ContextItem, opts syncOptions, now time.Time) string {
+	line := fmt.Sprintf("- [%s] %s", shortID(item.ID), item.Content)
 	if len(item.Tags) > 0 {
 		line += fmt.Sprintf(" (@%s)", strings.Join(item.Tags, ", @"))
 	}
+	meta := itemMetadata(item, now, !opts.HideMetadata)
+	if blockers := opts.BlockedBy[item.ID]; len(blockers) > 0 {
+		ids := make([]string, len(blockers))
+		for i, id := range blockers {
+			ids[i] = shortID(id)
+		}
+		meta = append(meta, "blocked by "+strings.Join(ids, ", "))
+	}
+	if p, ok := opts.Progress[item.ID]; ok {
+		meta = append(meta, utils.FormatProgress(p))
+	}
+	if len(meta) > 0 {
+		line += fmt.Sprintf(" _(%s)_", strings.Join(meta, ", "))
+	}
 	return line + "\n"
 }
 
+// itemMetadata returns the compact metadata shown after an item in sync files.
+// Without full, only the flags that call for attention are returned: the
+// item's state if it is in progress or blocked, and its due date if overdue.
+func itemMetadata(item models.ContextItem, now time.Time, full bool) []string {
+	var meta []string
+	if full {
+		if age := utils.FormatAge(item.CreatedAt, now); age != "" {
+			meta = append(meta, age)
+		}
+		if item.Pinned {
+			meta = append(meta, "pinned")
+		}
+	}
+	switch item.EffectiveState() {
+	case models.StateInProgress:
+		meta = append(meta, "in progress")
+	case models.StateBlocked:
+		meta = append(meta, "blocked")
+	}
+	if full && item.Priority != "" {
+		meta = append(meta, item.Priority)
+	}
+	if item.DueAt != nil && item.CompletedAt == nil && (full || item.IsOverdue(now)) {
+		meta = append(meta, utils.FormatDue(*item.DueAt, now))
+	}
+	if full && item.ParentID != "" {
+		meta = append(meta, "subtask of "+shortID(item.ParentID))
+	}
+	if full {
+		for _, a := range item.Anchors {
+			if !a.Stale {
+				meta = append(meta, "at "+a.Path+":"+strconv.Itoa(a.Line))
+			}
+		}
+	}
+	return meta
+}
+
+// formatManifest formats the HTML comment listing the IDs written to a sync file.
+func formatManifest(items []models.ContextItem) string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, shortID(item.ID))
+	}
+	return fmt.Sprintf("\n%s %s -->\n", manifestPrefix, strings.Join(ids, " "))
+}
+
+// shortID returns the short form of an item ID shown to users and used in
+// sync files, see models.ShortID.
+func shortID(id string) string {
+	return models.ShortID(id)
+}
+
+// Command flags for the sync command.
+var (
+	// syncPullFlag reads edits from the generated files back into the store
+	syncPullFlag bool
+	// syncYesFlag applies pulled changes without asking for confirmation
+	syncYesFlag bool
+	// syncGroupByFlag overrides the configured section grouping
+	syncGroupByFlag string
+	// syncLimitFlag overrides the configured per-section item limit
+	syncLimitFlag int
+	// syncQueryFlag overrides the configured filter query
+	syncQueryFlag string
+)
+
 func init() {
+	syncCmd.Flags().BoolVar(&syncPullFlag, "pull", false, "Pull edits made to generated agent files back into the store")
+	syncCmd.Flags().BoolVarP(&syncYesFlag, "yes", "y", false, "Apply pulled changes without confirmation")
+	syncCmd.Flags().StringVar(&syncGroupByFlag, "group-by", "", "Group items into sections: project, tag, kind or none")
+	syncCmd.Flags().IntVar(&syncLimitFlag, "limit", 0, "Maximum number of items per section")
+	syncCmd.Flags().StringVarP(&syncQueryFlag, "query", "q", "", "Only sync items matching this query (see 'ck list --help')")
 	RootCmd.AddCommand(syncCmd)
 }
@@ -0,0 +1,393 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// manifestPrefix starts the HTML comment that lists the IDs written to a sync file.
+const manifestPrefix = "<!-- ck:items"
+
+// Kinds of changes detected by sync --pull.
+const (
+	pullAdd  = "add"
+	pullEdit = "edit"
+	pullDone = "done"
+)
+
+var (
+	// pullItemRegex matches a Markdown bullet with an optional checkbox and ID prefix.
+	pullItemRegex = regexp.MustCompile(`^[-*+]\s+(?:\[([ xX])\]\s+)?(?:\[([0-9A-Za-z-]{2,})\](?:\s+|$))?(.*)$`)
+	// pullTagsRegex matches the trailing tag list emitted by formatItemLine.
+	pullTagsRegex = regexp.MustCompile(`\s*\(@([^()]*)\)\s*$`)
+	// pullMetaRegex matches the trailing italic metadata emitted by formatItemLine.
+	pullMetaRegex = regexp.MustCompile(`\s*_\([^()]*\)_\s*$`)
+)
+
+// pulledItem is a single bullet parsed back from a generated sync file.
+type pulledItem struct {
+	ID      string   // ID prefix from the line, empty for new bullets
+	Content string   // Item content with tags stripped
+	Tags    []string // Tags from the trailing "(@tag, ...)" list
+	Done    bool     // Line was checked off or struck through
+}
+
+// pulledFile is the parsed form of a generated sync file.
+type pulledFile struct {
+	Path     string
+	Project  string // Project whose items the file lists, empty for all items
+	Items    []pulledItem
+	Manifest []string // IDs recorded when the file was generated
+}
+
+// pullChange is a single proposed change to the store.
+type pullChange struct {
+	Kind   string             // pullAdd, pullEdit or pullDone
+	Item   models.ContextItem // Item after the change is applied
+	Before string             // Previous content for edits
+	Reason string             // Short explanation shown in the preview
+	Source string             // Sync file the change was read from
+}
+
+// runSyncPull reads the generated sync files, shows the changes made to them
+// and applies those changes to the store after confirmation.
+func runSyncPull(cmd *cobra.Command) error {
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+
+	// The files of projects with a sync directory are read too, as they
+	// are regenerated afterwards
+	targets := []projectTarget{}
+	for _, path := range append(agentTargets(), fallbackTarget()) {
+		targets = append(targets, projectTarget{Path: path})
+	}
+	projects, err := projectTargets()
+	if err != nil {
+		return err
+	}
+	var files []pulledFile
+	for _, target := range append(targets, projects...) {
+		data, err := os.ReadFile(target.Path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to read %s: %w", target.Path, err)
+		}
+		file := parseSyncMarkdown(string(data))
+		file.Path = target.Path
+		file.Project = target.Project
+		files = append(files, file)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(files) == 0 {
+		fmt.Fprintln(out, "No synced files found to pull from.")
+		return nil
+	}
+
+	changes := detectPullChanges(stor, files, out)
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "No changes to pull.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Proposed changes (%d):\n", len(changes))
+	for _, change := range changes {
+		fmt.Fprintln(out, formatPullChange(change))
+	}
+
+	if !syncYesFlag && !confirm(cmd, fmt.Sprintf("Apply %d changes?", len(changes))) {
+		fmt.Fprintln(out, "Cancelled.")
+		return nil
+	}
+
+	err = stor.Batch(func() error {
+		for _, change := range changes {
+			var err error
+			if change.Kind == pullAdd {
+				err = stor.Add(change.Item)
+			} else {
+				err = stor.Update(change.Item)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to apply %s for %s: %w", change.Kind, shortID(change.Item.ID), err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Applied %d changes\n", len(changes))
+
+	// Regenerate the files so they match the store again
+	content, err := buildSyncContent(stor)
+	if err != nil {
+		return err
+	}
+	if _, err := SyncToFiles(content, out); err != nil {
+		fmt.Fprintf(out, "Warning: sync failed: %v\n", err)
+	}
+	if _, err := syncProjectDirs(stor, out); err != nil {
+		fmt.Fprintf(out, "Warning: sync failed: %v\n", err)
+	}
+	return nil
+}
+
+// parseSyncMarkdown parses a file produced by generateMarkdown.
+//
+// Headings, the block quote header, the timestamp line and HTML comments are
+// skipped. Lines that are not bullets are treated as continuation lines of the
+// preceding bullet, which is how multi-line content is written out.
+func parseSyncMarkdown(data string) pulledFile {
+	var file pulledFile
+	var current *pulledItem
+	var pendingBlank int
+
+	flush := func() {
+		if current != nil {
+			finishPulledItem(current)
+			file.Items = append(file.Items, *current)
+			current = nil
+		}
+		pendingBlank = 0
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, manifestPrefix):
+			flush()
+			body := strings.TrimSuffix(strings.TrimPrefix(trimmed, manifestPrefix), "-->")
+			file.Manifest = strings.Fields(body)
+		case trimmed == "":
+			pendingBlank++
+		case strings.HasPrefix(trimmed, "#"), strings.HasPrefix(trimmed, ">"),
+			strings.HasPrefix(trimmed, "<!--"), strings.HasPrefix(trimmed, "_Last updated"),
+			trimmed == "No active context items.":
+			flush()
+		default:
+			if m := pullItemRegex.FindStringSubmatch(trimmed); m != nil && line == strings.TrimLeft(line, " \t") {
+				flush()
+				current = &pulledItem{
+					ID:      m[2],
+					Content: m[3],
+					Done:    m[1] == "x" || m[1] == "X",
+				}
+				continue
+			}
+			if current == nil {
+				continue
+			}
+			current.Content += strings.Repeat("\n", pendingBlank+1) + line
+			pendingBlank = 0
+		}
+	}
+	flush()
+
+	return file
+}
+
+// finishPulledItem splits the trailing metadata, tag list and strikethrough
+// markers off the raw content of a parsed bullet.
+func finishPulledItem(item *pulledItem) {
+	content := strings.TrimSpace(item.Content)
+	if loc := pullMetaRegex.FindStringIndex(content); loc != nil {
+		content = content[:loc[0]]
+	}
+	if m := pullTagsRegex.FindStringSubmatch(content); m != nil {
+		for _, tag := range strings.Split(m[1], ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "@")
+			if tag != "" {
+				item.Tags = append(item.Tags, tag)
+			}
+		}
+		content = strings.TrimSpace(content[:len(content)-len(m[0])])
+	}
+	if len(content) > 4 && strings.HasPrefix(content, "~~") && strings.HasSuffix(content, "~~") {
+		content = strings.TrimSpace(content[2 : len(content)-2])
+		item.Done = true
+	}
+	item.Content = content
+}
+
+// detectPullChanges compares the parsed sync files with the store and returns
+// the proposed changes. Problems with individual lines, such as IDs that no
+// longer exist, are reported as warnings to out and skipped.
+func detectPullChanges(stor storage.Storage, files []pulledFile, out io.Writer) []pullChange {
+	var changes []pullChange
+	seen := make(map[string]bool)  // Item IDs that already have a change
+	added := make(map[string]bool) // Content of new items already proposed
+	now := time.Now()
+
+	for _, file := range files {
+		present := make(map[string]bool)
+
+		for _, pulled := range file.Items {
+			if pulled.ID == "" {
+				if pulled.Content == "" || added[pulled.Content] {
+					continue
+				}
+				if err := utils.ValidateTags(pulled.Tags); err != nil {
+					fmt.Fprintf(out, "Warning: %s: skipping new item %q: %v\n", file.Path, pulled.Content, err)
+					continue
+				}
+				id, err := utils.GenerateUUID()
+				if err != nil {
+					fmt.Fprintf(out, "Warning: %s: skipping new item %q: %v\n", file.Path, pulled.Content, err)
+					continue
+				}
+				added[pulled.Content] = true
+				item := models.ContextItem{
+					ID:        id,
+					Content:   pulled.Content,
+					Project:   file.Project,
+					Tags:      pulled.Tags,
+					CreatedAt: now,
+				}
+				if pulled.Done {
+					item.SetState(models.StateDone, "", now)
+				}
+				changes = append(changes, pullChange{Kind: pullAdd, Item: item, Reason: "new line", Source: file.Path})
+				continue
+			}
+
+			present[pulled.ID] = true
+			item, err := stor.GetByPrefix(pulled.ID)
+			if err != nil {
+				if errors.Is(err, storage.ErrAmbiguousID) {
+					fmt.Fprintf(out, "Warning: %s: ID %s is ambiguous, skipping\n", file.Path, pulled.ID)
+				} else {
+					fmt.Fprintf(out, "Warning: %s: item %s not found, skipping\n", file.Path, pulled.ID)
+				}
+				continue
+			}
+			if seen[item.ID] {
+				continue
+			}
+
+			edited := false
+			if normalizeContent(pulled.Content) != normalizeContent(item.Content) || !equalTags(pulled.Tags, item.Tags) {
+				if err := utils.ValidateTags(pulled.Tags); err != nil {
+					fmt.Fprintf(out, "Warning: %s: skipping edit of %s: %v\n", file.Path, pulled.ID, err)
+				} else {
+					before := item.Content
+					item.Content = pulled.Content
+					item.Tags = pulled.Tags
+					changes = append(changes, pullChange{Kind: pullEdit, Item: item, Before: before, Reason: "line changed", Source: file.Path})
+					edited = true
+				}
+			}
+
+			if pulled.Done && item.CompletedAt == nil {
+				item.SetState(models.StateDone, "", now)
+				if edited {
+					// Fold the completion into the edit proposed above
+					changes[len(changes)-1].Item = item
+					changes[len(changes)-1].Reason = "line changed and checked off"
+				} else {
+					changes = append(changes, pullChange{Kind: pullDone, Item: item, Reason: "checked off", Source: file.Path})
+				}
+				seen[item.ID] = true
+			}
+			// Lines left alone do not hide changes to the item in other files,
+			// such as the file of its project
+			if edited {
+				seen[item.ID] = true
+			}
+		}
+
+		// Lines listed in the manifest but missing from the file were deleted
+		for _, id := range file.Manifest {
+			if present[id] {
+				continue
+			}
+			item, err := stor.GetByPrefix(id)
+			if err != nil || seen[item.ID] || item.CompletedAt != nil {
+				continue
+			}
+			item.SetState(models.StateDone, "", now)
+			changes = append(changes, pullChange{Kind: pullDone, Item: item, Reason: "line removed", Source: file.Path})
+			seen[item.ID] = true
+		}
+	}
+
+	return changes
+}
+
+// formatPullChange formats a proposed change for the confirmation preview.
+func formatPullChange(change pullChange) string {
+	id := "new"
+	if change.Kind != pullAdd {
+		id = shortID(change.Item.ID)
+	}
+	line := fmt.Sprintf("  %-4s [%s] %s (%s in %s)", change.Kind, id,
+		previewContent(change.Item.Content, 60), change.Reason, change.Source)
+	if change.Kind == pullEdit && normalizeContent(change.Before) != normalizeContent(change.Item.Content) {
+		line += fmt.Sprintf("\n         was: %s", previewContent(change.Before, 60))
+	}
+	return line
+}
+
+// previewContent flattens content to a single line and truncates it to
+// maxLen characters.
+func previewContent(content string, maxLen int) string {
+	flat := []rune(strings.Join(strings.Fields(content), " "))
+	if len(flat) > maxLen {
+		return string(flat[:maxLen-3]) + "..."
+	}
+	return string(flat)
+}
+
+// normalizeContent trims each line and drops blank lines so that whitespace
+// introduced by the Markdown layout is not reported as an edit.
+func normalizeContent(content string) string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// equalTags reports whether two tag lists contain the same tags in order.
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// confirm asks a yes/no question on the command's input and returns true
+// only for an explicit "y" or "yes".
+func confirm(cmd *cobra.Command, question string) bool {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s (y/N): ", question)
+	response, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestParseSyncMarkdown(t *testing.T) {
+	items := []models.ContextItem{
+		{ID: "aaaaaaaa-1111", Content: "First item", Tags: []string{"bug", "urgent"}},
+		{ID: "bbbbbbbb-2222", Content: "Second item\nwith a second line"},
+	}
+
+	file := parseSyncMarkdown(generateMarkdown(items, syncOptions{}))
+
+	if len(file.Items) != 2 {
+		t.Fatalf("Expected 2 parsed items, got %d: %+v", len(file.Items), file.Items)
+	}
+	if file.Items[0].ID != "aaaaaaaa" || file.Items[0].Content != "First item" {
+		t.Errorf("Unexpected first item: %+v", file.Items[0])
+	}
+	if !equalTags(file.Items[0].Tags, []string{"bug", "urgent"}) {
+		t.Errorf("Expected tags [bug urgent], got %v", file.Items[0].Tags)
+	}
+	if file.Items[1].Content != "Second item\nwith a second line" {
+		t.Errorf("Expected multi-line content to round-trip, got %q", file.Items[1].Content)
+	}
+	if strings.Join(file.Manifest, " ") != "aaaaaaaa bbbbbbbb" {
+		t.Errorf("Expected manifest of both IDs, got %v", file.Manifest)
+	}
+}
+
+func TestParseSyncMarkdownDoneMarkers(t *testing.T) {
+	data := "## Active Items\n\n" +
+		"- [x] [aaaaaaaa] Checked off\n" +
+		"- [bbbbbbbb] ~~Struck through~~ (@bug)\n" +
+		"- A brand new line\n"
+
+	file := parseSyncMarkdown(data)
+	if len(file.Items) != 3 {
+		t.Fatalf("Expected 3 parsed items, got %d", len(file.Items))
+	}
+	if !file.Items[0].Done || file.Items[0].Content != "Checked off" {
+		t.Errorf("Expected checked item to be done, got %+v", file.Items[0])
+	}
+	if !file.Items[1].Done || file.Items[1].Content != "Struck through" {
+		t.Errorf("Expected struck through item to be done, got %+v", file.Items[1])
+	}
+	if file.Items[2].ID != "" || file.Items[2].Content != "A brand new line" {
+		t.Errorf("Expected new item without ID, got %+v", file.Items[2])
+	}
+}
+
+func TestPreviewContent(t *testing.T) {
+	if got := previewContent("Short\n  note", 60); got != "Short note" {
+		t.Errorf("Expected flattened content, got %q", got)
+	}
+	got := previewContent(strings.Repeat("ž", 70), 60)
+	if got != strings.Repeat("ž", 57)+"..." || !utf8.ValidString(got) {
+		t.Errorf("Expected 57 characters and an ellipsis, got %q", got)
+	}
+}
+
+func TestSyncPull(t *testing.T) {
+	defer func() {
+		syncPullFlag = false
+		syncYesFlag = false
+	}()
+
+	setup := func(t *testing.T) (string, storage.Storage) {
+		tmpDir, storagePath := testStore(t)
+		os.MkdirAll(filepath.Join(tmpDir, ".claude", "rules"), 0755)
+
+		stor := storage.NewStorage(storagePath)
+		stor.Add(models.ContextItem{ID: "edit-me-12345", Content: "Original content", CreatedAt: time.Now()})
+		stor.Add(models.ContextItem{ID: "check-me-12345", Content: "Check me off", CreatedAt: time.Now()})
+		stor.Add(models.ContextItem{ID: "delete-me-12345", Content: "Delete my line", CreatedAt: time.Now()})
+		stor.Add(models.ContextItem{ID: "keep-me-12345", Content: "Leave me alone", CreatedAt: time.Now()})
+
+		oldWd, _ := os.Getwd()
+		os.Chdir(tmpDir)
+		t.Cleanup(func() { os.Chdir(oldWd) })
+
+		syncPullFlag = false
+		syncYesFlag = false
+		RootCmd.SetOut(new(bytes.Buffer))
+		RootCmd.SetArgs([]string{"sync"})
+		if err := RootCmd.Execute(); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+
+		// Simulate an agent editing the generated file
+		path := filepath.Join(".claude", "rules", "ck-context.md")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read sync file: %v", err)
+		}
+		edited := string(data)
+		edited = strings.Replace(edited, "Original content", "Edited content (@docs)", 1)
+		edited = strings.Replace(edited, "- [check-me] ", "- [x] [check-me] ", 1)
+		var lines []string
+		for _, line := range strings.Split(edited, "\n") {
+			if !strings.Contains(line, "Delete my line") {
+				lines = append(lines, line)
+			}
+		}
+		edited = strings.Join(lines, "\n")
+		edited = strings.Replace(edited, "\n<!--", "- Added by an agent\n\n<!--", 1)
+		os.WriteFile(path, []byte(edited), 0644)
+
+		return storagePath, storage.NewStorage(storagePath)
+	}
+
+	t.Run("applies changes with --yes", func(t *testing.T) {
+		_, stor := setup(t)
+
+		buf := new(bytes.Buffer)
+		RootCmd.SetOut(buf)
+		RootCmd.SetArgs([]string{"sync", "--pull", "--yes"})
+		if err := RootCmd.Execute(); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if !strings.Contains(buf.String(), "Applied 4 changes") {
+			t.Fatalf("Expected 4 applied changes, got: %s", buf.String())
+		}
+
+		stor.Load()
+		edited, _ := stor.GetByID("edit-me-12345")
+		if edited.Content != "Edited content" || !equalTags(edited.Tags, []string{"docs"}) {
+			t.Errorf("Expected edited content and tags, got %+v", edited)
+		}
+		for _, id := range []string{"check-me-12345", "delete-me-12345"} {
+			if item, _ := stor.GetByID(id); item.CompletedAt == nil {
+				t.Errorf("Expected %s to be completed", id)
+			}
+		}
+		if item, _ := stor.GetByID("keep-me-12345"); item.CompletedAt != nil || item.Content != "Leave me alone" {
+			t.Errorf("Expected untouched item to stay unchanged, got %+v", item)
+		}
+
+		found := false
+		for _, item := range stor.GetAll() {
+			if item.Content == "Added by an agent" {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected new item to be added")
+		}
+	})
+
+	t.Run("declining confirmation leaves the store untouched", func(t *testing.T) {
+		storagePath, stor := setup(t)
+		before, _ := os.ReadFile(storagePath)
+
+		buf := new(bytes.Buffer)
+		RootCmd.SetOut(buf)
+		RootCmd.SetIn(strings.NewReader("n\n"))
+		defer RootCmd.SetIn(nil)
+		RootCmd.SetArgs([]string{"sync", "--pull"})
+		if err := RootCmd.Execute(); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if !strings.Contains(buf.String(), "Cancelled.") {
+			t.Errorf("Expected cancellation, got: %s", buf.String())
+		}
+
+		after, _ := os.ReadFile(storagePath)
+		if !bytes.Equal(before, after) {
+			t.Error("Expected store to be unchanged after declining")
+		}
+		stor.Load()
+		if len(stor.GetAll()) != 4 {
+			t.Errorf("Expected 4 items, got %d", len(stor.GetAll()))
+		}
+	})
+}
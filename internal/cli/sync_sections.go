@@ -0,0 +1,188 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/query"
+)
+
+// Grouping modes for sync output.
+const (
+	groupNone    = "none"
+	groupProject = "project"
+	groupTag     = "tag"
+	groupKind    = "kind"
+)
+
+// Section names for items that don't fit any other section.
+const (
+	sectionNoProject = "No project"
+	sectionUntagged  = "Untagged"
+	sectionOther     = "Other"
+)
+
+// syncOptions controls the layout of generated sync files.
+type syncOptions struct {
+	GroupBy      string                     // One of the group* constants, empty for a flat list
+	Order        []string                   // Section names to list first
+	Limit        int                        // Maximum items per section, 0 for no limit
+	Kinds        []string                   // Tags that define an item's kind
+	HideMetadata bool                       // Omit the compact metadata suffix
+	Filter       query.Node                 // Selects the items to sync, nil for all active items
+	Progress     map[string]models.Progress // Subtask rollups, keyed by parent ID
+	BlockedBy    map[string][]string        // Open items blocking each item, keyed by ID
+}
+
+// syncSection is a named group of items in a sync file.
+type syncSection struct {
+	Name  string
+	Items []models.ContextItem
+}
+
+// loadSyncOptions reads the sync layout from the store configuration and
+// applies the --group-by, --limit and --query overrides.
+func loadSyncOptions() (syncOptions, error) {
+	cfg, err := config.LoadStoreConfig(config.FindStoragePath(pathFlag))
+	if err != nil {
+		return syncOptions{}, err
+	}
+
+	opts := syncOptions{
+		GroupBy:      cfg.Sync.GroupBy,
+		Order:        cfg.Sync.Order,
+		Limit:        cfg.Sync.Limit,
+		Kinds:        cfg.SyncKinds(),
+		HideMetadata: cfg.Sync.HideMetadata,
+	}
+	if syncGroupByFlag != "" {
+		opts.GroupBy = syncGroupByFlag
+	}
+	if syncLimitFlag > 0 {
+		opts.Limit = syncLimitFlag
+	}
+
+	filter := cfg.Sync.Filter
+	if syncQueryFlag != "" {
+		filter = syncQueryFlag
+	}
+	if opts.Filter, err = query.ParseWithViews(filter, viewsOf(cfg)); err != nil {
+		return syncOptions{}, fmt.Errorf("invalid sync filter: %w", err)
+	}
+
+	switch opts.GroupBy {
+	case "", groupNone, groupProject, groupTag, groupKind:
+	default:
+		return syncOptions{}, fmt.Errorf("invalid sync grouping %q: must be project, tag, kind or none", opts.GroupBy)
+	}
+	return opts, nil
+}
+
+// groupSyncItems splits items into sections according to opts.GroupBy.
+//
+// Without grouping a single unnamed section is returned. Sections named in
+// opts.Order come first, followed by the rest alphabetically and then the
+// catch-all section. Within each section pinned items come first, then items
+// in progress; otherwise the original item order is kept.
+func groupSyncItems(items []models.ContextItem, opts syncOptions) []syncSection {
+	if opts.GroupBy == "" || opts.GroupBy == groupNone {
+		return []syncSection{{Items: attentionFirst(items)}}
+	}
+
+	byName := make(map[string][]models.ContextItem)
+	var names []string
+	for _, item := range items {
+		name := sectionName(item, opts)
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], item)
+	}
+
+	rank := make(map[string]int, len(opts.Order))
+	for i, name := range opts.Order {
+		rank[name] = i
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		ri, iOrdered := rank[names[i]]
+		rj, jOrdered := rank[names[j]]
+		switch {
+		case iOrdered && jOrdered:
+			return ri < rj
+		case iOrdered != jOrdered:
+			return iOrdered
+		}
+		iCatchAll, jCatchAll := isCatchAllSection(names[i]), isCatchAllSection(names[j])
+		if iCatchAll != jCatchAll {
+			return jCatchAll
+		}
+		return names[i] < names[j]
+	})
+
+	sections := make([]syncSection, 0, len(names))
+	for _, name := range names {
+		sections = append(sections, syncSection{Name: name, Items: attentionFirst(byName[name])})
+	}
+	return sections
+}
+
+// sectionName returns the section an item belongs to.
+func sectionName(item models.ContextItem, opts syncOptions) string {
+	switch opts.GroupBy {
+	case groupProject:
+		if item.Project != "" {
+			return item.Project
+		}
+		return sectionNoProject
+	case groupTag:
+		// Prefer a tag that has an explicit position in the ordering
+		for _, name := range opts.Order {
+			if hasTag(item, name) {
+				return name
+			}
+		}
+		if len(item.Tags) > 0 {
+			return item.Tags[0]
+		}
+		return sectionUntagged
+	default:
+		for _, kind := range opts.Kinds {
+			if hasTag(item, kind) {
+				return kind
+			}
+		}
+		return sectionOther
+	}
+}
+
+// isCatchAllSection reports whether name is one of the sections for items
+// without a project, tag or kind.
+func isCatchAllSection(name string) bool {
+	return name == sectionNoProject || name == sectionUntagged || name == sectionOther
+}
+
+// pinnedFirst returns a copy of items with pinned items moved to the front.
+func attentionFirst(items []models.ContextItem) []models.ContextItem {
+	sorted := make([]models.ContextItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Pinned && !sorted[j].Pinned
+	})
+	return sorted
+}
+
+// hasTag reports whether item carries the given tag.
+func hasTag(item models.ContextItem, tag string) bool {
+	for _, t := range item.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
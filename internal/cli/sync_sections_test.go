@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+func TestGroupSyncItems(t *testing.T) {
+	items := []models.ContextItem{
+		{ID: "1", Content: "api bug", Project: "api", Tags: []string{"bug"}},
+		{ID: "2", Content: "web idea", Project: "web", Tags: []string{"idea"}},
+		{ID: "3", Content: "loose note"},
+		{ID: "4", Content: "pinned api decision", Project: "api", Tags: []string{"decision"}, Pinned: true},
+	}
+
+	names := func(sections []syncSection) string {
+		var out []string
+		for _, s := range sections {
+			out = append(out, s.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	t.Run("flat list keeps pinned items first", func(t *testing.T) {
+		sections := groupSyncItems(items, syncOptions{})
+		if len(sections) != 1 || sections[0].Name != "" {
+			t.Fatalf("Expected one unnamed section, got %q", names(sections))
+		}
+		if sections[0].Items[0].ID != "4" {
+			t.Errorf("Expected pinned item first, got %s", sections[0].Items[0].ID)
+		}
+	})
+
+	t.Run("by project with catch-all last", func(t *testing.T) {
+		sections := groupSyncItems(items, syncOptions{GroupBy: groupProject})
+		if got := names(sections); got != "api,web,No project" {
+			t.Errorf("Unexpected sections: %s", got)
+		}
+		if sections[0].Items[0].ID != "4" {
+			t.Errorf("Expected pinned item first in api section, got %s", sections[0].Items[0].ID)
+		}
+	})
+
+	t.Run("by kind with configured order", func(t *testing.T) {
+		opts := syncOptions{GroupBy: groupKind, Kinds: []string{"decision", "bug", "idea"}, Order: []string{"idea"}}
+		if got := names(groupSyncItems(items, opts)); got != "idea,bug,decision,Other" {
+			t.Errorf("Unexpected sections: %s", got)
+		}
+	})
+
+	t.Run("by tag", func(t *testing.T) {
+		if got := names(groupSyncItems(items, syncOptions{GroupBy: groupTag})); got != "bug,decision,idea,Untagged" {
+			t.Errorf("Unexpected sections: %s", got)
+		}
+	})
+}
+
+func TestGenerateMarkdownSections(t *testing.T) {
+	created := time.Now().Add(-72 * time.Hour)
+	items := []models.ContextItem{
+		{ID: "aaaaaaaa-1", Content: "one", Project: "api", CreatedAt: created},
+		{ID: "bbbbbbbb-2", Content: "two", Project: "api", CreatedAt: created},
+		{ID: "cccccccc-3", Content: "three", Project: "web", CreatedAt: created, Pinned: true},
+	}
+
+	out := generateMarkdown(items, syncOptions{GroupBy: groupProject, Limit: 1})
+
+	for _, want := range []string{"### api", "### web", "- [aaaaaaaa] one _(3d)_", "- [cccccccc] three _(3d, pinned)_", "> 1 more not shown."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "bbbbbbbb] two") {
+		t.Errorf("Expected item over the section limit to be omitted, got:\n%s", out)
+	}
+
+	// Only written items are recorded, so pull does not treat the rest as deleted
+	file := parseSyncMarkdown(out)
+	if strings.Join(file.Manifest, " ") != "aaaaaaaa cccccccc" {
+		t.Errorf("Unexpected manifest: %v", file.Manifest)
+	}
+	for _, item := range file.Items {
+		if strings.Contains(item.Content, "_(") {
+			t.Errorf("Expected metadata to be stripped when parsing, got %q", item.Content)
+		}
+	}
+
+	if out := generateMarkdown(items, syncOptions{HideMetadata: true}); strings.Contains(out, "_(3d") {
+		t.Errorf("Expected metadata to be hidden, got:\n%s", out)
+	}
+}
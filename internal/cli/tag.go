@@ -0,0 +1,123 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// tagCmd adds tags to and removes tags from context items.
+var tagCmd = &cobra.Command{
+	Use:   "tag [id...] --add <tags> --remove <tags>",
+	Short: "Add or remove tags of context items",
+	Long:  "Add tags to context items and remove tags from them. Items that already have the tags, or lack the ones to remove, are left alone.\n\n" + bulkHelp + "\n\n" + itemRefHelp,
+	Example: `  # Tag an item
+  ck tag 12 --add urgent
+
+  # Move the open items of a sprint to the next one
+  ck tag --tags sprint-12 --add sprint-13 --remove sprint-12`,
+	Args: cobra.ArbitraryArgs,
+	RunE: tagCommand,
+}
+
+// Command flags for the tag command.
+var (
+	// tagAddFlag lists the tags to add
+	tagAddFlag string
+	// tagRemoveFlag lists the tags to remove
+	tagRemoveFlag string
+	// tagBulk selects the items to tag
+	tagBulk bulkFlags
+)
+
+// tagCommand is the execution function for the tag command.
+func tagCommand(cmd *cobra.Command, args []string) error {
+	add, err := parseTagInput(tagAddFlag)
+	if err != nil {
+		return err
+	}
+	remove, err := parseTagInput(tagRemoveFlag)
+	if err != nil {
+		return err
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return fmt.Errorf("no tags given: use --add or --remove")
+	}
+	if err := utils.ValidateTags(append(append([]string{}, add...), remove...)); err != nil {
+		return err
+	}
+
+	stor := storage.NewStorage(config.FindStoragePath(pathFlag))
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	items, err := tagBulk.selectItems(cmd, stor, args)
+	if err != nil {
+		return err
+	}
+
+	var changed []models.ContextItem
+	for _, item := range items {
+		if tags, ok := retag(item.Tags, add, remove); ok {
+			item.Tags = tags
+			changed = append(changed, item)
+		}
+	}
+	_, err = tagBulk.run(cmd, stor, changed, bulkAction{
+		name:     "tag",
+		question: "Change the tags of %d items?",
+		result:   "Changed the tags of %d items",
+		apply:    stor.Update,
+	})
+	return err
+}
+
+// retag returns tags without the tags in remove and with the tags in add
+// that it lacks, appended in order, and whether that changed anything.
+func retag(tags, add, remove []string) ([]string, bool) {
+	drop := make(map[string]bool, len(remove))
+	for _, tag := range remove {
+		drop[tag] = true
+	}
+	result := make([]string, 0, len(tags)+len(add))
+	have := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if !drop[tag] {
+			result = append(result, tag)
+			have[tag] = true
+		}
+	}
+	for _, tag := range add {
+		if !have[tag] {
+			result = append(result, tag)
+			have[tag] = true
+		}
+	}
+
+	if len(result) != len(tags) {
+		return result, true
+	}
+	for i := range tags {
+		if tags[i] != result[i] {
+			return result, true
+		}
+	}
+	return tags, false
+}
+
+// init registers the tag command with the root command.
+func init() {
+	tagCmd.Flags().StringVar(&tagAddFlag, "add", "", "Tags to add (comma or space separated)")
+	tagCmd.Flags().StringVar(&tagRemoveFlag, "remove", "", "Tags to remove (comma or space separated)")
+	tagCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	tagBulk.register(tagCmd)
+	RootCmd.AddCommand(tagCmd)
+}
@@ -0,0 +1,466 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+// tagsCmd lists the tags in use.
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List tags and how many items use them",
+	Long: `List the tags in use, most used first, with the number of items using
+them and how many of those are open, followed by the tag aliases.
+
+Clean tags up with 'ck tag rename', 'ck tag merge' and 'ck tag delete', and
+add aliases with 'ck tag alias' so that tags given on the command line are
+spelled the same way: with the alias bugs for bug, 'ck add --tags bugs'
+tags the item bug and 'ck list --tags bugs' lists the items tagged bug.`,
+	Example: `  ck tags
+  ck tag rename bugs bug --alias
+  ck tag merge ui frontend-ui frontend
+  ck tag delete wip
+  ck tag alias fe frontend`,
+	Args: cobra.NoArgs,
+	RunE: runTags,
+}
+
+// tagRenameCmd renames a tag on all items.
+var tagRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag on all items",
+	Long:  "Rename a tag on all items, including closed ones. Aliases of the old tag are moved to the new one, and --alias makes the old tag an alias too. To rename a tag to one already in use, merge them instead.",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runTagRename,
+}
+
+// tagMergeCmd merges tags into another one.
+var tagMergeCmd = &cobra.Command{
+	Use:   "merge <tag>... <into>",
+	Short: "Merge tags into another tag",
+	Long:  "Replace the given tags with the last one on all items, including closed ones. Aliases of the merged tags are moved to it, and --alias makes the merged tags aliases too.",
+	Example: `  # Tag everything tagged ui or frontend-ui as frontend instead
+  ck tag merge ui frontend-ui frontend`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runTagMerge,
+}
+
+// tagDeleteCmd removes tags from all items.
+var tagDeleteCmd = &cobra.Command{
+	Use:     "delete <tag>...",
+	Aliases: []string{"rm"},
+	Short:   "Remove tags from all items",
+	Long:    "Remove tags from all items, including closed ones, after confirmation. Aliases of the tags are deleted too.",
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    runTagDelete,
+}
+
+// tagAliasCmd adds a tag alias.
+var tagAliasCmd = &cobra.Command{
+	Use:   "alias <alias> <tag>",
+	Short: "Make a tag an alias of another",
+	Long: `Make a tag an alias of another: tags given on the command line, to add,
+edit, filter or select items, are replaced by the tags they are aliases of.
+Aliases are stored in .contextkeeper/config.json and shared with the team
+through git. Items already tagged with the alias keep it; merge the tags to
+retag them.`,
+	Example: `  ck tag alias bugs bug
+  ck tag merge bugs bug`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTagAlias,
+}
+
+// tagUnaliasCmd removes a tag alias.
+var tagUnaliasCmd = &cobra.Command{
+	Use:   "unalias <alias>",
+	Short: "Remove a tag alias",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTagUnalias,
+}
+
+// Command flags for the tag management commands.
+var (
+	tagKeepAliasFlag bool // --alias: Keep renamed or merged tags as aliases
+	tagDeleteYesFlag bool // --yes: Delete without confirmation
+)
+
+// parseTagInput parses tags given on the command line, replacing the tag
+// aliases of the current store.
+func parseTagInput(tagStr string) ([]string, error) {
+	cfg, err := config.LoadStoreConfig(config.FindStoragePath(pathFlag))
+	if err != nil {
+		return nil, err
+	}
+	return utils.ParseTagsWithAliases(tagStr, cfg.TagAliases), nil
+}
+
+// tagUsage is the number of items using a tag.
+type tagUsage struct {
+	Tag   string `json:"tag"`
+	Items int    `json:"items"`
+	Open  int    `json:"open"`
+}
+
+// countTags returns the usage of every tag of items, most used first.
+func countTags(items []models.ContextItem) []tagUsage {
+	byTag := make(map[string]*tagUsage)
+	var usage []*tagUsage
+	for _, item := range items {
+		for _, tag := range item.Tags {
+			u, ok := byTag[tag]
+			if !ok {
+				u = &tagUsage{Tag: tag}
+				byTag[tag] = u
+				usage = append(usage, u)
+			}
+			u.Items++
+			if item.CompletedAt == nil {
+				u.Open++
+			}
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Items != usage[j].Items {
+			return usage[i].Items > usage[j].Items
+		}
+		return usage[i].Tag < usage[j].Tag
+	})
+	result := make([]tagUsage, len(usage))
+	for i, u := range usage {
+		result[i] = *u
+	}
+	return result
+}
+
+// runTags is the execution function for the tags command.
+func runTags(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+	usage := countTags(stor.GetAll())
+
+	if jsonOutput {
+		result := struct {
+			Tags    []tagUsage        `json:"tags"`
+			Aliases map[string]string `json:"aliases"`
+		}{usage, cfg.TagAliases}
+		if result.Aliases == nil {
+			result.Aliases = map[string]string{}
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal tags to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	if len(usage) == 0 {
+		cmd.Println("No tags in use.")
+	}
+	width := 0
+	for _, u := range usage {
+		width = max(width, len(u.Tag))
+	}
+	for _, u := range usage {
+		cmd.Printf("%-*s  %d items, %d open\n", width, u.Tag, u.Items, u.Open)
+	}
+
+	if len(cfg.TagAliases) > 0 {
+		aliases := make([]string, 0, len(cfg.TagAliases))
+		for alias := range cfg.TagAliases {
+			aliases = append(aliases, alias)
+		}
+		sort.Strings(aliases)
+		cmd.Println("\nAliases:")
+		for _, alias := range aliases {
+			cmd.Printf("  %s -> %s\n", alias, cfg.TagAliases[alias])
+		}
+	}
+	return nil
+}
+
+// runTagRename is the execution function for the tag rename command.
+func runTagRename(cmd *cobra.Command, args []string) error {
+	old, tag := args[0], args[1]
+	if old == tag {
+		return fmt.Errorf("the old and new tag are the same")
+	}
+	return replaceTagEverywhere(cmd, []string{old}, tag, func(inUse bool) error {
+		if inUse {
+			return fmt.Errorf("tag %q is already in use: merge the tags with 'ck tag merge %s %s'", tag, old, tag)
+		}
+		return nil
+	})
+}
+
+// runTagMerge is the execution function for the tag merge command.
+func runTagMerge(cmd *cobra.Command, args []string) error {
+	into := args[len(args)-1]
+	var tags []string
+	for _, tag := range args[:len(args)-1] {
+		if tag != into {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return fmt.Errorf("no tags to merge into %q", into)
+	}
+	return replaceTagEverywhere(cmd, tags, into, nil)
+}
+
+// replaceTagEverywhere replaces tags with tag on all items and moves their
+// aliases to it. check, if set, is called with whether tag is in use
+// before anything changes.
+func replaceTagEverywhere(cmd *cobra.Command, tags []string, tag string, check func(inUse bool) error) error {
+	if err := utils.ValidateTags([]string{tag}); err != nil {
+		return err
+	}
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	for _, u := range countTags(stor.GetAll()) {
+		used[u.Tag] = true
+	}
+	if check != nil {
+		if err := check(used[tag]); err != nil {
+			return err
+		}
+	}
+	from := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		if !used[t] && !tagKeepAliasFlag {
+			return fmt.Errorf("no items are tagged %q", t)
+		}
+		from[t] = true
+	}
+
+	changed, err := retagAll(stor, from, tag)
+	if err != nil {
+		return err
+	}
+
+	// Aliases follow the tags they stand for
+	aliasesChanged := false
+	for alias, target := range cfg.TagAliases {
+		if from[target] {
+			cfg.TagAliases[alias] = tag
+			aliasesChanged = true
+		}
+	}
+	if tagKeepAliasFlag {
+		if cfg.TagAliases == nil {
+			cfg.TagAliases = make(map[string]string)
+		}
+		for _, t := range tags {
+			cfg.TagAliases[t] = tag
+		}
+		aliasesChanged = true
+	}
+	if aliasesChanged {
+		if err := config.SaveStoreConfig(storagePath, cfg); err != nil {
+			return err
+		}
+	}
+
+	cmd.Printf("Replaced tag %s with %s on %d items\n", strings.Join(tags, ", "), tag, changed)
+	if tagKeepAliasFlag {
+		cmd.Printf("Kept %s as aliases of %s\n", strings.Join(tags, ", "), tag)
+	}
+	return nil
+}
+
+// runTagDelete is the execution function for the tag delete command.
+func runTagDelete(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+
+	from := make(map[string]bool, len(args))
+	for _, tag := range args {
+		from[tag] = true
+	}
+	count := 0
+	for _, item := range stor.GetAll() {
+		if _, ok := replaceTags(item.Tags, from, ""); ok {
+			count++
+		}
+	}
+	if count == 0 {
+		return fmt.Errorf("no items are tagged %s", strings.Join(args, ", "))
+	}
+	if !tagDeleteYesFlag && !confirm(cmd, fmt.Sprintf("Remove tag %s from %d items?", strings.Join(args, ", "), count)) {
+		cmd.Println("Cancelled.")
+		return nil
+	}
+
+	changed, err := retagAll(stor, from, "")
+	if err != nil {
+		return err
+	}
+	aliasesChanged := false
+	for alias, target := range cfg.TagAliases {
+		if from[target] {
+			delete(cfg.TagAliases, alias)
+			aliasesChanged = true
+		}
+	}
+	if aliasesChanged {
+		if err := config.SaveStoreConfig(storagePath, cfg); err != nil {
+			return err
+		}
+	}
+	cmd.Printf("Removed tag %s from %d items\n", strings.Join(args, ", "), changed)
+	return nil
+}
+
+// runTagAlias is the execution function for the tag alias command.
+func runTagAlias(cmd *cobra.Command, args []string) error {
+	alias, tag := args[0], args[1]
+	if err := utils.ValidateTags([]string{alias, tag}); err != nil {
+		return err
+	}
+	if alias == tag {
+		return fmt.Errorf("a tag cannot be an alias of itself")
+	}
+	storagePath := config.FindStoragePath(pathFlag)
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+	if target, ok := cfg.TagAliases[tag]; ok {
+		return fmt.Errorf("%q is itself an alias of %q", tag, target)
+	}
+
+	if cfg.TagAliases == nil {
+		cfg.TagAliases = make(map[string]string)
+	}
+	// Aliases of the alias now stand for the tag
+	for a, target := range cfg.TagAliases {
+		if target == alias {
+			cfg.TagAliases[a] = tag
+		}
+	}
+	cfg.TagAliases[alias] = tag
+	if err := config.SaveStoreConfig(storagePath, cfg); err != nil {
+		return err
+	}
+	cmd.Printf("Added alias %s for %s\n", alias, tag)
+
+	stor := storage.NewStorage(storagePath)
+	if err := stor.Load(); err != nil {
+		return fmt.Errorf("failed to load storage: %w", err)
+	}
+	for _, u := range countTags(stor.GetAll()) {
+		if u.Tag == alias {
+			cmd.Printf("%d items are tagged %s; retag them with 'ck tag merge %s %s'\n", u.Items, alias, alias, tag)
+		}
+	}
+	return nil
+}
+
+// runTagUnalias is the execution function for the tag unalias command.
+func runTagUnalias(cmd *cobra.Command, args []string) error {
+	storagePath := config.FindStoragePath(pathFlag)
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+	tag, ok := cfg.TagAliases[args[0]]
+	if !ok {
+		return fmt.Errorf("no tag alias %q", args[0])
+	}
+	delete(cfg.TagAliases, args[0])
+	if err := config.SaveStoreConfig(storagePath, cfg); err != nil {
+		return err
+	}
+	cmd.Printf("Removed alias %s for %s\n", args[0], tag)
+	return nil
+}
+
+// retagAll replaces the tags in from with to on all items, see
+// replaceTags, and returns the number of items changed.
+func retagAll(stor storage.Storage, from map[string]bool, to string) (int, error) {
+	changed := 0
+	err := stor.Batch(func() error {
+		for _, item := range stor.GetAll() {
+			tags, ok := replaceTags(item.Tags, from, to)
+			if !ok {
+				continue
+			}
+			item.Tags = tags
+			if err := stor.Update(item); err != nil {
+				return fmt.Errorf("failed to update item %s: %w", shortID(item.ID), err)
+			}
+			changed++
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// replaceTags replaces the tags in from with to, once, where the first of
+// them was, and reports whether that changed anything. An empty to removes
+// the tags.
+func replaceTags(tags []string, from map[string]bool, to string) ([]string, bool) {
+	result := make([]string, 0, len(tags))
+	changed, placed := false, false
+	for _, tag := range tags {
+		if from[tag] || tag == to {
+			changed = changed || from[tag]
+			if to != "" && !placed {
+				result = append(result, to)
+				placed = true
+			}
+			continue
+		}
+		result = append(result, tag)
+	}
+	if !changed {
+		return tags, false
+	}
+	return result, true
+}
+
+// init registers the tag management commands.
+func init() {
+	tagsCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	tagRenameCmd.Flags().BoolVar(&tagKeepAliasFlag, "alias", false, "Keep the old tag as an alias of the new one")
+	tagMergeCmd.Flags().BoolVar(&tagKeepAliasFlag, "alias", false, "Keep the merged tags as aliases")
+	tagDeleteCmd.Flags().BoolVarP(&tagDeleteYesFlag, "yes", "y", false, "Remove the tags without asking for confirmation")
+
+	tagCmd.AddCommand(tagRenameCmd, tagMergeCmd, tagDeleteCmd, tagAliasCmd, tagUnaliasCmd)
+	RootCmd.AddCommand(tagsCmd)
+}
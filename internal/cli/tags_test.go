@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/storage"
+)
+
+func TestTagCommands(t *testing.T) {
+	defer RootCmd.SetIn(nil)
+
+	_, storagePath := testStore(t)
+
+	created := time.Date(2025, 12, 1, 9, 0, 0, 0, time.UTC)
+	closed := created.Add(time.Hour)
+	stor := storage.NewStorage(storagePath)
+	for _, item := range []models.ContextItem{
+		{ID: "aaaa1111-item", Content: "Rate limit the API", Tags: []string{"bugs", "api"}, CreatedAt: created},
+		{ID: "bbbb2222-item", Content: "Fix the login redirect", Tags: []string{"bug", "ui", "bugs"}, CreatedAt: created},
+		{ID: "cccc3333-item", Content: "Restyle the header", Tags: []string{"frontend-ui"}, CreatedAt: created},
+		{ID: "dddd4444-item", Content: "Old decision", Tags: []string{"wip", "api"}, CreatedAt: created, CompletedAt: &closed},
+	} {
+		if err := stor.Add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tagsOf := func(t *testing.T, id string) []string {
+		t.Helper()
+		stor := storage.NewStorage(storagePath)
+		if err := stor.Load(); err != nil {
+			t.Fatal(err)
+		}
+		item, err := stor.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return item.Tags
+	}
+	aliases := func(t *testing.T) map[string]string {
+		t.Helper()
+		cfg, err := config.LoadStoreConfig(storagePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg.TagAliases
+	}
+
+	t.Run("tags lists usage", func(t *testing.T) {
+		out := mustExecute(t, "", "tags")
+		if !strings.Contains(out, "api          2 items, 1 open") || !strings.Contains(out, "bugs         2 items, 2 open") ||
+			strings.Index(out, "api") > strings.Index(out, "bug ") {
+			t.Errorf("Unexpected tags:\n%s", out)
+		}
+	})
+
+	t.Run("rename to a tag in use suggests merge", func(t *testing.T) {
+		_, err := runInput(t, "", "tag", "rename", "bugs", "bug")
+		if err == nil || !strings.Contains(err.Error(), "ck tag merge bugs bug") {
+			t.Errorf("Expected a merge suggestion, got %v", err)
+		}
+	})
+
+	t.Run("merge keeps one tag and an alias", func(t *testing.T) {
+		out := mustExecute(t, "", "tag", "merge", "bugs", "bug", "--alias")
+		if !strings.Contains(out, "Replaced tag bugs with bug on 2 items") {
+			t.Errorf("Unexpected output:\n%s", out)
+		}
+		if got := tagsOf(t, "aaaa1111-item"); !reflect.DeepEqual(got, []string{"bug", "api"}) {
+			t.Errorf("Expected [bug api], got %v", got)
+		}
+		if got := tagsOf(t, "bbbb2222-item"); !reflect.DeepEqual(got, []string{"bug", "ui"}) {
+			t.Errorf("Expected [bug ui], got %v", got)
+		}
+		if got := aliases(t); got["bugs"] != "bug" {
+			t.Errorf("Expected the alias bugs for bug, got %v", got)
+		}
+	})
+
+	t.Run("aliases apply to input", func(t *testing.T) {
+		mustExecute(t, "", "add", "Flaky test", "--tags", "bugs")
+		out := mustExecute(t, "", "list", "--tags", "bugs", "--json")
+		var items []listItemJSON
+		if err := json.Unmarshal([]byte(out), &items); err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 3 {
+			t.Fatalf("Expected 3 items tagged bug, got %d", len(items))
+		}
+		for _, item := range items {
+			for _, tag := range item.Tags {
+				if tag == "bugs" {
+					t.Errorf("Alias stored on item %s: %v", item.ID, item.Tags)
+				}
+			}
+		}
+	})
+
+	t.Run("rename moves aliases", func(t *testing.T) {
+		mustExecute(t, "", "tag", "rename", "bug", "defect")
+		if got := aliases(t); got["bugs"] != "defect" {
+			t.Errorf("Expected the alias bugs for defect, got %v", got)
+		}
+		if got := tagsOf(t, "aaaa1111-item"); !reflect.DeepEqual(got, []string{"defect", "api"}) {
+			t.Errorf("Expected [defect api], got %v", got)
+		}
+	})
+
+	t.Run("alias and unalias", func(t *testing.T) {
+		out := mustExecute(t, "", "tag", "alias", "fe", "frontend-ui")
+		if !strings.Contains(out, "Added alias fe for frontend-ui") {
+			t.Errorf("Unexpected output:\n%s", out)
+		}
+		if _, err := runInput(t, "", "tag", "alias", "x", "fe"); err == nil {
+			t.Error("Expected an error for an alias of an alias")
+		}
+		mustExecute(t, "", "tag", "unalias", "fe")
+		if _, ok := aliases(t)["fe"]; ok {
+			t.Error("Expected the alias to be removed")
+		}
+		if _, err := runInput(t, "", "tag", "unalias", "fe"); err == nil {
+			t.Error("Expected an error for an unknown alias")
+		}
+	})
+
+	t.Run("delete asks for confirmation", func(t *testing.T) {
+		out := mustExecute(t, "n\n", "tag", "delete", "wip")
+		if !strings.Contains(out, "Remove tag wip from 1 items?") || !strings.Contains(out, "Cancelled.") {
+			t.Errorf("Unexpected output:\n%s", out)
+		}
+		mustExecute(t, "", "tag", "delete", "wip", "--yes")
+		if got := tagsOf(t, "dddd4444-item"); !reflect.DeepEqual(got, []string{"api"}) {
+			t.Errorf("Expected [api], got %v", got)
+		}
+		if _, err := runInput(t, "", "tag", "delete", "wip", "--yes"); err == nil {
+			t.Error("Expected an error for an unused tag")
+		}
+	})
+
+	t.Run("tag items still works", func(t *testing.T) {
+		mustExecute(t, "", "tag", "cccc3333", "--add", "bugs")
+		if got := tagsOf(t, "cccc3333-item"); !reflect.DeepEqual(got, []string{"frontend-ui", "defect"}) {
+			t.Errorf("Expected [frontend-ui defect], got %v", got)
+		}
+	})
+}
+
+func TestReplaceTags(t *testing.T) {
+	from := map[string]bool{"a": true, "b": true}
+	for _, tt := range []struct {
+		tags    []string
+		to      string
+		want    []string
+		changed bool
+	}{
+		{[]string{"x", "a", "y", "b"}, "c", []string{"x", "c", "y"}, true},
+		{[]string{"c", "a"}, "c", []string{"c"}, true},
+		{[]string{"x", "a"}, "", []string{"x"}, true},
+		{[]string{"x", "c"}, "c", []string{"x", "c"}, false},
+	} {
+		got, changed := replaceTags(tt.tags, from, tt.to)
+		if !reflect.DeepEqual(got, tt.want) || changed != tt.changed {
+			t.Errorf("replaceTags(%v, %q) = %v, %v, want %v, %v", tt.tags, tt.to, got, changed, tt.want, tt.changed)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Package cli provides the command-line interface for ContextKeeper.
+//
+// This package implements the Cobra-based CLI for managing context and
+// configuration. See the root.go file for the main command structure.
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+	"github.com/ondrahracek/contextkeeper/internal/query"
+	"github.com/spf13/cobra"
+)
+
+// viewCmd groups the commands that manage saved views.
+//
+// A view is a named query stored in the store configuration (config.json),
+// so it is shared with the team through git. Views are referred to as @name
+// anywhere a query is accepted.
+var viewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Manage saved views",
+	Long: `Manage saved views: named queries stored in .contextkeeper/config.json and
+shared with the team through git.
+
+Refer to a view as @name anywhere a query is accepted: 'ck list @triage',
+'ck search @triage auth', 'ck sync --query @triage' or the sync.filter
+setting. Views can be combined with other terms and with each other.
+
+Views apply to list, search and sync only; the MCP server's tools do not
+take queries.`,
+	Example: `  # Save a view
+  ck view save triage --project api --tags bug
+
+  # Save a view from a query, including completed items
+  ck view save shipped 'tag:feature created:>=2026-01-01' --all
+
+  # Use it
+  ck list @triage
+  ck search @triage auth
+
+  # List and delete views
+  ck view list
+  ck view delete triage`,
+}
+
+// viewSaveCmd saves or replaces a view.
+var viewSaveCmd = &cobra.Command{
+	Use:   "save <name> [query]",
+	Short: "Save a view",
+	Long: `Save a query as a named view, replacing any existing view with that name.
+
+The view's query is built from the optional query arguments and the
+--project, --tags and --all flags, which have the same meaning as for
+'ck list'.`,
+	Example: `  ck view save triage --project api --tags bug,urgent
+  ck view save stale 'created:<2026-01-01 -tag:pinned'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runViewSave,
+}
+
+// viewListCmd lists saved views.
+var viewListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List saved views",
+	Args:    cobra.NoArgs,
+	RunE:    runViewList,
+}
+
+// viewDeleteCmd deletes a saved view.
+var viewDeleteCmd = &cobra.Command{
+	Use:     "delete <name>",
+	Aliases: []string{"rm"},
+	Short:   "Delete a saved view",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runViewDelete,
+}
+
+// Command flags for the view commands.
+var (
+	viewProjectFlag string // --project: Restrict the view to a project
+	viewTagsFlag    string // --tags: Require tags
+	viewAllFlag     bool   // --all: Include completed items
+	viewJSONFlag    bool   // --json: Output as JSON
+)
+
+// viewNameRegex matches valid view names.
+var viewNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// runViewSave builds the view's query from the arguments and flags, checks
+// that it parses, and stores it in the configuration.
+func runViewSave(cmd *cobra.Command, args []string) error {
+	name := strings.TrimPrefix(args[0], "@")
+	if !viewNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid view name %q: use letters, digits, '-' and '_'", args[0])
+	}
+
+	var terms []string
+	if viewProjectFlag != "" {
+		terms = append(terms, fieldTerm(query.FieldProject, viewProjectFlag))
+	}
+	tags, err := parseTagInput(viewTagsFlag)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		terms = append(terms, fieldTerm(query.FieldTag, tag))
+	}
+	if q := strings.TrimSpace(strings.Join(args[1:], " ")); q != "" {
+		// Keep a top-level OR from swallowing the terms from the flags
+		combined := len(terms) > 0 || viewAllFlag
+		if combined && (strings.Contains(q, " OR ") || strings.Contains(q, " | ")) {
+			q = "(" + q + ")"
+		}
+		terms = append(terms, q)
+	}
+	if viewAllFlag {
+		terms = append(terms, query.FieldStatus+":"+query.StatusAny)
+	}
+	queryText := strings.Join(terms, " ")
+
+	storagePath := config.FindStoragePath(pathFlag)
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+
+	views := make(map[string]string, len(cfg.Views)+1)
+	for k, v := range cfg.Views {
+		views[k] = v
+	}
+	views[name] = queryText
+	if _, err := query.ParseWithViews(queryText, views); err != nil {
+		return err
+	}
+
+	_, existed := cfg.Views[name]
+	cfg.Views = views
+	if err := config.SaveStoreConfig(storagePath, cfg); err != nil {
+		return err
+	}
+
+	if existed {
+		cmd.Printf("Updated view @%s: %s\n", name, describeViewQuery(queryText))
+	} else {
+		cmd.Printf("Saved view @%s: %s\n", name, describeViewQuery(queryText))
+	}
+	return nil
+}
+
+// runViewList prints all saved views in name order.
+func runViewList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadStoreConfig(config.FindStoragePath(pathFlag))
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(cfg.Views))
+	for name := range cfg.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if viewJSONFlag {
+		type jsonView struct {
+			Name  string `json:"name"`
+			Query string `json:"query"`
+		}
+		out := make([]jsonView, 0, len(names))
+		for _, name := range names {
+			out = append(out, jsonView{Name: name, Query: cfg.Views[name]})
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal views to JSON: %w", err)
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+
+	if len(names) == 0 {
+		cmd.Println("No saved views. Create one with 'ck view save <name> [query]'.")
+		return nil
+	}
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for _, name := range names {
+		cmd.Printf("@%-*s  %s\n", width, name, describeViewQuery(cfg.Views[name]))
+	}
+	return nil
+}
+
+// runViewDelete removes a view from the configuration.
+func runViewDelete(cmd *cobra.Command, args []string) error {
+	name := strings.TrimPrefix(args[0], "@")
+	storagePath := config.FindStoragePath(pathFlag)
+	cfg, err := config.LoadStoreConfig(storagePath)
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Views[name]; !ok {
+		return fmt.Errorf("no view named %q", name)
+	}
+
+	delete(cfg.Views, name)
+	if err := config.SaveStoreConfig(storagePath, cfg); err != nil {
+		return err
+	}
+	cmd.Printf("Deleted view @%s\n", name)
+	return nil
+}
+
+// parseItemQuery parses the query given as command arguments, expanding
+// references to the views saved for the current store.
+func parseItemQuery(args []string) (query.Node, error) {
+	cfg, err := config.LoadStoreConfig(config.FindStoragePath(pathFlag))
+	if err != nil {
+		return nil, err
+	}
+	return query.ParseWithViews(strings.Join(args, " "), viewsOf(cfg))
+}
+
+// viewsOf returns the views of cfg for query.ParseWithViews. The result is
+// never nil, so @name always refers to a view even if none are saved.
+func viewsOf(cfg *config.StoreConfig) map[string]string {
+	if cfg.Views == nil {
+		return map[string]string{}
+	}
+	return cfg.Views
+}
+
+// fieldTerm formats a field:value query term, quoting the value if needed.
+func fieldTerm(field, value string) string {
+	if strings.ContainsAny(value, " \t()\"") {
+		value = fmt.Sprintf("%q", value)
+	}
+	return field + ":" + value
+}
+
+// describeViewQuery returns the query of a view for display.
+func describeViewQuery(q string) string {
+	if q == "" {
+		return "(all active items)"
+	}
+	return q
+}
+
+// init registers the view commands with the root command.
+func init() {
+	viewSaveCmd.Flags().StringVarP(&viewProjectFlag, "project", "P", "", "Only include items of this project")
+	viewSaveCmd.Flags().StringVarP(&viewTagsFlag, "tags", "t", "", "Only include items with all of these tags (comma or space separated)")
+	viewSaveCmd.Flags().BoolVarP(&viewAllFlag, "all", "a", false, "Include completed items")
+	viewListCmd.Flags().BoolVar(&viewJSONFlag, "json", false, "Output as JSON")
+
+	viewCmd.AddCommand(viewSaveCmd, viewListCmd, viewDeleteCmd)
+	RootCmd.AddCommand(viewCmd)
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/config"
+)
+
+func TestViewCommands(t *testing.T) {
+	storagePath, cleanup := createSearchTestStorage(t)
+	defer cleanup()
+
+	count := func(t *testing.T, args ...string) int {
+		t.Helper()
+		out, err := run(t, args...)
+		if err != nil {
+			t.Fatalf("%v failed: %v", args, err)
+		}
+		var items []map[string]interface{}
+		if err := json.Unmarshal([]byte(out), &items); err != nil {
+			t.Fatalf("Failed to unmarshal: %v\nOutput: %s", err, out)
+		}
+		return len(items)
+	}
+
+	if out, err := run(t, "view", "save", "triage", "--project", "carscoring-app", "--tags", "auth"); err != nil || !strings.Contains(out, "Saved view @triage") {
+		t.Fatalf("view save failed: %v %q", err, out)
+	}
+	if _, err := run(t, "view", "save", "everything", "tag:ui OR tag:api", "--all"); err != nil {
+		t.Fatalf("view save failed: %v", err)
+	}
+
+	t.Run("views are stored in the store config", func(t *testing.T) {
+		cfg, err := config.LoadStoreConfig(storagePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Views["triage"] != "project:carscoring-app tag:auth" {
+			t.Errorf("Unexpected triage query %q", cfg.Views["triage"])
+		}
+		if cfg.Views["everything"] != "(tag:ui OR tag:api) status:any" {
+			t.Errorf("Unexpected everything query %q", cfg.Views["everything"])
+		}
+	})
+
+	t.Run("list and search expand views", func(t *testing.T) {
+		if n := count(t, "list", "@triage", "--json"); n != 1 {
+			t.Errorf("list @triage: expected 1 item, got %d", n)
+		}
+		if n := count(t, "list", "@everything", "--json"); n != 2 {
+			t.Errorf("list @everything: expected 2 items including completed, got %d", n)
+		}
+		if n := count(t, "search", "@triage", "rate", "--json"); n != 1 {
+			t.Errorf("search @triage rate: expected 1 item, got %d", n)
+		}
+		if n := count(t, "search", "@triage", "whatsapp", "--json"); n != 0 {
+			t.Errorf("search @triage whatsapp: expected 0 items, got %d", n)
+		}
+	})
+
+	t.Run("unknown views are errors", func(t *testing.T) {
+		if _, err := run(t, "list", "@nope"); err == nil || !strings.Contains(err.Error(), `unknown view "nope"`) {
+			t.Errorf("Expected unknown view error, got %v", err)
+		}
+		if _, err := run(t, "view", "save", "bad", "@bad"); err == nil {
+			t.Error("Expected error for a view referring to itself")
+		}
+	})
+
+	t.Run("list and delete", func(t *testing.T) {
+		out, err := run(t, "view", "list")
+		if err != nil || !strings.Contains(out, "@everything") || !strings.Contains(out, "@triage") {
+			t.Errorf("Unexpected view list: %v %q", err, out)
+		}
+		if _, err := run(t, "view", "delete", "triage"); err != nil {
+			t.Fatalf("view delete failed: %v", err)
+		}
+		if _, err := run(t, "view", "delete", "triage"); err == nil {
+			t.Error("Expected error deleting a missing view")
+		}
+		out, _ = run(t, "view", "list", "--json")
+		if strings.Contains(out, "triage") {
+			t.Errorf("Expected triage to be deleted, got %q", out)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+// Package config provides configuration management for ContextKeeper.
+//
+// This file contains the StoreConfig type, which holds settings that are kept
+// next to items.json and shared with the rest of the team through git.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// StoreConfigFileName is the name of the configuration file inside the storage directory.
+const StoreConfigFileName = "config.json"
+
+// StoreConfig holds per-store settings.
+//
+// The zero value is a valid configuration and is used when no config.json exists.
+type StoreConfig struct {
+	// Sync controls how items are written to AI agent rule files
+	Sync SyncConfig `json:"sync"`
+
+	// Views maps view names to saved queries (see package query), used as
+	// @name in ck list, ck search and sync filters
+	Views map[string]string `json:"views,omitempty"`
+
+	// TagAliases maps alias tags to the tags they stand for, such as "bugs"
+	// to "bug"; tags given on the command line are replaced by them
+	TagAliases map[string]string `json:"tag_aliases,omitempty"`
+
+	// Projects holds per-project settings by project name; the settings of
+	// a project apply to its subprojects too
+	Projects map[string]ProjectConfig `json:"projects,omitempty"`
+
+	// Scan controls which comments ck scan imports
+	Scan ScanConfig `json:"scan,omitempty"`
+
+	// Branches controls how items are bound to git branches
+	Branches BranchConfig `json:"branches,omitempty"`
+}
+
+// BranchConfig controls branch-bound items.
+type BranchConfig struct {
+	// Auto binds new items to the current branch, unless it is the base branch
+	Auto bool `json:"auto,omitempty"`
+
+	// Base is the branch feature branches are merged into; empty means main
+	// or master, whichever exists
+	Base string `json:"base,omitempty"`
+}
+
+// ProjectConfig holds the settings of a project.
+type ProjectConfig struct {
+	// Tags are added to new items of the project
+	Tags []string `json:"tags,omitempty"`
+
+	// SyncDir is a subdirectory of the repository, relative to its root,
+	// whose agent rule directories receive the project's items on ck sync,
+	// such as the project's directory in a monorepo
+	SyncDir string `json:"sync_dir,omitempty"`
+}
+
+// ScanConfig controls how ck scan finds marker comments.
+type ScanConfig struct {
+	// Markers replaces the default markers (TODO, FIXME, HACK and XXX)
+	Markers []string `json:"markers,omitempty"`
+}
+
+// SyncConfig controls the layout of the files generated by ck sync.
+type SyncConfig struct {
+	// GroupBy selects how items are split into sections: "project", "tag", "kind",
+	// or empty for a single flat list
+	GroupBy string `json:"group_by,omitempty"`
+
+	// Order lists section names that should appear first, in this order.
+	// Sections not listed follow in alphabetical order.
+	Order []string `json:"order,omitempty"`
+
+	// Limit is the maximum number of items written per section (0 means no limit)
+	Limit int `json:"limit,omitempty"`
+
+	// Kinds lists the tags that define an item's kind when grouping by kind.
+	// An item's kind is the first of these tags it carries.
+	Kinds []string `json:"kinds,omitempty"`
+
+	// HideMetadata turns off the compact age/metadata suffix on each item
+	HideMetadata bool `json:"hide_metadata,omitempty"`
+
+	// Filter is a query (see package query) selecting the items to sync
+	Filter string `json:"filter,omitempty"`
+}
+
+// DefaultKinds are the item kinds used when SyncConfig.Kinds is empty.
+var DefaultKinds = []string{"decision", "bug", "feature", "todo", "idea"}
+
+// StoreDir returns the storage directory for a path returned by FindStoragePath.
+//
+// The storage path may point either at the directory or directly at items.json
+// (as CK_STORAGE_PATH is allowed to), so a trailing items.json is stripped.
+func StoreDir(storagePath string) string {
+	if strings.HasSuffix(storagePath, "items.json") {
+		return filepath.Dir(storagePath)
+	}
+	return storagePath
+}
+
+// LoadStoreConfig reads the configuration for the given storage path.
+//
+// A missing config.json is not an error; the zero StoreConfig is returned instead.
+func LoadStoreConfig(storagePath string) (*StoreConfig, error) {
+	cfg := &StoreConfig{}
+	path := filepath.Join(StoreDir(storagePath), StoreConfigFileName)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %q: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %q: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %q: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// validate checks the settings that could make ck write to the wrong files.
+func (c *StoreConfig) validate() error {
+	for project, settings := range c.Projects {
+		if settings.SyncDir == "" {
+			continue
+		}
+		// The repository root would make the project's files replace the
+		// files of all items
+		dir := filepath.Clean(settings.SyncDir)
+		if filepath.IsAbs(dir) || dir == "." || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("sync_dir %q of project %q must be a subdirectory of the repository", settings.SyncDir, project)
+		}
+	}
+	return nil
+}
+
+// SaveStoreConfig writes the configuration for the given storage path.
+func SaveStoreConfig(storagePath string, cfg *StoreConfig) error {
+	dir := StoreDir(storagePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory %q: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config to JSON: %w", err)
+	}
+
+	path := filepath.Join(dir, StoreConfigFileName)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file %q: %w", path, err)
+	}
+	return nil
+}
+
+// SyncKinds returns the configured item kinds, or DefaultKinds if none are set.
+func (c *StoreConfig) SyncKinds() []string {
+	if len(c.Sync.Kinds) > 0 {
+		return c.Sync.Kinds
+	}
+	return DefaultKinds
+}
+
+// ProjectTags returns the default tags of new items of project: those of
+// the project and of the projects it is a subproject of, outermost first.
+func (c *StoreConfig) ProjectTags(project string) []string {
+	var names []string
+	for name := project; name != ""; name = models.ParentProject(name) {
+		names = append([]string{name}, names...)
+	}
+	var tags []string
+	for _, name := range names {
+		tags = append(tags, c.Projects[name].Tags...)
+	}
+	return tags
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreDir(t *testing.T) {
+	dir := filepath.Join("project", ".contextkeeper")
+	if got := StoreDir(dir); got != dir {
+		t.Errorf("StoreDir(%q) = %q, want %q", dir, got, dir)
+	}
+	if got := StoreDir(filepath.Join(dir, "items.json")); got != dir {
+		t.Errorf("StoreDir with items.json = %q, want %q", got, dir)
+	}
+}
+
+func TestStoreConfigRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ck-config-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A missing file yields the zero configuration
+	cfg, err := LoadStoreConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadStoreConfig() error: %v", err)
+	}
+	if cfg.Sync.GroupBy != "" || len(cfg.SyncKinds()) != len(DefaultKinds) {
+		t.Errorf("Expected zero config with default kinds, got %+v", cfg)
+	}
+
+	cfg.Sync.GroupBy = "project"
+	cfg.Sync.Limit = 5
+	if err := SaveStoreConfig(filepath.Join(tmpDir, "items.json"), cfg); err != nil {
+		t.Fatalf("SaveStoreConfig() error: %v", err)
+	}
+
+	loaded, err := LoadStoreConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadStoreConfig() error: %v", err)
+	}
+	if loaded.Sync.GroupBy != "project" || loaded.Sync.Limit != 5 {
+		t.Errorf("Unexpected loaded config: %+v", loaded.Sync)
+	}
+}
+
+func TestLoadStoreConfigInvalidJSON(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "ck-config-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	os.WriteFile(filepath.Join(tmpDir, StoreConfigFileName), []byte("{"), 0644)
+	if _, err := LoadStoreConfig(tmpDir); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestProjectTags(t *testing.T) {
+	cfg := &StoreConfig{Projects: map[string]ProjectConfig{
+		"platform":     {Tags: []string{"backend"}},
+		"platform/api": {Tags: []string{"api"}, SyncDir: "services/api"},
+	}}
+	if got := cfg.ProjectTags("platform/api/auth"); strings.Join(got, ",") != "backend,api" {
+		t.Errorf("ProjectTags(platform/api/auth) = %v, want [backend api]", got)
+	}
+	if got := cfg.ProjectTags("web"); len(got) != 0 {
+		t.Errorf("ProjectTags(web) = %v, want none", got)
+	}
+}
+
+func TestLoadStoreConfigInvalidSyncDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, syncDir := range []string{".", "./", "..", "../x", "a/../..", "/abs"} {
+		data := `{"projects": {"api": {"sync_dir": "` + syncDir + `"}}}`
+		os.WriteFile(filepath.Join(tmpDir, StoreConfigFileName), []byte(data), 0644)
+		if _, err := LoadStoreConfig(tmpDir); err == nil {
+			t.Errorf("Expected error for sync_dir %q", syncDir)
+		}
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, StoreConfigFileName), []byte(`{"projects": {"api": {"sync_dir": "services/api"}}}`), 0644)
+	if _, err := LoadStoreConfig(tmpDir); err != nil {
+		t.Errorf("Expected a subdirectory to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,238 @@
+// Package git runs git commands for ContextKeeper.
+//
+// ContextKeeper does not link against a git library; it runs the git
+// executable, so features built on git history only work where git is
+// installed. Callers should check IsRepo first and degrade gracefully when
+// the project is not a git repository.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Commit is a commit from the log.
+type Commit struct {
+	Hash    string
+	Author  string
+	Date    time.Time
+	Message string   // The full commit message
+	Parents []string // Hashes of the parent commits
+}
+
+// Subject returns the first line of the commit message.
+func (c Commit) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// ShortHash returns the first 7 characters of the commit hash.
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// Run runs git with args in dir and returns its standard output. A failing
+// command returns an error including git's error output.
+func Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// IsRepo reports whether dir is inside a git work tree.
+func IsRepo(dir string) bool {
+	out, err := Run(dir, "rev-parse", "--is-inside-work-tree")
+	return err == nil && strings.TrimSpace(out) == "true"
+}
+
+// Separators of the fields and records in the log format.
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+)
+
+// Log returns the commits listed by git log with the given extra arguments,
+// newest first.
+func Log(dir string, args ...string) ([]Commit, error) {
+	format := "--format=" + strings.Join([]string{"%H", "%an", "%aI", "%P", "%B"}, fieldSep) + recordSep
+	out, err := Run(dir, append([]string{"log", format}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, recordSep) {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), fieldSep, 5)
+		if len(fields) != 5 {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit date %q: %w", fields[2], err)
+		}
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Date:    date,
+			Message: strings.TrimSpace(fields[4]),
+			Parents: strings.Fields(fields[3]),
+		})
+	}
+	return commits, nil
+}
+
+// Trailer is a "Key: value" line at the end of a commit message, such as
+// "Closes: ck:5299c5".
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+// Trailers returns the trailers of a commit message: the lines of its last
+// paragraph, if all of them have the form "Key: value". Comment lines
+// starting with "#", as in the file passed to a commit-msg hook, are ignored.
+func Trailers(message string) []Trailer {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	// The last paragraph, which must not be the subject
+	start := len(lines)
+	for start > 0 && lines[start-1] != "" {
+		start--
+	}
+	if start == 0 {
+		return nil
+	}
+
+	var trailers []Trailer
+	for _, line := range lines[start:] {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: key, Value: strings.TrimSpace(value)})
+	}
+	return trailers
+}
+
+// HooksDir returns the directory git runs hooks from for the repository at
+// dir, honoring core.hooksPath.
+func HooksDir(dir string) (string, error) {
+	out, err := Run(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	hooks := strings.TrimSpace(out)
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	return hooks, nil
+}
+
+// CurrentBranch returns the name of the branch checked out in the repository
+// at dir, or an empty string if HEAD is detached or dir is not a repository.
+func CurrentBranch(dir string) string {
+	out, err := Run(dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// Branches returns the names of the local branches of the repository at dir.
+// With merged set, only the branches merged into that branch or commit are
+// returned.
+func Branches(dir, merged string) ([]string, error) {
+	args := []string{"for-each-ref", "--format=%(refname:short)"}
+	if merged != "" {
+		args = append(args, "--merged="+merged)
+	}
+	out, err := Run(dir, append(args, "refs/heads/")...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// RemoteBranches returns the names of the remote-tracking branches of the
+// repository at dir, without the name of the remote.
+func RemoteBranches(dir string) ([]string, error) {
+	out, err := Run(dir, "for-each-ref", "--format=%(refname:lstrip=3)", "refs/remotes/")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range strings.Fields(out) {
+		if name != "HEAD" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// GoneBranches returns the names of the local branches of the repository at
+// dir whose upstream branch was deleted from the remote.
+func GoneBranches(dir string) ([]string, error) {
+	out, err := Run(dir, "for-each-ref", "--format=%(refname:short) %(upstream:track)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if name, track, ok := strings.Cut(line, " "); ok && track == "[gone]" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// ResolveCommit returns the commit hash that rev, such as a branch name,
+// points to.
+func ResolveCommit(dir, rev string) (string, error) {
+	out, err := Run(dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Show returns the content of the file at path, relative to dir, as of the
+// commit rev.
+func Show(dir, rev, path string) ([]byte, error) {
+	out, err := Run(dir, "show", rev+":./"+filepath.ToSlash(path))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// TopLevel returns the root directory of the work tree containing dir.
+func TopLevel(dir string) (string, error) {
+	out, err := Run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(strings.TrimSpace(out)), nil
+}
@@ -0,0 +1,100 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// initRepo creates a git repository in a temporary directory with one commit
+// per message.
+func initRepo(t *testing.T, messages ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=Ada", "-c", "user.email=ada@example.com"}, args...)
+		if _, err := Run(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q")
+	for i, msg := range messages {
+		name := filepath.Join(dir, "file.txt")
+		if err := os.WriteFile(name, []byte{byte('a' + i)}, 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "file.txt")
+		run("commit", "-q", "-m", msg)
+	}
+	return dir
+}
+
+func TestLog(t *testing.T) {
+	dir := initRepo(t, "First commit", "Second commit\n\nWith a body")
+
+	if !IsRepo(dir) {
+		t.Fatal("IsRepo() = false, want true")
+	}
+	if IsRepo(t.TempDir()) {
+		t.Error("IsRepo() = true outside of a repository")
+	}
+
+	commits, err := Log(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Log() returned %d commits, want 2", len(commits))
+	}
+	c := commits[0]
+	if c.Subject() != "Second commit" || c.Message != "Second commit\n\nWith a body" {
+		t.Errorf("Newest commit = %q, want the second commit", c.Message)
+	}
+	if c.Author != "Ada" || len(c.Hash) != 40 || len(c.ShortHash()) != 7 || c.Date.IsZero() {
+		t.Errorf("Unexpected commit: %+v", c)
+	}
+
+	commits, err = Log(dir, "--grep=First")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 || commits[0].Subject() != "First commit" {
+		t.Errorf("Log(--grep) = %+v, want the first commit", commits)
+	}
+}
+
+func TestTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []Trailer
+	}{
+		{
+			name:    "trailers",
+			message: "Fix login\n\nThe token expired.\n\nCloses: ck:5299c5\nRefs: ck:0c9d88, ck:a1b2c3\n",
+			want:    []Trailer{{"Closes", "ck:5299c5"}, {"Refs", "ck:0c9d88, ck:a1b2c3"}},
+		},
+		{
+			name:    "comment lines are ignored",
+			message: "Fix login\n\nCloses: ck:5299c5\n# Please enter the commit message\n#\n",
+			want:    []Trailer{{"Closes", "ck:5299c5"}},
+		},
+		{
+			name:    "subject only",
+			message: "Closes: ck:5299c5",
+		},
+		{
+			name:    "last paragraph is prose",
+			message: "Fix login\n\nCloses: ck:5299c5\n\nSome more text.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Trailers(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Trailers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
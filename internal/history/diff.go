@@ -0,0 +1,125 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// ItemDiff is the change of one item between two versions of the store.
+type ItemDiff struct {
+	ID      string
+	Content string // The content in the newer version, or the older if deleted
+
+	Kind    string   // One of the Kind* constants, except KindRestored
+	Changes []Change // The changed fields; for KindCreated the initial values
+}
+
+// Revision returns the items of the store file at path as of the commit rev,
+// or in the working tree if rev is empty. A revision that has no store file
+// has no items.
+func Revision(path, rev string) (map[string]models.ContextItem, error) {
+	if rev == "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		items, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid store file %s: %w", path, err)
+		}
+		return items, nil
+	}
+
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	if !git.IsRepo(dir) {
+		return nil, fmt.Errorf("not a git repository: %s", dir)
+	}
+	hash, err := git.ResolveCommit(dir, rev)
+	if err != nil {
+		return nil, err
+	}
+	data, err := git.Show(dir, hash, name)
+	if err != nil {
+		return map[string]models.ContextItem{}, nil
+	}
+	items, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store file in %s: %w", rev, err)
+	}
+	return items, nil
+}
+
+// Diff compares two versions of the store, matching items by ID. Items are
+// ordered by creation; unchanged items are left out.
+func Diff(old, new map[string]models.ContextItem) []ItemDiff {
+	all := make(map[string]models.ContextItem, len(new))
+	ids := make([]string, 0, len(new))
+	for _, items := range []map[string]models.ContextItem{new, old} {
+		for id, item := range items {
+			if _, ok := all[id]; !ok {
+				all[id] = item
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	var diffs []ItemDiff
+	for _, item := range sortedItems(all, ids) {
+		var o, n *models.ContextItem
+		if item, ok := old[item.ID]; ok {
+			o = &item
+		}
+		if item, ok := new[item.ID]; ok {
+			n = &item
+		}
+		if kind, changes, ok := compare(o, n); ok {
+			diffs = append(diffs, ItemDiff{ID: item.ID, Content: item.Content, Kind: kind, Changes: changes})
+		}
+	}
+	return diffs
+}
+
+// LineDiff returns the lines of old and new text as a minimal line diff:
+// lines only in old are prefixed with "- ", lines only in new with "+ " and
+// common lines with "  ".
+func LineDiff(old, new string) []string {
+	a, b := strings.Split(old, "\n"), strings.Split(new, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	return lines
+}
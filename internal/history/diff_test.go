@@ -0,0 +1,65 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+func TestDiff(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	item := func(id, content string, minutes int) models.ContextItem {
+		return models.ContextItem{ID: id, Content: content, CreatedAt: created.Add(time.Duration(minutes) * time.Minute)}
+	}
+	index := func(items ...models.ContextItem) map[string]models.ContextItem {
+		m := make(map[string]models.ContextItem)
+		for _, item := range items {
+			m[item.ID] = item
+		}
+		return m
+	}
+
+	kept := item("a", "Unchanged", 0)
+	edited := item("b", "Use OIDC", 1)
+	done := item("c", "Rate limit", 2)
+	reopened := item("d", "Flaky tests", 3)
+	reopened.SetState(models.StateDone, "", created)
+	removed := item("e", "Old note", 4)
+	added := item("f", "New note", 5)
+
+	old := index(kept, edited, done, reopened, removed)
+	edited.Content = "Use OIDC for login"
+	done.SetState(models.StateDone, "", created)
+	reopened.SetState(models.StateTodo, "", created)
+	added.Project = "api"
+	new := index(kept, edited, done, reopened, added)
+
+	type result struct{ id, kind string }
+	var got []result
+	diffs := Diff(old, new)
+	for _, d := range diffs {
+		got = append(got, result{d.ID, d.Kind})
+	}
+	want := []result{
+		{"b", KindChanged}, {"c", KindCompleted}, {"d", KindReopened}, {"e", KindDeleted}, {"f", KindCreated},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff() = %v, want %v", got, want)
+	}
+	if c := diffs[0].Changes; len(c) != 1 || c[0] != (Change{Field: "content", Old: "Use OIDC", New: "Use OIDC for login"}) {
+		t.Errorf("Unexpected changes of the edited item: %+v", c)
+	}
+	if diffs[3].Content != "Old note" {
+		t.Errorf("Deleted item content = %q", diffs[3].Content)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	got := LineDiff("Use OIDC\nfor login\nand SSO", "Use OIDC\nfor the login\nand SSO\nlater")
+	want := []string{"  Use OIDC", "- for login", "+ for the login", "  and SSO", "+ later"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LineDiff() = %q, want %q", got, want)
+	}
+}
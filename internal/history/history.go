@@ -0,0 +1,386 @@
+// Package history reconstructs how items changed from the git history of
+// the store file.
+//
+// The store file, items.json, is usually committed with the project. Every
+// commit that changed it holds a complete version of the store, a Snapshot.
+// Comparing one item in each snapshot with the versions in the commit's
+// parents yields its Events: when it was created and by whom, each edit, its
+// completion, and so on. Merges only yield events for changes that none of
+// the merged branches made, so each change is credited to the commit that
+// made it. Changes to
+// the store that are not committed yet form a last snapshot without a
+// commit. Diff compares all items between two versions of the store the same
+// way.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// Snapshot is a version of the store file.
+type Snapshot struct {
+	Commit *git.Commit // The commit, or nil for the working tree
+
+	Items map[string]models.ContextItem // Items by ID
+
+	// Parents are the versions of the store that the snapshot's changes are
+	// relative to: those of the commit's parents, several for a merge and
+	// none for the first commit, or that of HEAD for the working tree.
+	Parents []map[string]models.ContextItem
+}
+
+// Kinds of events.
+const (
+	KindCreated   = "created"
+	KindChanged   = "changed"
+	KindCompleted = "completed"
+	KindReopened  = "reopened"
+	KindDeleted   = "deleted"
+	KindRestored  = "restored"
+)
+
+// Event is a change of one item made by a commit.
+type Event struct {
+	Commit *git.Commit // The commit, or nil for uncommitted changes
+
+	Kind    string   // One of the Kind* constants
+	Changes []Change // The changed fields; for KindCreated the initial values
+}
+
+// Change is the change of one field of an item. Values are formatted for
+// display; an empty value means the field was not set.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Load returns the versions of the store file at path: one for each commit
+// that changed it, parents before their children, and last one for the
+// working tree if it differs from HEAD or from the last commit. It fails if
+// the file is not in a git repository.
+//
+// Commits whose version of the file cannot be parsed, such as a committed
+// merge conflict, are skipped; their children are compared with the
+// snapshot before them instead.
+func Load(path string) ([]Snapshot, error) {
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	if !git.IsRepo(dir) {
+		return nil, fmt.Errorf("not a git repository: %s", dir)
+	}
+	// With --parents the parents are rewritten to the nearest commits that
+	// changed the file, which hold the same version of it
+	commits, err := git.Log(dir, "--reverse", "--topo-order", "--parents", "--", name)
+	if err != nil {
+		return nil, err
+	}
+
+	// version returns the store in a commit, and false if it cannot be parsed
+	versions := make(map[string]map[string]models.ContextItem)
+	version := func(hash string) (map[string]models.ContextItem, bool) {
+		if items, ok := versions[hash]; ok {
+			return items, items != nil
+		}
+		items := map[string]models.ContextItem{}
+		// A commit that deleted the file has no version of it
+		if data, err := git.Show(dir, hash, name); err == nil {
+			items, _ = parse(data)
+		}
+		versions[hash] = items
+		return items, items != nil
+	}
+
+	var snapshots []Snapshot
+	for i := range commits {
+		items, ok := version(commits[i].Hash)
+		if !ok {
+			continue
+		}
+		var parents []map[string]models.ContextItem
+		for _, hash := range commits[i].Parents {
+			if parent, ok := version(hash); ok {
+				parents = append(parents, parent)
+			}
+		}
+		if len(parents) == 0 && len(commits[i].Parents) > 0 && len(snapshots) > 0 {
+			parents = append(parents, snapshots[len(snapshots)-1].Items)
+		}
+		snapshots = append(snapshots, Snapshot{Commit: &commits[i], Items: items, Parents: parents})
+	}
+
+	items, err := Revision(path, "")
+	if err != nil {
+		return nil, err
+	}
+	head := map[string]models.ContextItem{}
+	if len(commits) > 0 {
+		if hash, err := git.ResolveCommit(dir, "HEAD"); err == nil {
+			if items, ok := version(hash); ok {
+				head = items
+			}
+		}
+	}
+	if n := len(snapshots); n == 0 || !reflect.DeepEqual(head, items) || !reflect.DeepEqual(snapshots[n-1].Items, items) {
+		snapshots = append(snapshots, Snapshot{Items: items, Parents: []map[string]models.ContextItem{head}})
+	}
+	return snapshots, nil
+}
+
+// previous returns the version of the item with the given ID that the
+// snapshot's change of it is relative to, nil if the item did not exist.
+// changed is false if the snapshot has the item as one of its parents had
+// it, including a merge taking the item from the merged branch.
+func (s Snapshot) previous(id string) (prev *models.ContextItem, changed bool) {
+	cur, exists := s.Items[id]
+	for _, parent := range s.Parents {
+		if old, had := parent[id]; had == exists && reflect.DeepEqual(old, cur) {
+			return nil, false
+		}
+	}
+	if len(s.Parents) > 0 {
+		if old, had := s.Parents[0][id]; had {
+			return &old, true
+		}
+	}
+	return nil, exists
+}
+
+// parse reads a version of the store file.
+func parse(data []byte) (map[string]models.ContextItem, error) {
+	var list []models.ContextItem
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+	}
+	items := make(map[string]models.ContextItem, len(list))
+	for _, item := range list {
+		items[item.ID] = item
+	}
+	return items, nil
+}
+
+// IDs returns the IDs of all items in the snapshots, including deleted ones,
+// in order of first appearance.
+func IDs(snapshots []Snapshot) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, s := range snapshots {
+		var added []string
+		for id := range s.Items {
+			if !seen[id] {
+				seen[id] = true
+				added = append(added, id)
+			}
+		}
+		// Map order is random; order the new items by creation instead
+		for _, item := range sortedItems(s.Items, added) {
+			ids = append(ids, item.ID)
+		}
+	}
+	return ids
+}
+
+// sortedItems returns the items with the given IDs by creation time.
+func sortedItems(items map[string]models.ContextItem, ids []string) []models.ContextItem {
+	out := make([]models.ContextItem, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, items[id])
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Resolve returns the ID of the item in the snapshots whose ID is id or
+// that id refers to, see models.MatchesID. ok is false if there is no such item or more than one.
+func Resolve(snapshots []Snapshot, id string) (full string, ok bool) {
+	for _, candidate := range IDs(snapshots) {
+		if candidate == id {
+			return candidate, true
+		}
+		if models.MatchesID(candidate, id) {
+			if full != "" {
+				return "", false
+			}
+			full = candidate
+		}
+	}
+	return full, full != ""
+}
+
+// ItemHistory returns the events of the item with the given ID, oldest
+// first. Commits that changed only bookkeeping of the item, such as its
+// state transitions without a change of state, yield no event.
+func ItemHistory(snapshots []Snapshot, id string) []Event {
+	var events []Event
+	seen := false
+	for _, s := range snapshots {
+		prev, changed := s.previous(id)
+		if !changed {
+			continue
+		}
+		var cur *models.ContextItem
+		if item, ok := s.Items[id]; ok {
+			cur = &item
+		}
+		kind, changes, ok := compare(prev, cur)
+		if !ok {
+			continue
+		}
+		if kind == KindCreated && seen {
+			kind = KindRestored
+		}
+		seen = seen || cur != nil
+		events = append(events, Event{Commit: s.Commit, Kind: kind, Changes: changes})
+	}
+	return events
+}
+
+// compare returns the kind of change from old to new, either of which is nil
+// if the item does not exist, and the changed fields. ok is false if no
+// field shown in the history changed.
+func compare(old, new *models.ContextItem) (kind string, changes []Change, ok bool) {
+	switch {
+	case old == nil && new == nil:
+		return "", nil, false
+	case old == nil:
+		return KindCreated, diff(&models.ContextItem{}, new), true
+	case new == nil:
+		return KindDeleted, nil, true
+	}
+	changes = diff(old, new)
+	if len(changes) == 0 {
+		return "", nil, false
+	}
+	switch wasDone, isDone := old.IsCompleted(), new.IsCompleted(); {
+	case !wasDone && isDone:
+		return KindCompleted, changes, true
+	case wasDone && !isDone:
+		return KindReopened, changes, true
+	}
+	return KindChanged, changes, true
+}
+
+// LastChanged returns, for each item of the last snapshot, the snapshot that
+// last changed it in any way: the last one that made the item as it is now.
+// Use its Commit, which is nil for uncommitted changes.
+func LastChanged(snapshots []Snapshot) map[string]Snapshot {
+	last := make(map[string]Snapshot)
+	n := len(snapshots)
+	if n == 0 {
+		return last
+	}
+	current := snapshots[n-1].Items
+	for _, s := range snapshots {
+		for id, item := range s.Items {
+			now, ok := current[id]
+			if !ok || !reflect.DeepEqual(item, now) {
+				continue
+			}
+			if _, changed := s.previous(id); changed {
+				last[id] = s
+			}
+		}
+	}
+	return last
+}
+
+// diff returns the changes between two versions of an item, in a fixed
+// field order.
+func diff(old, new *models.ContextItem) []Change {
+	var changes []Change
+	add := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, Change{Field: field, Old: o, New: n})
+		}
+	}
+	add("content", old.Content, new.Content)
+	add("state", state(old), state(new))
+	add("project", old.Project, new.Project)
+	add("tags", strings.Join(old.Tags, ", "), strings.Join(new.Tags, ", "))
+	add("priority", old.Priority, new.Priority)
+	add("due", day(old.DueAt), day(new.DueAt))
+	add("branch", old.Branch, new.Branch)
+	add("parent", old.ParentID, new.ParentID)
+	add("pinned", flag(old.Pinned), flag(new.Pinned))
+	add("archived", flag(old.Archived), flag(new.Archived))
+	add("links", links(old.Links), links(new.Links))
+	add("anchors", anchors(old.Anchors), anchors(new.Anchors))
+	add("commits", commits(old.Commits), commits(new.Commits))
+	return changes
+}
+
+// state formats the workflow state of an item, omitting the state of a
+// zero item.
+func state(item *models.ContextItem) string {
+	if item.ID == "" {
+		return ""
+	}
+	if reason := item.StateReason(); reason != "" {
+		return item.EffectiveState() + " (" + reason + ")"
+	}
+	return item.EffectiveState()
+}
+
+// day formats a due date.
+func day(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// flag formats a boolean field.
+func flag(b bool) string {
+	if b {
+		return "yes"
+	}
+	return ""
+}
+
+// links formats the links of an item.
+func links(links []models.Link) string {
+	parts := make([]string, 0, len(links))
+	for _, l := range links {
+		parts = append(parts, l.Type+" "+short(l.Target))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// anchors formats the code anchors of an item.
+func anchors(anchors []models.Anchor) string {
+	parts := make([]string, 0, len(anchors))
+	for _, a := range anchors {
+		parts = append(parts, a.Path+":"+strconv.Itoa(a.Line))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// commits formats the commits recorded on an item.
+func commits(commits []models.CommitRef) string {
+	parts := make([]string, 0, len(commits))
+	for _, c := range commits {
+		parts = append(parts, git.Commit{Hash: c.Hash}.ShortHash())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// short returns the short form of an item ID.
+func short(id string) string {
+	return models.ShortID(id)
+}
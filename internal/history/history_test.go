@@ -0,0 +1,170 @@
+package history
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/git"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+func TestHistory(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, ".contextkeeper", "items.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	gitRun := func(t *testing.T, author string, args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=" + author, "-c", "user.email=dev@example.com"}, args...)
+		if _, err := git.Run(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save := func(t *testing.T, items ...models.ContextItem) {
+		t.Helper()
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit := func(t *testing.T, author, msg string) {
+		t.Helper()
+		gitRun(t, author, "add", "-A")
+		gitRun(t, author, "commit", "-q", "-m", msg)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Fatal("Expected an error outside a git repository")
+	}
+	gitRun(t, "Ada", "init", "-q")
+
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	login := models.ContextItem{ID: "5299c5ab-0000-0000-0000-000000000001", Content: "Use OIDC", CreatedAt: created}
+	api := models.ContextItem{ID: "77a1e0f2-0000-0000-0000-000000000002", Content: "Rate limit the API", CreatedAt: created.Add(time.Hour)}
+	save(t, login, api)
+	commit(t, "Ada", "Add items")
+
+	login.Content = "Use OIDC for login"
+	login.Tags = []string{"auth"}
+	save(t, login, api)
+	commit(t, "Bob", "Clarify login")
+
+	login.SetState(models.StateDone, "", created.Add(48*time.Hour))
+	save(t, login)
+	commit(t, "Cy", "Finish login, drop API item")
+
+	api.Pinned = true
+	save(t, login, api)
+
+	snapshots, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 4 || snapshots[3].Commit != nil {
+		t.Fatalf("Expected 3 commits and the working tree, got %d snapshots", len(snapshots))
+	}
+
+	t.Run("ItemHistory", func(t *testing.T) {
+		type event struct {
+			author, kind string
+			changes      []Change
+		}
+		var got []event
+		for _, e := range ItemHistory(snapshots, login.ID) {
+			got = append(got, event{e.Commit.Author, e.Kind, e.Changes})
+		}
+		want := []event{
+			{"Ada", KindCreated, []Change{{Field: "content", New: "Use OIDC"}, {Field: "state", New: "todo"}}},
+			{"Bob", KindChanged, []Change{{Field: "content", Old: "Use OIDC", New: "Use OIDC for login"}, {Field: "tags", New: "auth"}}},
+			{"Cy", KindCompleted, []Change{{Field: "state", Old: "todo", New: "done"}}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ItemHistory() =\n%+v\nwant\n%+v", got, want)
+		}
+
+		events := ItemHistory(snapshots, api.ID)
+		var kinds []string
+		for _, e := range events {
+			kinds = append(kinds, e.Kind)
+		}
+		if !reflect.DeepEqual(kinds, []string{KindCreated, KindDeleted, KindRestored}) {
+			t.Errorf("Kinds of the deleted item = %v", kinds)
+		}
+		if events[2].Commit != nil {
+			t.Errorf("Expected the restore to be uncommitted, got %v", events[2].Commit)
+		}
+	})
+
+	t.Run("LastChanged", func(t *testing.T) {
+		last := LastChanged(snapshots)
+		if c := last[login.ID].Commit; c == nil || c.Author != "Cy" {
+			t.Errorf("Login last changed by %v, want Cy", c)
+		}
+		if s, ok := last[api.ID]; !ok || s.Commit != nil {
+			t.Errorf("Expected the API item changed in the working tree, got %v", s.Commit)
+		}
+	})
+
+	t.Run("Resolve", func(t *testing.T) {
+		if id, ok := Resolve(snapshots, "77a1"); !ok || id != api.ID {
+			t.Errorf("Resolve(77a1) = %q, %v", id, ok)
+		}
+		if _, ok := Resolve(snapshots, "0"); ok {
+			t.Error("Expected no match")
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		commit(t, "Ada", "Pin API item")
+		gitRun(t, "Ada", "branch", "-M", "main")
+
+		// A branch adds an item while main edits another one
+		gitRun(t, "Bea", "checkout", "-q", "-b", "feature")
+		cache := models.ContextItem{ID: "9c1d2e3f-0000-0000-0000-000000000003", Content: "Cache tokens", CreatedAt: created.Add(2 * time.Hour)}
+		save(t, login, api, cache)
+		commit(t, "Bea", "Add cache item")
+		gitRun(t, "Max", "checkout", "-q", "main")
+		login.Content = "Use OIDC for login and SSO"
+		save(t, login, api)
+		commit(t, "Max", "Mention SSO")
+		gitRun(t, "Mo", "merge", "-q", "--no-edit", "feature")
+
+		snapshots, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(snapshots); snapshots[n-1].Commit == nil || len(snapshots[n-1].Items) != 3 {
+			t.Fatalf("Expected the merge last, got %+v", snapshots[n-1])
+		}
+
+		authors := func(id string) []string {
+			var got []string
+			for _, e := range ItemHistory(snapshots, id) {
+				got = append(got, e.Commit.Author+" "+e.Kind)
+			}
+			return got
+		}
+		if got, want := authors(cache.ID), []string{"Bea created"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("History of the branch item = %v, want %v", got, want)
+		}
+		if got := authors(login.ID); got[len(got)-1] != "Max changed" {
+			t.Errorf("History of the edited item = %v, want Max last", got)
+		}
+
+		last := LastChanged(snapshots)
+		if c := last[cache.ID].Commit; c == nil || c.Author != "Bea" {
+			t.Errorf("Branch item last changed by %v, want Bea", c)
+		}
+		if c := last[login.ID].Commit; c == nil || c.Author != "Max" {
+			t.Errorf("Edited item last changed by %v, want Max", c)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+// Package models provides data structures for ContextKeeper items.
+//
+// This package contains the core domain models used throughout the application,
+// including the ContextItem struct which represents a single context entry.
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Anchor attaches an item to a line of code. See the anchor package for how
+// anchors are created and re-located after the code changes.
+type Anchor struct {
+	// Path is the file path, relative to the project root (the directory
+	// containing .contextkeeper) and with forward slashes
+	Path string `json:"path"`
+
+	// Line is the 1-based line number
+	Line int `json:"line"`
+
+	// Fingerprint is a hash of the anchored line and the lines around it,
+	// used to find the line again after the code moves
+	Fingerprint string `json:"fingerprint"`
+
+	// Text is the anchored line without leading and trailing whitespace
+	Text string `json:"text,omitempty"`
+
+	// Stale is set when the anchored code could not be found anymore
+	Stale bool `json:"stale,omitempty"`
+}
+
+// String returns the anchor's location as "path:line".
+func (a Anchor) String() string {
+	return fmt.Sprintf("%s:%d", filepath.FromSlash(a.Path), a.Line)
+}
@@ -0,0 +1,45 @@
+// Package models provides data structures for ContextKeeper items.
+//
+// This package contains the core domain models used throughout the application,
+// including the ContextItem struct which represents a single context entry.
+package models
+
+import "strings"
+
+// Item IDs are UUIDs. Items created before IDs became time-ordered have
+// random version 4 IDs, newer ones version 7 IDs, which start with their
+// creation time (see utils.GenerateUUID). Items created around the same time
+// share the first digits of their version 7 IDs, so their short form is
+// taken from the random part at the end instead:
+//
+//	5299c5ab-...                            short ID 5299c5ab
+//	0192f3a4-7c1e-7b2d-9e4f-a1b2c3d4e5f6    short ID a1b2c3d4
+
+// shortIDLength is the length of a short ID.
+const shortIDLength = 8
+
+// timeOrdered reports whether id is a version 7 UUID.
+func timeOrdered(id string) bool {
+	return len(id) == 36 && id[8] == '-' && id[14] == '7' && id[23] == '-'
+}
+
+// ShortID returns the short form of an item ID shown to users: the first 8
+// characters of a version 4 ID, or of the last group of a version 7 ID.
+func ShortID(id string) string {
+	if timeOrdered(id) {
+		id = id[24:]
+	}
+	if len(id) > shortIDLength {
+		return id[:shortIDLength]
+	}
+	return id
+}
+
+// MatchesID reports whether ref refers to the item ID id: whether it is a
+// prefix of the ID or, for a version 7 ID, of its short form.
+func MatchesID(id, ref string) bool {
+	if ref == "" {
+		return false
+	}
+	return strings.HasPrefix(id, ref) || timeOrdered(id) && strings.HasPrefix(id[24:], ref)
+}
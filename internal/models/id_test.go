@@ -0,0 +1,40 @@
+package models
+
+import "testing"
+
+func TestShortID(t *testing.T) {
+	tests := []struct {
+		id, short string
+		refs      []string // References matching the ID
+		others    []string // References not matching it
+	}{
+		{
+			id:     "5299c5ab-1e2f-4a3b-8c4d-5e6f7a8b9c0d",
+			short:  "5299c5ab",
+			refs:   []string{"5299c5ab-1e2f-4a3b-8c4d-5e6f7a8b9c0d", "5299", "5299c5ab-1e"},
+			others: []string{"", "5e6f7a8b", "299c"},
+		},
+		{
+			id:     "0192f3a4-7c1e-7b2d-9e4f-a1b2c3d4e5f6",
+			short:  "a1b2c3d4",
+			refs:   []string{"0192f3a4-7c1e-7b2d-9e4f-a1b2c3d4e5f6", "0192f3", "a1b2", "a1b2c3d4e5f6"},
+			others: []string{"", "9e4f", "b2c3"},
+		},
+		{id: "ab12", short: "ab12", refs: []string{"ab"}, others: []string{"ab123"}},
+	}
+	for _, tt := range tests {
+		if got := ShortID(tt.id); got != tt.short {
+			t.Errorf("ShortID(%q) = %q, want %q", tt.id, got, tt.short)
+		}
+		for _, ref := range tt.refs {
+			if !MatchesID(tt.id, ref) {
+				t.Errorf("Expected %q to match %q", ref, tt.id)
+			}
+		}
+		for _, ref := range tt.others {
+			if MatchesID(tt.id, ref) {
+				t.Errorf("Expected %q not to match %q", ref, tt.id)
+			}
+		}
+	}
+}
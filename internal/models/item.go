@@ -14,6 +14,11 @@ type ContextItem struct {
 	// ID is the unique identifier for this context item
 	ID string `json:"id"`
 
+	// Number is a short handle for the item, unique within its store and
+	// never reused, such as 12 in "ck done 12" (0 until the store assigns
+	// one, see storage.Renumber)
+	Number int `json:"number,omitempty"`
+
 	// Content is the main text content of this context item
 	Content string `json:"content"`
 
@@ -26,20 +31,186 @@ type ContextItem struct {
 	// CreatedAt is the timestamp when this item was created
 	CreatedAt time.Time `json:"created_at"`
 
-	// CompletedAt is the timestamp when this item was completed (nil if not completed)
+	// CompletedAt is the timestamp when this item was closed, as done or
+	// wontfix (nil if the item is open)
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 
+	// State is the workflow state, one of the State* constants. Items written
+	// before states existed have no state; see EffectiveState.
+	State string `json:"state,omitempty"`
+
+	// Transitions records every state change, oldest first
+	Transitions []Transition `json:"transitions,omitempty"`
+
 	// Archived indicates whether this item has been archived
 	Archived bool `json:"archived"`
+
+	// Pinned keeps the item at the top of its section in sync output
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Priority is the urgency of this item: PriorityHigh, PriorityMedium,
+	// PriorityLow, or empty if not set
+	Priority string `json:"priority,omitempty"`
+
+	// DueAt is the day this item is due, as local midnight (nil if no due date)
+	DueAt *time.Time `json:"due_at,omitempty"`
+
+	// Branch is the git branch this item is bound to; it is only listed and
+	// synced while that branch is checked out (empty for global items)
+	Branch string `json:"branch,omitempty"`
+
+	// ParentID is the ID of the item this one is a subtask of (optional)
+	ParentID string `json:"parent_id,omitempty"`
+
+	// Links are typed relationships to other items, see Link
+	Links []Link `json:"links,omitempty"`
+
+	// Anchors are the code locations this item is about (optional)
+	Anchors []Anchor `json:"anchors,omitempty"`
+
+	// Source records where the item was imported from, such as a code
+	// comment found by ck scan (nil for items added by hand)
+	Source *Source `json:"source,omitempty"`
+
+	// Commits are the commits whose messages referenced or closed this item
+	// (see ck hooks), oldest first
+	Commits []CommitRef `json:"commits,omitempty"`
+}
+
+// CommitRef records a commit that referenced an item in its message.
+type CommitRef struct {
+	// Hash is the full commit hash
+	Hash string `json:"hash"`
+
+	// Subject is the first line of the commit message
+	Subject string `json:"subject,omitempty"`
+
+	// At is the commit's author date
+	At time.Time `json:"at"`
+
+	// Closed is set when the commit closed the item
+	Closed bool `json:"closed,omitempty"`
+}
+
+// Source identifies what an imported item was created from.
+type Source struct {
+	// Kind is the importer, one of the Source* constants
+	Kind string `json:"kind"`
+
+	// Key identifies the imported thing, so that re-imports update the item
+	// instead of adding a new one
+	Key string `json:"key"`
 }
 
-// IsCompleted returns true if the context item has been completed.
+// SourceScan marks items imported from code comments by ck scan.
+const SourceScan = "scan"
+
+// Transition is a single change of an item's workflow state.
+type Transition struct {
+	// State is the state the item moved to
+	State string `json:"state"`
+
+	// At is the time of the change
+	At time.Time `json:"at"`
+
+	// Reason explains the change, e.g. why an item is blocked (optional)
+	Reason string `json:"reason,omitempty"`
+}
+
+// Workflow states. Todo, in-progress and blocked items are open; done and
+// wontfix items are closed and have a CompletedAt timestamp.
+const (
+	StateTodo       = "todo"
+	StateInProgress = "in-progress"
+	StateBlocked    = "blocked"
+	StateDone       = "done"
+	StateWontfix    = "wontfix"
+)
+
+// Priority levels, from most to least urgent.
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// PriorityRank returns the sort rank of a priority: 0 for high, 1 for
+// medium, 2 for low and 3 for no priority.
+func PriorityRank(priority string) int {
+	switch priority {
+	case PriorityHigh:
+		return 0
+	case PriorityMedium:
+		return 1
+	case PriorityLow:
+		return 2
+	}
+	return 3
+}
+
+// IsCompleted returns true if the context item has been closed, either as
+// done or as wontfix.
 //
 // A completed item has a non-nil CompletedAt timestamp.
 func (c *ContextItem) IsCompleted() bool {
 	return c.CompletedAt != nil
 }
 
+// EffectiveState returns the workflow state of the item.
+//
+// CompletedAt takes precedence over State, so that items completed before
+// workflow states existed (or by an older version of ck) are done, and an
+// item with no state and no CompletedAt is todo.
+func (c *ContextItem) EffectiveState() string {
+	if c.CompletedAt != nil {
+		if c.State == StateWontfix {
+			return StateWontfix
+		}
+		return StateDone
+	}
+	switch c.State {
+	case StateInProgress, StateBlocked:
+		return c.State
+	}
+	return StateTodo
+}
+
+// SetState moves the item to a workflow state and records the transition.
+//
+// Closing the item (done or wontfix) sets CompletedAt, keeping an existing
+// timestamp when moving between the two; moving to an open state clears it.
+func (c *ContextItem) SetState(state, reason string, now time.Time) {
+	c.State = state
+	c.Transitions = append(c.Transitions, Transition{State: state, At: now, Reason: reason})
+	switch state {
+	case StateDone, StateWontfix:
+		if c.CompletedAt == nil {
+			c.CompletedAt = &now
+		}
+	default:
+		c.CompletedAt = nil
+	}
+}
+
+// StateReason returns the reason recorded with the transition into the
+// item's current state, such as why it is blocked, or an empty string.
+func (c *ContextItem) StateReason() string {
+	if n := len(c.Transitions); n > 0 && c.Transitions[n-1].State == c.EffectiveState() {
+		return c.Transitions[n-1].Reason
+	}
+	return ""
+}
+
+// IsOverdue returns true if the context item is still open and its due day
+// is before the day of now.
+func (c *ContextItem) IsOverdue(now time.Time) bool {
+	if c.DueAt == nil || c.CompletedAt != nil {
+		return false
+	}
+	y, m, d := now.Date()
+	return c.DueAt.Before(time.Date(y, m, d, 0, 0, 0, 0, now.Location()))
+}
+
 // IsArchived returns true if the context item has been archived.
 func (c *ContextItem) IsArchived() bool {
 	return c.Archived
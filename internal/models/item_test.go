@@ -60,4 +60,83 @@ func TestContextItemOptionalFields(t *testing.T) {
 	if _, exists := m["tags"]; exists {
 		t.Error("tags should be omitted when empty")
 	}
+	if _, exists := m["priority"]; exists {
+		t.Error("priority should be omitted when empty")
+	}
+	if _, exists := m["due_at"]; exists {
+		t.Error("due_at should be omitted when empty")
+	}
+}
+
+func TestContextItemIsOverdue(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)
+	yesterday := time.Date(2026, 10, 13, 0, 0, 0, 0, time.Local)
+	today := time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)
+
+	if (&ContextItem{}).IsOverdue(now) {
+		t.Error("Item without due date should not be overdue")
+	}
+	if (&ContextItem{DueAt: &today}).IsOverdue(now) {
+		t.Error("Item due today should not be overdue")
+	}
+	if !(&ContextItem{DueAt: &yesterday}).IsOverdue(now) {
+		t.Error("Item due yesterday should be overdue")
+	}
+	if (&ContextItem{DueAt: &yesterday, CompletedAt: &now}).IsOverdue(now) {
+		t.Error("Completed item should not be overdue")
+	}
+}
+
+func TestPriorityRank(t *testing.T) {
+	if !(PriorityRank(PriorityHigh) < PriorityRank(PriorityMedium) &&
+		PriorityRank(PriorityMedium) < PriorityRank(PriorityLow) &&
+		PriorityRank(PriorityLow) < PriorityRank("")) {
+		t.Error("Expected high < medium < low < none")
+	}
+}
+
+func TestContextItemStates(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	t.Run("legacy items derive their state", func(t *testing.T) {
+		var legacy ContextItem
+		if err := json.Unmarshal([]byte(`{"id":"1","content":"x","completed_at":"2026-01-01T00:00:00Z"}`), &legacy); err != nil {
+			t.Fatal(err)
+		}
+		if got := legacy.EffectiveState(); got != StateDone {
+			t.Errorf("Completed legacy item state = %q, want done", got)
+		}
+		if got := (&ContextItem{}).EffectiveState(); got != StateTodo {
+			t.Errorf("Open legacy item state = %q, want todo", got)
+		}
+	})
+
+	t.Run("transitions are recorded", func(t *testing.T) {
+		item := ContextItem{}
+		item.SetState(StateInProgress, "", now)
+		item.SetState(StateBlocked, "waiting on review", now.Add(time.Hour))
+		if item.EffectiveState() != StateBlocked || item.StateReason() != "waiting on review" || item.CompletedAt != nil {
+			t.Errorf("Unexpected blocked item: %+v", item)
+		}
+
+		item.SetState(StateWontfix, "", now.Add(2*time.Hour))
+		if item.EffectiveState() != StateWontfix || item.CompletedAt == nil || !item.IsCompleted() {
+			t.Errorf("Unexpected wontfix item: %+v", item)
+		}
+
+		item.SetState(StateTodo, "", now.Add(3*time.Hour))
+		if item.EffectiveState() != StateTodo || item.CompletedAt != nil {
+			t.Errorf("Unexpected reopened item: %+v", item)
+		}
+		if len(item.Transitions) != 4 || item.Transitions[1].Reason != "waiting on review" {
+			t.Errorf("Unexpected transitions: %+v", item.Transitions)
+		}
+	})
+
+	t.Run("CompletedAt from an older version wins", func(t *testing.T) {
+		item := ContextItem{State: StateInProgress, CompletedAt: &now}
+		if item.EffectiveState() != StateDone {
+			t.Errorf("Expected done, got %q", item.EffectiveState())
+		}
+	})
 }
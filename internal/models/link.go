@@ -0,0 +1,105 @@
+// Package models provides data structures for ContextKeeper items.
+//
+// This package contains the core domain models used throughout the application,
+// including the ContextItem struct which represents a single context entry.
+package models
+
+// Link is a typed relationship from one item to another. Links are stored on
+// the source item: "a blocks b" is a Link{Type: LinkBlocks, Target: b} on a.
+type Link struct {
+	// Type is the kind of relationship, one of the Link* constants
+	Type string `json:"type"`
+
+	// Target is the full ID of the linked item
+	Target string `json:"target"`
+}
+
+// Link types.
+const (
+	LinkBlocks     = "blocks"
+	LinkRelatesTo  = "relates-to"
+	LinkDuplicates = "duplicates"
+	LinkSupersedes = "supersedes"
+)
+
+// LinkTypes lists all link types in display order.
+var LinkTypes = []string{LinkBlocks, LinkRelatesTo, LinkDuplicates, LinkSupersedes}
+
+// InverseLinkLabel returns how a link reads from its target's side, such as
+// "blocked by" for LinkBlocks.
+func InverseLinkLabel(linkType string) string {
+	switch linkType {
+	case LinkBlocks:
+		return "blocked by"
+	case LinkRelatesTo:
+		return "relates to"
+	case LinkDuplicates:
+		return "duplicated by"
+	case LinkSupersedes:
+		return "superseded by"
+	}
+	return linkType + " (from)"
+}
+
+// AddLink adds a link to target, returning false if the item already has
+// that exact link.
+func (c *ContextItem) AddLink(linkType, target string) bool {
+	for _, l := range c.Links {
+		if l.Type == linkType && l.Target == target {
+			return false
+		}
+	}
+	c.Links = append(c.Links, Link{Type: linkType, Target: target})
+	return true
+}
+
+// RemoveLinks removes the links to target, only those of linkType if it is
+// not empty, and returns the number of links removed.
+func (c *ContextItem) RemoveLinks(linkType, target string) int {
+	var kept []Link
+	for _, l := range c.Links {
+		if l.Target == target && (linkType == "" || l.Type == linkType) {
+			continue
+		}
+		kept = append(kept, l)
+	}
+	removed := len(c.Links) - len(kept)
+	c.Links = kept
+	return removed
+}
+
+// Blockers returns, for every item blocked through a LinkBlocks link, the IDs
+// of the open items blocking it, keyed by the blocked item's ID. Links from
+// closed or archived items no longer block anything.
+func Blockers(items []ContextItem) map[string][]string {
+	blockers := make(map[string][]string)
+	for _, item := range items {
+		if item.CompletedAt != nil || item.Archived {
+			continue
+		}
+		for _, l := range item.Links {
+			if l.Type == LinkBlocks {
+				blockers[l.Target] = append(blockers[l.Target], item.ID)
+			}
+		}
+	}
+	return blockers
+}
+
+// Superseded returns the IDs of the items that another item supersedes,
+// mapped to the ID of the superseding item. Links from archived items and
+// from items closed as wontfix are ignored.
+func Superseded(items []ContextItem) map[string]string {
+	superseded := make(map[string]string)
+	for _, item := range items {
+		if item.Archived || item.EffectiveState() == StateWontfix {
+			continue
+		}
+		for _, l := range item.Links {
+			if l.Type == LinkSupersedes {
+				superseded[l.Target] = item.ID
+			}
+		}
+	}
+	return superseded
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContextItemLinks(t *testing.T) {
+	item := ContextItem{ID: "a"}
+	if !item.AddLink(LinkBlocks, "b") || !item.AddLink(LinkRelatesTo, "b") || !item.AddLink(LinkBlocks, "c") {
+		t.Fatal("Expected new links to be added")
+	}
+	if item.AddLink(LinkBlocks, "b") {
+		t.Error("Expected duplicate link to be rejected")
+	}
+
+	if n := item.RemoveLinks(LinkRelatesTo, "b"); n != 1 {
+		t.Errorf("Expected 1 link removed, got %d", n)
+	}
+	if n := item.RemoveLinks("", "b"); n != 1 {
+		t.Errorf("Expected 1 link removed, got %d", n)
+	}
+	if len(item.Links) != 1 || item.Links[0].Target != "c" {
+		t.Errorf("Unexpected remaining links: %v", item.Links)
+	}
+	if n := item.RemoveLinks("", "c"); n != 1 || item.Links != nil {
+		t.Errorf("Expected no links left, got %v", item.Links)
+	}
+}
+
+func TestBlockersAndSuperseded(t *testing.T) {
+	now := time.Now()
+	items := []ContextItem{
+		{ID: "open", Links: []Link{{LinkBlocks, "x"}, {LinkSupersedes, "old"}}},
+		{ID: "done", CompletedAt: &now, Links: []Link{{LinkBlocks, "y"}, {LinkSupersedes, "older"}}},
+		{ID: "dropped", State: StateWontfix, CompletedAt: &now, Links: []Link{{LinkSupersedes, "kept"}}},
+		{ID: "related", Links: []Link{{LinkRelatesTo, "x"}}},
+	}
+
+	blockers := Blockers(items)
+	if len(blockers) != 1 || len(blockers["x"]) != 1 || blockers["x"][0] != "open" {
+		t.Errorf("Expected only x to be blocked by open, got %v", blockers)
+	}
+
+	superseded := Superseded(items)
+	if superseded["old"] != "open" || superseded["older"] != "done" {
+		t.Errorf("Unexpected superseded items: %v", superseded)
+	}
+	if _, ok := superseded["kept"]; ok {
+		t.Error("Expected links from wontfix items to be ignored")
+	}
+}
+
+func TestInverseLinkLabel(t *testing.T) {
+	if got := InverseLinkLabel(LinkBlocks); got != "blocked by" {
+		t.Errorf("Expected 'blocked by', got %q", got)
+	}
+	if got := InverseLinkLabel(LinkSupersedes); got != "superseded by" {
+		t.Errorf("Expected 'superseded by', got %q", got)
+	}
+}
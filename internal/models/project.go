@@ -0,0 +1,53 @@
+// Package models provides data structures for ContextKeeper items.
+//
+// This package contains the core domain models used throughout the application,
+// including the ContextItem struct which represents a single context entry.
+package models
+
+import "strings"
+
+// Project names form a hierarchy separated by slashes: "platform/api/auth"
+// is a subproject of "platform/api", which is one of "platform". Selecting
+// a project selects its subprojects too.
+
+// ProjectSeparator separates the parts of a hierarchical project name.
+const ProjectSeparator = "/"
+
+// InProject reports whether project is name or one of its subprojects, so
+// that "platform/api" is in "platform" but "platform-v2" is not.
+func InProject(project, name string) bool {
+	return project == name || strings.HasPrefix(project, name+ProjectSeparator)
+}
+
+// RenameProject returns project with the project old replaced by new, also
+// in subprojects, and reports whether project is in old.
+func RenameProject(project, old, new string) (string, bool) {
+	if !InProject(project, old) {
+		return project, false
+	}
+	return new + project[len(old):], true
+}
+
+// ParentProject returns the project name is a subproject of, or "" if it
+// is a top-level project.
+func ParentProject(name string) string {
+	if i := strings.LastIndex(name, ProjectSeparator); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// ProjectKey normalizes a project name for spotting near duplicates such as
+// "webapp", "web-app" and "Web_App": it is lowercased, without separators
+// other than slashes.
+func ProjectKey(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch r {
+		case '-', '_', '.', ' ':
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+package models
+
+import "testing"
+
+func TestInProject(t *testing.T) {
+	tests := []struct {
+		project, name string
+		want          bool
+	}{
+		{"platform", "platform", true},
+		{"platform/api", "platform", true},
+		{"platform/api/auth", "platform/api", true},
+		{"platform-v2", "platform", false},
+		{"platform", "platform/api", false},
+		{"", "platform", false},
+	}
+	for _, tt := range tests {
+		if got := InProject(tt.project, tt.name); got != tt.want {
+			t.Errorf("InProject(%q, %q) = %v, want %v", tt.project, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRenameProject(t *testing.T) {
+	tests := []struct {
+		project, old, new string
+		want              string
+		ok                bool
+	}{
+		{"webapp", "webapp", "web-app", "web-app", true},
+		{"platform/api/auth", "platform/api", "services/api", "services/api/auth", true},
+		{"platform/api", "platform", "core", "core/api", true},
+		{"platform-v2", "platform", "core", "platform-v2", false},
+	}
+	for _, tt := range tests {
+		got, ok := RenameProject(tt.project, tt.old, tt.new)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("RenameProject(%q, %q, %q) = %q, %v, want %q, %v", tt.project, tt.old, tt.new, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestProjectKey(t *testing.T) {
+	if ProjectKey("webapp") != ProjectKey("Web-App") || ProjectKey("web_app") != ProjectKey("web.app") {
+		t.Error("Expected spellings of webapp to share a key")
+	}
+	if ProjectKey("web/app") == ProjectKey("webapp") {
+		t.Error("Expected subprojects to keep their own key")
+	}
+	if got := ParentProject("platform/api/auth"); got != "platform/api" {
+		t.Errorf("ParentProject = %q, want platform/api", got)
+	}
+	if got := ParentProject("platform"); got != "" {
+		t.Errorf("ParentProject = %q, want empty", got)
+	}
+}
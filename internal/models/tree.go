@@ -0,0 +1,65 @@
+// Package models provides data structures for ContextKeeper items.
+//
+// This package contains the core domain models used throughout the application,
+// including the ContextItem struct which represents a single context entry.
+package models
+
+// Progress is the completion rollup of an item's subtasks.
+type Progress struct {
+	// Done is the number of subtasks that are done
+	Done int `json:"done"`
+
+	// Total is the number of subtasks, not counting those closed as wontfix
+	Total int `json:"total"`
+}
+
+// SubtaskProgress returns the rollup of the direct subtasks of every parent
+// in items, keyed by the parent's ID. Items without subtasks have no entry.
+// Archived subtasks and subtasks closed as wontfix are not counted.
+func SubtaskProgress(items []ContextItem) map[string]Progress {
+	progress := make(map[string]Progress)
+	for _, item := range items {
+		if item.ParentID == "" || item.Archived {
+			continue
+		}
+		state := item.EffectiveState()
+		if state == StateWontfix {
+			continue
+		}
+		p := progress[item.ParentID]
+		p.Total++
+		if state == StateDone {
+			p.Done++
+		}
+		progress[item.ParentID] = p
+	}
+	return progress
+}
+
+// Descendants returns the subtasks of the item with the given ID, their
+// subtasks and so on, in depth-first order. Each item is returned at most
+// once, even if the parent links form a cycle.
+func Descendants(items []ContextItem, id string) []ContextItem {
+	children := make(map[string][]ContextItem)
+	for _, item := range items {
+		if item.ParentID != "" {
+			children[item.ParentID] = append(children[item.ParentID], item)
+		}
+	}
+
+	var out []ContextItem
+	seen := map[string]bool{id: true}
+	var walk func(parent string)
+	walk = func(parent string) {
+		for _, child := range children[parent] {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			out = append(out, child)
+			walk(child.ID)
+		}
+	}
+	walk(id)
+	return out
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubtaskProgress(t *testing.T) {
+	now := time.Now()
+	items := []ContextItem{
+		{ID: "parent"},
+		{ID: "a", ParentID: "parent", CompletedAt: &now},
+		{ID: "b", ParentID: "parent"},
+		{ID: "c", ParentID: "parent", State: StateWontfix, CompletedAt: &now},
+		{ID: "d", ParentID: "parent", Archived: true},
+		{ID: "e", ParentID: "b", State: StateInProgress},
+	}
+
+	progress := SubtaskProgress(items)
+	if got := progress["parent"]; got != (Progress{Done: 1, Total: 2}) {
+		t.Errorf("Expected 1/2 for parent, got %+v", got)
+	}
+	if got := progress["b"]; got != (Progress{Done: 0, Total: 1}) {
+		t.Errorf("Expected 0/1 for b, got %+v", got)
+	}
+	if _, ok := progress["a"]; ok {
+		t.Error("Expected no progress for an item without subtasks")
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	items := []ContextItem{
+		{ID: "root"},
+		{ID: "a", ParentID: "root"},
+		{ID: "a1", ParentID: "a"},
+		{ID: "b", ParentID: "root"},
+		{ID: "other"},
+		// A cycle must not loop forever
+		{ID: "x", ParentID: "y"},
+		{ID: "y", ParentID: "x"},
+	}
+
+	ids := func(items []ContextItem) string {
+		var s string
+		for _, item := range items {
+			s += item.ID + " "
+		}
+		return s
+	}
+	if got := ids(Descendants(items, "root")); got != "a a1 b " {
+		t.Errorf("Expected depth-first descendants, got %q", got)
+	}
+	if got := ids(Descendants(items, "x")); got != "y " {
+		t.Errorf("Expected cycle to stop, got %q", got)
+	}
+	if got := Descendants(items, "other"); len(got) != 0 {
+		t.Errorf("Expected no descendants, got %v", got)
+	}
+}
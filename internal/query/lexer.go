@@ -0,0 +1,143 @@
+// Package query implements the filter language shared by ck list, ck search
+// and ck sync.
+//
+// A query is a sequence of terms combined with AND (implicit), OR, negation
+// and parentheses:
+//
+//	project:api tag:bug -tag:wontfix created:>2026-01-01 status:open "rate limit"
+//	(tag:bug OR tag:security) NOT project:legacy
+//
+// Field terms have the form field:value or field:<op>value, where op is one of
+// >, >=, <, <= or = for date fields (created and due). Dates may be absolute
+// (2026-01-31) or relative to today (today, -7d, +2w, friday). Bare words
+// and quoted phrases match item content and tags case-insensitively. A word
+// of the form @name refers to a saved view (see ParseWithViews). Parse turns
+// a query string into a Node tree that is evaluated against items with
+// Node.Match.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tokenKind identifies the type of a lexical token.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokLParen
+	tokRParen
+	tokOr
+	tokAnd
+	tokNot
+)
+
+// token is a single lexical element of a query.
+type token struct {
+	kind tokenKind
+	text string // Word or phrase text; for words this may include "field:value"
+	pos  int    // Character offset of the token in the query, for error messages
+}
+
+// ParseError describes a syntax error in a query.
+type ParseError struct {
+	Pos int    // Character offset in the query where the error was detected
+	Msg string // Description of the problem
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Pos+1, e.Msg)
+}
+
+// lex splits a query string into tokens.
+//
+// Quotes may appear at the start of a token ("rate limit") or after a field
+// separator (project:"my app"); the quoted text is kept as a single token.
+// A leading "-" is reported as a tokNot so that -tag:x and NOT tag:x are
+// equivalent.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	i := 0
+
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')' &&
+			(i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			tokens = append(tokens, token{kind: tokNot, text: "-", pos: i})
+			i++
+		case r == '"':
+			text, next, err := readQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokPhrase, text: text, pos: i})
+			i = next
+		default:
+			start := i
+			var sb strings.Builder
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				if runes[i] == '"' && i > start && runes[i-1] == ':' {
+					text, next, err := readQuoted(runes, i)
+					if err != nil {
+						return nil, err
+					}
+					sb.WriteString(text)
+					i = next
+					continue
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			word := sb.String()
+			switch word {
+			case "OR", "|":
+				tokens = append(tokens, token{kind: tokOr, text: word, pos: start})
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, text: word, pos: start})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, text: word, pos: start})
+			default:
+				tokens = append(tokens, token{kind: tokWord, text: word, pos: start})
+			}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// readQuoted reads a double-quoted string starting at runes[start] and
+// returns its contents and the index just past the closing quote.
+// A backslash escapes the next character.
+func readQuoted(runes []rune, start int) (string, int, error) {
+	var sb strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				sb.WriteRune(runes[i])
+			}
+		case '"':
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return "", 0, &ParseError{Pos: start, Msg: "unterminated quoted string"}
+}
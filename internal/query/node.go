@@ -0,0 +1,378 @@
+// Package query implements the filter language shared by ck list, ck search
+// and ck sync. See lexer.go for an overview of the syntax.
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// Node is a node of a parsed query.
+type Node interface {
+	// Match reports whether the item satisfies this node.
+	Match(item models.ContextItem) bool
+
+	// String returns a canonical text form of the node.
+	String() string
+}
+
+// And matches items that satisfy all of its children.
+type And struct {
+	Children []Node
+}
+
+// Or matches items that satisfy at least one of its children.
+type Or struct {
+	Children []Node
+}
+
+// Not matches items that don't satisfy its child.
+type Not struct {
+	Child Node
+}
+
+// Text matches items whose content or tags contain Value, case-insensitively.
+type Text struct {
+	Value  string
+	Phrase bool // Value came from a quoted phrase
+}
+
+// Field matches items by a named attribute, e.g. tag:bug or created:>2026-01-01.
+type Field struct {
+	Name  string // Canonical field name, one of the Field* constants
+	Op    string // Comparison operator: "=", ">", ">=", "<" or "<="
+	Value string // Value as written in the query
+
+	date    time.Time // Parsed value for date fields
+	dateDay bool      // Date was given without a time, compare whole days
+}
+
+// Canonical field names.
+const (
+	FieldProject  = "project"
+	FieldTag      = "tag"
+	FieldStatus   = "status"
+	FieldCreated  = "created"
+	FieldPriority = "priority"
+	FieldDue      = "due"
+	FieldBranch   = "branch"
+)
+
+// fieldAliases maps accepted field spellings to canonical field names.
+var fieldAliases = map[string]string{
+	"project":  FieldProject,
+	"p":        FieldProject,
+	"tag":      FieldTag,
+	"tags":     FieldTag,
+	"t":        FieldTag,
+	"status":   FieldStatus,
+	"is":       FieldStatus,
+	"created":  FieldCreated,
+	"priority": FieldPriority,
+	"prio":     FieldPriority,
+	"due":      FieldDue,
+	"branch":   FieldBranch,
+}
+
+// Status values accepted by the status field. Besides the workflow states of
+// models.ContextItem, open matches todo, in-progress and blocked items and
+// closed matches done and wontfix items.
+const (
+	StatusOpen       = "open"
+	StatusClosed     = "closed"
+	StatusTodo       = models.StateTodo
+	StatusInProgress = models.StateInProgress
+	StatusBlocked    = models.StateBlocked
+	StatusDone       = models.StateDone
+	StatusWontfix    = models.StateWontfix
+	StatusAny        = "any" // Matches every item; lifts the default "open only" filter
+)
+
+// statusAliases maps accepted status spellings to canonical status values.
+var statusAliases = map[string]string{
+	"open":        StatusOpen,
+	"active":      StatusOpen,
+	"closed":      StatusClosed,
+	"todo":        StatusTodo,
+	"in-progress": StatusInProgress,
+	"inprogress":  StatusInProgress,
+	"started":     StatusInProgress,
+	"wip":         StatusInProgress,
+	"blocked":     StatusBlocked,
+	"done":        StatusDone,
+	"completed":   StatusDone,
+	"wontfix":     StatusWontfix,
+	"won't-fix":   StatusWontfix,
+	"cancelled":   StatusWontfix,
+	"any":         StatusAny,
+	"all":         StatusAny,
+}
+
+// Match implements Node.
+func (n *And) Match(item models.ContextItem) bool {
+	for _, child := range n.Children {
+		if !child.Match(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// String implements Node.
+func (n *And) String() string {
+	parts := make([]string, len(n.Children))
+	for i, child := range n.Children {
+		parts[i] = child.String()
+	}
+	return "(" + strings.Join(parts, " AND ") + ")"
+}
+
+// Match implements Node.
+func (n *Or) Match(item models.ContextItem) bool {
+	for _, child := range n.Children {
+		if child.Match(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// String implements Node.
+func (n *Or) String() string {
+	parts := make([]string, len(n.Children))
+	for i, child := range n.Children {
+		parts[i] = child.String()
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+// Match implements Node.
+func (n *Not) Match(item models.ContextItem) bool {
+	return !n.Child.Match(item)
+}
+
+// String implements Node.
+func (n *Not) String() string {
+	return "NOT " + n.Child.String()
+}
+
+// Match implements Node.
+func (n *Text) Match(item models.ContextItem) bool {
+	value := strings.ToLower(n.Value)
+	if strings.Contains(strings.ToLower(item.Content), value) {
+		return true
+	}
+	for _, tag := range item.Tags {
+		if strings.Contains(strings.ToLower(tag), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// String implements Node.
+func (n *Text) String() string {
+	if n.Phrase {
+		return fmt.Sprintf("%q", n.Value)
+	}
+	return n.Value
+}
+
+// Match implements Node.
+func (n *Field) Match(item models.ContextItem) bool {
+	switch n.Name {
+	case FieldProject:
+		return models.InProject(item.Project, n.Value)
+	case FieldTag:
+		for _, tag := range item.Tags {
+			if tag == n.Value {
+				return true
+			}
+		}
+		return false
+	case FieldStatus:
+		switch n.Value {
+		case StatusAny:
+			return true
+		case StatusOpen:
+			return item.CompletedAt == nil
+		case StatusClosed:
+			return item.CompletedAt != nil
+		}
+		return item.EffectiveState() == n.Value
+	case FieldCreated:
+		return n.compareDate(item.CreatedAt)
+	case FieldPriority:
+		return item.Priority == n.Value
+	case FieldDue:
+		if item.DueAt == nil {
+			return false
+		}
+		return n.compareDate(*item.DueAt)
+	case FieldBranch:
+		return item.Branch == n.Value
+	}
+	return false
+}
+
+// String implements Node.
+func (n *Field) String() string {
+	op := n.Op
+	if op == "=" {
+		op = ""
+	}
+	value := n.Value
+	if strings.ContainsAny(value, " \t()\"") {
+		value = fmt.Sprintf("%q", value)
+	}
+	return n.Name + ":" + op + value
+}
+
+// compareDate compares t against the field's date using the field's operator.
+// Dates written without a time compare whole days in the local time zone, so
+// created:2026-01-01 matches any item created that day and created:>2026-01-01
+// matches items created from the next day onwards.
+func (n *Field) compareDate(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+
+	start, end := n.date, n.date
+	if n.dateDay {
+		end = start.AddDate(0, 0, 1)
+	}
+	t = t.In(start.Location())
+
+	switch n.Op {
+	case ">":
+		if n.dateDay {
+			return !t.Before(end)
+		}
+		return t.After(start)
+	case ">=":
+		return !t.Before(start)
+	case "<":
+		return t.Before(start)
+	case "<=":
+		if n.dateDay {
+			return t.Before(end)
+		}
+		return !t.After(start)
+	default:
+		if n.dateDay {
+			return !t.Before(start) && t.Before(end)
+		}
+		return t.Equal(start)
+	}
+}
+
+// Filter returns the items that match node. A nil node matches every item.
+func Filter(items []models.ContextItem, node Node) []models.ContextItem {
+	if node == nil {
+		return items
+	}
+	filtered := make([]models.ContextItem, 0, len(items))
+	for _, item := range items {
+		if node.Match(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// TextMatcher decides whether an item matches a text term. It lets callers
+// such as ranked search replace the default substring matching.
+type TextMatcher func(t *Text, item models.ContextItem) bool
+
+// MatchWith evaluates node against item like Node.Match, but uses match for
+// text terms.
+func MatchWith(node Node, item models.ContextItem, match TextMatcher) bool {
+	switch n := node.(type) {
+	case *And:
+		for _, child := range n.Children {
+			if !MatchWith(child, item, match) {
+				return false
+			}
+		}
+		return true
+	case *Or:
+		for _, child := range n.Children {
+			if MatchWith(child, item, match) {
+				return true
+			}
+		}
+		return false
+	case *Not:
+		return !MatchWith(n.Child, item, match)
+	case *Text:
+		return match(n, item)
+	case nil:
+		return true
+	}
+	return node.Match(item)
+}
+
+// FilterWith returns the items that match node, using match for text terms.
+func FilterWith(items []models.ContextItem, node Node, match TextMatcher) []models.ContextItem {
+	if node == nil {
+		return items
+	}
+	filtered := make([]models.ContextItem, 0, len(items))
+	for _, item := range items {
+		if MatchWith(node, item, match) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// HasField reports whether the query refers to the named field anywhere,
+// including inside negations. Commands use this to decide whether a default
+// filter (such as hiding completed items) should still be applied.
+func HasField(node Node, name string) bool {
+	switch n := node.(type) {
+	case *And:
+		for _, child := range n.Children {
+			if HasField(child, name) {
+				return true
+			}
+		}
+	case *Or:
+		for _, child := range n.Children {
+			if HasField(child, name) {
+				return true
+			}
+		}
+	case *Not:
+		return HasField(n.Child, name)
+	case *Field:
+		return n.Name == name
+	}
+	return false
+}
+
+// TextTerms returns the text terms of the query that are not negated.
+// Search uses them to rank and highlight results.
+func TextTerms(node Node) []*Text {
+	var terms []*Text
+	var walk func(Node)
+	walk = func(node Node) {
+		switch n := node.(type) {
+		case *And:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *Or:
+			for _, child := range n.Children {
+				walk(child)
+			}
+		case *Text:
+			terms = append(terms, n)
+		}
+	}
+	walk(node)
+	return terms
+}
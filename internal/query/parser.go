@@ -0,0 +1,292 @@
+// Package query implements the filter language shared by ck list, ck search
+// and ck sync. See lexer.go for an overview of the syntax.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+)
+
+// parser is a recursive descent parser over the token stream of a query.
+//
+// Grammar, from lowest to highest precedence:
+//
+//	query   = or
+//	or      = and { "OR" and }
+//	and     = unary { ["AND"] unary }
+//	unary   = ( "-" | "NOT" ) unary | primary
+//	primary = "(" or ")" | view | term
+//	view    = "@" name
+type parser struct {
+	tokens []token
+	pos    int
+
+	views     map[string]string // Saved view queries by name, see ParseWithViews
+	expanding map[string]bool   // Views being expanded, to detect cycles
+}
+
+// Parse parses a query string into a Node.
+//
+// An empty or blank query returns a nil Node and no error; Filter treats a
+// nil Node as matching every item. Syntax errors are returned as *ParseError.
+func Parse(input string) (Node, error) {
+	return ParseWithViews(input, nil)
+}
+
+// ParseWithViews parses a query that may refer to saved views.
+//
+// A word of the form @name is replaced by the query saved as views[name],
+// which may itself refer to other views. Referring to an unknown view, or to
+// a view that refers back to itself, is an error. With a nil map Parse and
+// ParseWithViews are equivalent, and @name words are matched as plain text.
+func ParseWithViews(input string, views map[string]string) (Node, error) {
+	return parseQuery(input, views, make(map[string]bool))
+}
+
+// parseQuery parses input with the given views, some of which may already be
+// in the middle of being expanded.
+func parseQuery(input string, views map[string]string, expanding map[string]bool) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, nil
+	}
+
+	p := &parser{tokens: tokens, views: views, expanding: expanding}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, &ParseError{Pos: tok.pos, Msg: "unexpected \")\" without matching \"(\""}
+		}
+		return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return node, nil
+}
+
+// peek returns the current token without consuming it.
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next consumes and returns the current token.
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses a sequence of AND groups separated by OR.
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []Node{first}
+	for p.peek().kind == tokOr {
+		p.next()
+		child, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &Or{Children: children}, nil
+}
+
+// parseAnd parses a sequence of terms joined by AND or juxtaposition.
+func (p *parser) parseAnd() (Node, error) {
+	var children []Node
+	for {
+		tok := p.peek()
+		switch tok.kind {
+		case tokEOF, tokRParen, tokOr:
+			if len(children) == 0 {
+				return nil, &ParseError{Pos: tok.pos, Msg: expectedTermMsg(tok)}
+			}
+			if len(children) == 1 {
+				return children[0], nil
+			}
+			return &And{Children: children}, nil
+		case tokAnd:
+			if len(children) == 0 {
+				return nil, &ParseError{Pos: tok.pos, Msg: "AND must follow a term"}
+			}
+			p.next()
+			if next := p.peek(); next.kind == tokEOF || next.kind == tokRParen || next.kind == tokOr || next.kind == tokAnd {
+				return nil, &ParseError{Pos: next.pos, Msg: "AND must be followed by a term"}
+			}
+		default:
+			child, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		}
+	}
+}
+
+// parseUnary parses an optionally negated primary expression.
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind == tokNot {
+		tok := p.next()
+		switch next := p.peek(); next.kind {
+		case tokEOF, tokRParen, tokOr, tokAnd:
+			return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("%q must be followed by a term", tok.text)}
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a parenthesized group or a single term.
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, &ParseError{Pos: tok.pos, Msg: "missing \")\" for this \"(\""}
+		}
+		return node, nil
+	case tokPhrase:
+		return &Text{Value: tok.text, Phrase: true}, nil
+	case tokWord:
+		if p.views != nil && len(tok.text) > 1 && tok.text[0] == '@' {
+			return p.parseView(tok)
+		}
+		return parseTerm(tok)
+	}
+	return nil, &ParseError{Pos: tok.pos, Msg: expectedTermMsg(tok)}
+}
+
+// parseView expands a reference to a saved view. A view with an empty query
+// matches every item.
+func (p *parser) parseView(tok token) (Node, error) {
+	name := tok.text[1:]
+	input, ok := p.views[name]
+	if !ok {
+		return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("unknown view %q", name)}
+	}
+	if p.expanding[name] {
+		return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("view %q refers to itself", name)}
+	}
+
+	p.expanding[name] = true
+	node, err := parseQuery(input, p.views, p.expanding)
+	delete(p.expanding, name)
+	if err != nil {
+		var perr *ParseError
+		if errors.As(err, &perr) {
+			return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("in view %q: %s", name, perr.Msg)}
+		}
+		return nil, err
+	}
+	if node == nil {
+		return &And{}, nil
+	}
+	return node, nil
+}
+
+// expectedTermMsg describes a missing term at tok.
+func expectedTermMsg(tok token) string {
+	if tok.kind == tokEOF {
+		return "expected a term at end of query"
+	}
+	return fmt.Sprintf("expected a term before %q", tok.text)
+}
+
+// parseTerm turns a word token into a Field or Text node.
+//
+// Words of the form name:value where name is a known field become Field
+// nodes. Any other word, including ones with an unknown prefix such as
+// "http://example.com", is matched as plain text.
+func parseTerm(tok token) (Node, error) {
+	name, value, found := strings.Cut(tok.text, ":")
+	if !found {
+		return &Text{Value: tok.text}, nil
+	}
+	field, ok := fieldAliases[strings.ToLower(name)]
+	if !ok {
+		return &Text{Value: tok.text}, nil
+	}
+
+	op := "="
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, candidate) {
+			op = candidate
+			value = value[len(candidate):]
+			break
+		}
+	}
+	if value == "" {
+		return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("missing value for %s:", name)}
+	}
+
+	node := &Field{Name: field, Op: op, Value: value}
+	if op != "=" && !isDateField(field) {
+		return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("operator %q is only supported for date fields", op)}
+	}
+
+	switch field {
+	case FieldStatus:
+		status, ok := statusAliases[strings.ToLower(value)]
+		if !ok {
+			return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("unknown status %q (use open, closed, todo, in-progress, blocked, done, wontfix or any)", value)}
+		}
+		node.Value = status
+	case FieldPriority:
+		priority, err := utils.ParsePriority(value)
+		if err != nil || priority == "" {
+			return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("unknown priority %q (use high, medium or low)", value)}
+		}
+		node.Value = priority
+	case FieldCreated, FieldDue:
+		date, day, err := parseDate(value)
+		if err != nil {
+			return nil, &ParseError{Pos: tok.pos, Msg: err.Error()}
+		}
+		node.date, node.dateDay = date, day
+	}
+	return node, nil
+}
+
+// isDateField reports whether field compares dates.
+func isDateField(field string) bool {
+	return field == FieldCreated || field == FieldDue
+}
+
+// parseDate parses a date value. Plain dates (2026-01-31) and the relative
+// forms accepted by utils.ParseDue (today, +7d, friday, ...) are interpreted
+// in the local time zone and compared by day; RFC 3339 timestamps are exact.
+func parseDate(value string) (time.Time, bool, error) {
+	if t, err := utils.ParseDue(value, time.Now()); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date %q (use YYYY-MM-DD, today, +7d, -2w, a weekday or RFC 3339)", value)
+}
@@ -0,0 +1,200 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+func testItems() []models.ContextItem {
+	done := time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local)
+	due1 := time.Date(2026, 1, 15, 0, 0, 0, 0, time.Local)
+	due3 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+	return []models.ContextItem{
+		{ID: "1", Content: "Add rate limit to API", Project: "api", Tags: []string{"bug"}, CreatedAt: time.Date(2026, 1, 10, 9, 0, 0, 0, time.Local), Priority: "high", DueAt: &due1},
+		{ID: "2", Content: "Ignore this one", Project: "api", Tags: []string{"bug", "wontfix"}, CreatedAt: time.Date(2026, 1, 12, 9, 0, 0, 0, time.Local), State: models.StateInProgress, Branch: "feature/rate-limit"},
+		{ID: "3", Content: "Security review", Project: "web", Tags: []string{"security"}, CreatedAt: time.Date(2025, 12, 1, 9, 0, 0, 0, time.Local), Priority: "low", DueAt: &due3},
+		{ID: "4", Content: "Old rate limit note", Project: "web", CreatedAt: time.Date(2026, 1, 1, 9, 0, 0, 0, time.Local), CompletedAt: &done},
+	}
+}
+
+func matchIDs(t *testing.T, q string) string {
+	t.Helper()
+	node, err := Parse(q)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", q, err)
+	}
+	ids := ""
+	for _, item := range Filter(testItems(), node) {
+		ids += item.ID
+	}
+	return ids
+}
+
+func TestParseAndMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "1234"},
+		{"project:api", "12"},
+		{"tag:bug -tag:wontfix", "1"},
+		{"tag:bug NOT tag:wontfix", "1"},
+		{"status:open", "123"},
+		{"is:done", "4"},
+		{`"rate limit"`, "14"},
+		{"RATE", "14"},
+		{"rate limit status:open", "1"},
+		{"tag:security OR project:api", "123"},
+		{"(tag:security OR tag:wontfix) project:web", "3"},
+		{"-(project:api OR project:web)", ""},
+		{"created:>2026-01-01", "12"},
+		{"created:>=2026-01-01", "124"},
+		{"created:<2026-01-01", "3"},
+		{"created:2026-01-12", "2"},
+		{"tag:bug AND project:api", "12"},
+		{`project:"api"`, "12"},
+		{"http://example.com", ""},
+		{"status:any project:web", "34"},
+		{"status:in-progress", "2"},
+		{"is:wip OR is:todo", "123"},
+		{"status:closed", "4"},
+		{"status:blocked", ""},
+		{"priority:high", "1"},
+		{"prio:low", "3"},
+		{"due:<2026-01-20", "1"},
+		{"due:2026-03-01", "3"},
+		{"due:>=today", ""},
+		{"branch:feature/rate-limit", "2"},
+		{"@mention", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := matchIDs(t, tt.query); got != tt.want {
+				t.Errorf("Query %q matched %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectHierarchy(t *testing.T) {
+	items := []models.ContextItem{
+		{ID: "1", Project: "platform"},
+		{ID: "2", Project: "platform/api"},
+		{ID: "3", Project: "platform/api/auth"},
+		{ID: "4", Project: "platform-v2"},
+	}
+	for q, want := range map[string]string{
+		"project:platform":      "123",
+		"project:platform/api":  "23",
+		"-project:platform/api": "14",
+	} {
+		node, err := Parse(q)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", q, err)
+		}
+		got := ""
+		for _, item := range Filter(items, node) {
+			got += item.ID
+		}
+		if got != want {
+			t.Errorf("Query %q matched %q, want %q", q, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"(tag:bug",
+		"tag:bug)",
+		"tag:bug OR",
+		"OR tag:bug",
+		"NOT",
+		"tag:",
+		"status:maybe",
+		"created:>yesterday-ish",
+		"project:>api",
+		`"unterminated`,
+		"()",
+	}
+
+	for _, q := range tests {
+		t.Run(q, func(t *testing.T) {
+			_, err := Parse(q)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse(%q) error = %v, want *ParseError", q, err)
+			}
+		})
+	}
+}
+
+func TestHasFieldAndTextTerms(t *testing.T) {
+	node, err := Parse(`auth -status:done (tag:bug OR "rate limit") -skip`)
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if !HasField(node, FieldStatus) {
+		t.Error("Expected query to reference status")
+	}
+	if HasField(node, FieldProject) {
+		t.Error("Expected query not to reference project")
+	}
+
+	terms := TextTerms(node)
+	if len(terms) != 2 || terms[0].Value != "auth" || terms[1].Value != "rate limit" {
+		t.Errorf("Unexpected text terms: %v", terms)
+	}
+}
+
+func TestParseWithViews(t *testing.T) {
+	views := map[string]string{
+		"api":    "project:api",
+		"triage": "@api tag:bug -tag:wontfix",
+		"all":    "",
+		"loop":   "@loop2",
+		"loop2":  "tag:bug @loop",
+		"broken": "(tag:bug",
+	}
+
+	ids := func(q string) string {
+		t.Helper()
+		node, err := ParseWithViews(q, views)
+		if err != nil {
+			t.Fatalf("ParseWithViews(%q) error: %v", q, err)
+		}
+		out := ""
+		for _, item := range Filter(testItems(), node) {
+			out += item.ID
+		}
+		return out
+	}
+
+	if got := ids("@triage"); got != "1" {
+		t.Errorf("@triage matched %q, want 1", got)
+	}
+	if got := ids("@api OR tag:security"); got != "123" {
+		t.Errorf("@api OR tag:security matched %q, want 123", got)
+	}
+	if got := ids("-@api"); got != "34" {
+		t.Errorf("-@api matched %q, want 34", got)
+	}
+	if got := ids("@all rate"); got != "14" {
+		t.Errorf("@all rate matched %q, want 14", got)
+	}
+
+	for q, want := range map[string]string{
+		"@missing": `unknown view "missing"`,
+		"@loop":    "refers to itself",
+		"@broken":  `in view "broken"`,
+	} {
+		_, err := ParseWithViews(q, views)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseWithViews(%q) error = %v, want %q", q, err, want)
+		}
+	}
+}
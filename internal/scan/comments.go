@@ -0,0 +1,136 @@
+// Package scan finds TODO-style marker comments in a source tree. See
+// scan.go for an overview.
+package scan
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// syntax describes the comments of a language.
+type syntax struct {
+	line   []string    // Line comment starts, e.g. "//"
+	block  [][2]string // Block comment start and end, e.g. "/*" and "*/"
+	quotes string      // String quote characters, comment starts inside strings are ignored
+}
+
+// Comment syntaxes shared by several languages.
+var (
+	cStyle    = syntax{line: []string{"//"}, block: [][2]string{{"/*", "*/"}}, quotes: "\"'`"}
+	hashStyle = syntax{line: []string{"#"}, quotes: "\"'"}
+	markup    = syntax{block: [][2]string{{"<!--", "-->"}}}
+)
+
+// syntaxByExt maps file extensions to comment syntaxes.
+var syntaxByExt = map[string]syntax{
+	".go": cStyle, ".js": cStyle, ".jsx": cStyle, ".mjs": cStyle, ".cjs": cStyle,
+	".ts": cStyle, ".tsx": cStyle, ".java": cStyle, ".kt": cStyle, ".kts": cStyle,
+	".scala": cStyle, ".c": cStyle, ".h": cStyle, ".cc": cStyle, ".cpp": cStyle,
+	".hpp": cStyle, ".cs": cStyle, ".swift": cStyle, ".dart": cStyle, ".proto": cStyle,
+	".groovy": cStyle, ".gradle": cStyle,
+	".rs":   {line: []string{"//"}, block: [][2]string{{"/*", "*/"}}, quotes: `"`},
+	".php":  {line: []string{"//", "#"}, block: [][2]string{{"/*", "*/"}}, quotes: "\"'"},
+	".css":  {block: [][2]string{{"/*", "*/"}}, quotes: "\"'"},
+	".scss": cStyle, ".less": cStyle,
+	".py": hashStyle, ".rb": hashStyle, ".sh": hashStyle, ".bash": hashStyle,
+	".zsh": hashStyle, ".fish": hashStyle, ".pl": hashStyle, ".r": hashStyle,
+	".yaml": hashStyle, ".yml": hashStyle, ".toml": hashStyle, ".tf": hashStyle,
+	".ex": hashStyle, ".exs": hashStyle, ".nim": hashStyle, ".cmake": hashStyle,
+	".sql":  {line: []string{"--"}, block: [][2]string{{"/*", "*/"}}, quotes: "'\""},
+	".lua":  {line: []string{"--"}, block: [][2]string{{"--[[", "]]"}}, quotes: "\"'"},
+	".hs":   {line: []string{"--"}, block: [][2]string{{"{-", "-}"}}, quotes: `"`},
+	".elm":  {line: []string{"--"}, block: [][2]string{{"{-", "-}"}}, quotes: `"`},
+	".clj":  {line: []string{";"}, quotes: `"`},
+	".el":   {line: []string{";"}, quotes: `"`},
+	".lisp": {line: []string{";"}, quotes: `"`},
+	".erl":  {line: []string{"%"}, quotes: `"`},
+	".tex":  {line: []string{"%"}},
+	".vim":  {line: []string{`"`}},
+	".html": markup, ".htm": markup, ".xml": markup, ".svg": markup,
+	".vue": {line: []string{"//"}, block: [][2]string{{"<!--", "-->"}, {"/*", "*/"}}, quotes: "\"'`"},
+	".md":  markup,
+}
+
+// syntaxByName maps file names without a telling extension to comment syntaxes.
+var syntaxByName = map[string]syntax{
+	"Makefile":    hashStyle,
+	"Dockerfile":  hashStyle,
+	"Rakefile":    hashStyle,
+	"Gemfile":     hashStyle,
+	"CMakeLists":  hashStyle,
+	"Jenkinsfile": cStyle,
+}
+
+// syntaxFor returns the comment syntax of the file at path, and false if the
+// file is not in a known language.
+func syntaxFor(path string) (syntax, bool) {
+	base := filepath.Base(path)
+	if s, ok := syntaxByName[strings.TrimSuffix(base, ".txt")]; ok {
+		return s, true
+	}
+	s, ok := syntaxByExt[strings.ToLower(filepath.Ext(base))]
+	return s, ok
+}
+
+// comment is the text of a comment on a single line.
+type comment struct {
+	line int // 1-based line number
+	text string
+}
+
+// comments returns the comment text on each line of a file. A block comment
+// spanning several lines yields one comment per line. Comment starts inside
+// string literals are skipped; strings are assumed not to span lines.
+func comments(lines []string, syn syntax) []comment {
+	var out []comment
+	blockEnd := "" // End of the block comment we are in, if any
+	for n, line := range lines {
+		var quote byte
+	scan:
+		for i := 0; i < len(line); {
+			if blockEnd != "" {
+				end := strings.Index(line[i:], blockEnd)
+				if end < 0 {
+					out = append(out, comment{n + 1, line[i:]})
+					break
+				}
+				out = append(out, comment{n + 1, line[i : i+end]})
+				i += end + len(blockEnd)
+				blockEnd = ""
+				continue
+			}
+
+			c := line[i]
+			if quote != 0 {
+				if c == '\\' {
+					i += 2
+					continue
+				}
+				if c == quote {
+					quote = 0
+				}
+				i++
+				continue
+			}
+
+			for _, b := range syn.block {
+				if strings.HasPrefix(line[i:], b[0]) {
+					blockEnd = b[1]
+					i += len(b[0])
+					continue scan
+				}
+			}
+			for _, start := range syn.line {
+				if strings.HasPrefix(line[i:], start) {
+					out = append(out, comment{n + 1, line[i+len(start):]})
+					break scan
+				}
+			}
+			if strings.IndexByte(syn.quotes, c) >= 0 {
+				quote = c
+			}
+			i++
+		}
+	}
+	return out
+}
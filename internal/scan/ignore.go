@@ -0,0 +1,171 @@
+// Package scan finds TODO-style marker comments in a source tree. See
+// scan.go for an overview.
+package scan
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a single pattern from a .gitignore file.
+type ignoreRule struct {
+	re      *regexp.Regexp // Matches paths relative to the .gitignore's directory
+	negate  bool           // The pattern started with "!"
+	dirOnly bool           // The pattern ended with "/"
+}
+
+// Matcher decides which paths are ignored according to the .gitignore files
+// in a tree. It supports the usual pattern syntax: "*", "?", "**", character
+// classes, negation with "!", patterns anchored with "/" and patterns
+// matching only directories with a trailing "/". The .gitignore of every
+// directory is read the first time a path below it is checked.
+type Matcher struct {
+	root  string
+	rules map[string][]ignoreRule // By directory, relative to root with "/"
+}
+
+// NewMatcher returns a Matcher for the tree at root.
+func NewMatcher(root string) *Matcher {
+	return &Matcher{root: root, rules: make(map[string][]ignoreRule)}
+}
+
+// Ignored reports whether the path rel, relative to the root and using "/"
+// as separator, is ignored. Rules in deeper .gitignore files take precedence,
+// and within a file later rules override earlier ones.
+//
+// A path inside an ignored directory is only reported as ignored if it
+// matches a pattern itself; callers walking the tree are expected to skip
+// ignored directories, as git does.
+func (m *Matcher) Ignored(rel string, isDir bool) bool {
+	ignored := false
+	dir := ""
+	for {
+		for _, r := range m.rulesFor(dir) {
+			if r.dirOnly && !isDir {
+				continue
+			}
+			sub := rel
+			if dir != "" {
+				sub = strings.TrimPrefix(rel, dir+"/")
+			}
+			if r.re.MatchString(sub) {
+				ignored = !r.negate
+			}
+		}
+
+		// Descend to the next directory on the way to rel
+		rest := strings.TrimPrefix(rel, dir)
+		rest = strings.TrimPrefix(rest, "/")
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			return ignored
+		}
+		dir = path.Join(dir, rest[:i])
+	}
+}
+
+// rulesFor returns the rules of the .gitignore in dir, reading it on first use.
+func (m *Matcher) rulesFor(dir string) []ignoreRule {
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+
+	var rules []ignoreRule
+	f, err := os.Open(filepath.Join(m.root, filepath.FromSlash(dir), ".gitignore"))
+	if err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if r, ok := parseIgnoreRule(scanner.Text()); ok {
+				rules = append(rules, r)
+			}
+		}
+		f.Close()
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// parseIgnoreRule parses a line of a .gitignore file. Blank lines and
+// comments yield no rule.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var r ignoreRule
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// A pattern with a slash other than at the end is relative to the
+	// directory of the .gitignore; otherwise it matches at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+// globToRegexp translates a gitignore glob to a regular expression.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
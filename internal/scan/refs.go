@@ -0,0 +1,96 @@
+// Package scan finds TODO-style marker comments in a source tree. See
+// scan.go for an overview.
+package scan
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/git"
+)
+
+// refPattern matches a reference to an item: "ck:" followed by the item's
+// ID or a prefix of at least four characters.
+var refPattern = regexp.MustCompile(`(?:^|[^\w])ck:([0-9a-fA-F][0-9a-fA-F-]{3,35})\b`)
+
+// Ref is a reference to an item, such as "see ck:5299c5", in a file of the
+// working tree or in a commit message.
+type Ref struct {
+	ID string // The referenced ID as written, usually a prefix
+
+	Path string // The file, relative to the root with "/" separators
+	Line int    // The 1-based line in the file
+
+	Commit *git.Commit // The commit whose message has the reference
+
+	Text string // The line, or the commit subject, without surrounding whitespace
+}
+
+// Location describes where the reference is: "path:line" or "commit abc1234".
+func (r Ref) Location() string {
+	if r.Commit != nil {
+		return "commit " + r.Commit.ShortHash()
+	}
+	return filepath.FromSlash(r.Path) + ":" + strconv.Itoa(r.Line)
+}
+
+// FindIDs returns the IDs referenced in text, lowercased, in order of
+// appearance.
+func FindIDs(text string) []string {
+	var ids []string
+	for _, m := range refPattern.FindAllStringSubmatch(text, -1) {
+		ids = append(ids, strings.ToLower(strings.TrimRight(m[1], "-")))
+	}
+	return ids
+}
+
+// TreeRefs returns the references in the text files under root, skipping
+// files ignored by .gitignore.
+func TreeRefs(root string) ([]Ref, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+	err = walk(root, root, NewMatcher(root), func(path, rel string) error {
+		lines, ok, err := readText(path)
+		if err != nil || !ok {
+			return err
+		}
+		for n, line := range lines {
+			if !strings.Contains(line, "ck:") {
+				continue
+			}
+			for _, id := range FindIDs(line) {
+				refs = append(refs, Ref{ID: id, Path: rel, Line: n + 1, Text: strings.TrimSpace(line)})
+			}
+		}
+		return nil
+	})
+	return refs, err
+}
+
+// HistoryRefs returns the references in the messages of all commits of the
+// git repository at root, newest first. Outside of a git repository there
+// are none.
+func HistoryRefs(root string) ([]Ref, error) {
+	if !git.IsRepo(root) {
+		return nil, nil
+	}
+	commits, err := git.Log(root, "--all", "--grep=ck:")
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Ref
+	for i := range commits {
+		c := &commits[i]
+		for _, id := range FindIDs(c.Message) {
+			refs = append(refs, Ref{ID: id, Commit: c, Text: c.Subject()})
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,55 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindIDs(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"// see ck:5299c5", []string{"5299c5"}},
+		{"refs ck:5299C5AB, ck:0c9d88", []string{"5299c5ab", "0c9d88"}},
+		{"ck:5299c5ab-1234-4abc-8def-0123456789ab", []string{"5299c5ab-1234-4abc-8def-0123456789ab"}},
+		{"ck:abc is too short", nil},
+		{"tick:5299c5 is not a reference", nil},
+		{"ck:xyz123 is not hex", nil},
+	}
+	for _, tt := range tests {
+		if got := FindIDs(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FindIDs(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTreeRefs(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":     "build/\n",
+		"main.go":        "package main\n\n// see ck:5299c5\nfunc main() {}\n",
+		"docs/design.md": "Decided in ck:0c9d88.\n",
+		"build/out.go":   "// ck:5299c5\n",
+	})
+
+	refs, err := TreeRefs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type location struct {
+		path string
+		line int
+	}
+	got := make(map[location]string)
+	for _, r := range refs {
+		got[location{r.Path, r.Line}] = r.ID
+	}
+	want := map[location]string{
+		{"docs/design.md", 1}: "0c9d88",
+		{"main.go", 3}:        "5299c5",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TreeRefs() = %v, want %v", got, want)
+	}
+}
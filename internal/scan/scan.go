@@ -0,0 +1,411 @@
+// Package scan finds TODO-style marker comments in a source tree and turns
+// them into context items.
+//
+// Scan walks the tree, skipping files ignored by .gitignore, and looks for
+// markers such as TODO, FIXME and HACK in the comments of the languages
+// listed in comments.go. Each finding carries a key, a hash of its file,
+// marker and text, that identifies it across scans. Reconcile compares the
+// findings with the items imported by earlier scans: new findings become new
+// items, moved or reworded ones update their item, and items whose comment
+// is gone are marked done.
+//
+// The package also finds references to items, written as "ck:" followed by
+// an ID prefix, in the working tree and in commit messages; see refs.go.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/anchor"
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/utils"
+)
+
+// DefaultMarkers are the markers looked for when none are configured.
+var DefaultMarkers = []string{"TODO", "FIXME", "HACK", "XXX"}
+
+// maxFileSize is the size above which files are not scanned.
+const maxFileSize = 1 << 20
+
+// skippedDirs are never scanned, whether ignored or not.
+var skippedDirs = map[string]bool{".git": true, ".hg": true, ".svn": true, ".contextkeeper": true}
+
+// Reasons recorded with the state changes made by Reconcile.
+const (
+	ReasonRemoved  = "comment removed from code"
+	ReasonRestored = "comment found in code again"
+)
+
+// Finding is a marker comment found in a file.
+type Finding struct {
+	Marker string        // The marker, e.g. "TODO"
+	Text   string        // The comment text after the marker
+	Anchor models.Anchor // The location of the comment
+	Key    string        // Identifies the finding across scans
+}
+
+// Result is the outcome of a scan.
+type Result struct {
+	Findings []Finding
+	Files    int      // Number of files scanned
+	Scope    []string // Scanned paths relative to the root, "" for the root
+	Markers  []string // The markers looked for
+}
+
+// Scan looks for marker comments in paths, which are files or directories
+// inside root. Relative paths are relative to the working directory; with no
+// paths the whole root is scanned. Found paths are relative to root.
+func Scan(root string, paths []string, markers []string) (*Result, error) {
+	if len(markers) == 0 {
+		markers = DefaultMarkers
+	}
+	re, err := markerRegexp(markers)
+	if err != nil {
+		return nil, err
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %w", root, err)
+	}
+	if len(paths) == 0 {
+		paths = []string{root}
+	}
+
+	result := &Result{Markers: markers}
+	ignore := NewMatcher(root)
+	for _, p := range paths {
+		rel, err := relativeTo(root, p)
+		if err != nil {
+			return nil, err
+		}
+		result.Scope = append(result.Scope, rel)
+
+		err = walk(root, p, ignore, func(path, rel string) error {
+			syn, ok := syntaxFor(path)
+			if !ok {
+				return nil
+			}
+			lines, ok, err := readText(path)
+			if err != nil || !ok {
+				return err
+			}
+			result.Files++
+			result.Findings = append(result.Findings, findMarkers(lines, rel, syn, re)...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// relativeTo returns path, relative to the working directory, as a path
+// relative to root with "/" separators, and "" for root itself. It is an
+// error for path to be outside of root.
+func relativeTo(root, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside of the project root %s", path, root)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// walk calls fn for each regular file under path that is not ignored, with
+// the file's path and its path relative to root using "/" separators.
+// Version control and store directories are skipped.
+func walk(root, path string, ignore *Matcher, fn func(path, rel string) error) error {
+	start, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	err = filepath.WalkDir(start, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if path != start && (skippedDirs[d.Name()] || ignore.Ignored(rel, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || ignore.Ignored(rel, false) {
+			return nil
+		}
+		return fn(path, rel)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", path, err)
+	}
+	return nil
+}
+
+// markerRegexp returns the expression matching any of markers as a word,
+// optionally followed by "(author)" and ":", capturing the marker and the
+// rest of the comment.
+func markerRegexp(markers []string) (*regexp.Regexp, error) {
+	quoted := make([]string, 0, len(markers))
+	for _, m := range markers {
+		if m = strings.TrimSpace(m); m != "" {
+			quoted = append(quoted, regexp.QuoteMeta(m))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil, fmt.Errorf("no markers to scan for")
+	}
+	return regexp.Compile(`(?:^|[^\w])(` + strings.Join(quoted, "|") + `)(?:\([^)]*\))?(?:[:\s]|$)\s*(.*)$`)
+}
+
+// readText returns the lines of the file at path. Files that are too large
+// or look binary are skipped, returning false.
+func readText(path string) ([]string, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxFileSize {
+		return nil, false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil, false, nil
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxFileSize)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, true, nil
+}
+
+// findMarkers returns the marker comments in the lines of the file rel.
+func findMarkers(lines []string, rel string, syn syntax, re *regexp.Regexp) []Finding {
+	var findings []Finding
+	seen := make(map[string]int)
+	for _, c := range comments(lines, syn) {
+		m := re.FindStringSubmatch(c.text)
+		if m == nil {
+			continue
+		}
+		marker := m[1]
+		text := cleanText(m[2])
+
+		// Identical comments in the same file are told apart by their order
+		id := marker + "\x00" + text
+		seen[id]++
+		key := findingKey(rel, id, seen[id])
+
+		findings = append(findings, Finding{
+			Marker: marker,
+			Text:   text,
+			Key:    key,
+			Anchor: models.Anchor{
+				Path:        rel,
+				Line:        c.line,
+				Fingerprint: anchor.Fingerprint(lines, c.line),
+				Text:        strings.TrimSpace(lines[c.line-1]),
+			},
+		})
+	}
+	return findings
+}
+
+// cleanText tidies the text after a marker: leftover comment characters and
+// surrounding whitespace and punctuation are removed.
+func cleanText(s string) string {
+	s = strings.TrimSpace(s)
+	for _, end := range []string{"*/", "-->", "-}", "]]"} {
+		s = strings.TrimSpace(strings.TrimSuffix(s, end))
+	}
+	return strings.TrimLeft(s, ":-– ")
+}
+
+// findingKey returns the key of the n-th comment with the given marker and
+// text in the file rel.
+func findingKey(rel, id string, n int) string {
+	sum := sha256.Sum256([]byte(rel + "\x00" + id + "\x00" + strconv.Itoa(n)))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Content returns the item content for a finding: its text, or a description
+// of its location if the comment has no text.
+func (f Finding) Content() string {
+	if f.Text != "" {
+		return f.Text
+	}
+	return fmt.Sprintf("%s in %s", f.Marker, f.Anchor)
+}
+
+// Plan is the set of changes that bring the items in line with a scan.
+type Plan struct {
+	New     []models.ContextItem // Items for new findings
+	Updated []models.ContextItem // Items whose comment moved, changed or came back
+	Done    []models.ContextItem // Items whose comment is gone
+}
+
+// Reconcile compares the findings of a scan with the items imported by
+// earlier scans and returns the changes to make.
+//
+// A finding belongs to the item with the same key, so comments keep their
+// item when they move. Failing that, it belongs to an item anchored at lines
+// with the same fingerprint or, for a comment reworded in place, to an item
+// with the same marker anchored at the same line. Items imported from files
+// within the scope of the scan that match no finding are marked done if the
+// scan looked for their marker; if their comment reappears they are
+// reopened. Items closed by hand are left alone. An error is returned only
+// if no IDs could be generated for new items.
+func Reconcile(items []models.ContextItem, result *Result, now time.Time) (Plan, error) {
+	var plan Plan
+	byKey := make(map[string]int)
+	byFingerprint := make(map[string]int)
+	byLine := make(map[string]int)
+	for i, item := range items {
+		if item.Source == nil || item.Source.Kind != models.SourceScan {
+			continue
+		}
+		byKey[item.Source.Key] = i
+		if len(item.Anchors) > 0 {
+			a := item.Anchors[0]
+			byFingerprint[a.Path+"\x00"+a.Fingerprint] = i
+			if len(item.Tags) > 0 {
+				byLine[a.String()+"\x00"+item.Tags[0]] = i
+			}
+		}
+	}
+
+	// Match by key first, so that a moved comment is not claimed by another
+	// finding that happens to be at its old line
+	matches := make([]int, len(result.Findings))
+	matched := make(map[int]bool)
+	claim := func(n int, index map[string]int, key string) bool {
+		if i, ok := index[key]; ok && !matched[i] {
+			matches[n] = i
+			matched[i] = true
+			return true
+		}
+		return false
+	}
+	for n, f := range result.Findings {
+		matches[n] = -1
+		claim(n, byKey, f.Key)
+	}
+	for n, f := range result.Findings {
+		if matches[n] < 0 && !claim(n, byFingerprint, f.Anchor.Path+"\x00"+f.Anchor.Fingerprint) {
+			claim(n, byLine, f.Anchor.String()+"\x00"+strings.ToLower(f.Marker))
+		}
+	}
+
+	for n, f := range result.Findings {
+		i := matches[n]
+		if i < 0 {
+			item, err := newItem(f, now)
+			if err != nil {
+				return Plan{}, err
+			}
+			plan.New = append(plan.New, item)
+			continue
+		}
+		if item, changed := updateItem(items[i], f, now); changed {
+			plan.Updated = append(plan.Updated, item)
+		}
+	}
+
+	for i, item := range items {
+		if matched[i] || item.Source == nil || item.Source.Kind != models.SourceScan || item.CompletedAt != nil {
+			continue
+		}
+		if len(item.Anchors) == 0 || !inScope(item.Anchors[0].Path, result.Scope) || !scannedFor(item, result.Markers) {
+			continue
+		}
+		item.SetState(models.StateDone, ReasonRemoved, now)
+		plan.Done = append(plan.Done, item)
+	}
+	return plan, nil
+}
+
+// newItem creates the item for a new finding, tagged with its marker.
+func newItem(f Finding, now time.Time) (models.ContextItem, error) {
+	id, err := utils.GenerateUUID()
+	if err != nil {
+		return models.ContextItem{}, err
+	}
+	return models.ContextItem{
+		ID:        id,
+		Content:   f.Content(),
+		Tags:      []string{strings.ToLower(f.Marker)},
+		CreatedAt: now,
+		Anchors:   []models.Anchor{f.Anchor},
+		Source:    &models.Source{Kind: models.SourceScan, Key: f.Key},
+	}, nil
+}
+
+// updateItem applies a finding to the item it belongs to and reports whether
+// anything changed.
+func updateItem(item models.ContextItem, f Finding, now time.Time) (models.ContextItem, bool) {
+	changed := false
+	if item.Content != f.Content() {
+		item.Content = f.Content()
+		changed = true
+	}
+	if item.Source.Key != f.Key {
+		item.Source = &models.Source{Kind: models.SourceScan, Key: f.Key}
+		changed = true
+	}
+	if len(item.Anchors) == 0 || item.Anchors[0] != f.Anchor {
+		item.Anchors = append([]models.Anchor{f.Anchor}, item.Anchors[min(1, len(item.Anchors)):]...)
+		changed = true
+	}
+	if item.CompletedAt != nil && item.StateReason() == ReasonRemoved {
+		item.SetState(models.StateTodo, ReasonRestored, now)
+		changed = true
+	}
+	return item, changed
+}
+
+// inScope reports whether path is one of the scanned paths or inside one.
+func inScope(path string, scope []string) bool {
+	for _, s := range scope {
+		if s == "" || path == s || strings.HasPrefix(path, s+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// scannedFor reports whether the scan looked for the marker of item, the
+// tag it was created with, so that scanning with other markers does not close
+// it.
+func scannedFor(item models.ContextItem, markers []string) bool {
+	for _, m := range markers {
+		if len(item.Tags) > 0 && strings.EqualFold(item.Tags[0], strings.TrimSpace(m)) {
+			return true
+		}
+	}
+	return false
+}
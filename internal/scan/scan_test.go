@@ -0,0 +1,290 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// writeFiles creates files under root from a map of slash paths to content.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":     "# build output\n/dist\nnode_modules/\n*.log\n!keep.log\ndocs/**/*.tmp\n",
+		"web/.gitignore": "generated.*\n",
+	})
+	m := NewMatcher(root)
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"dist", true, true},
+		{"web/dist", true, false},
+		{"node_modules", true, true},
+		{"web/node_modules", true, true},
+		{"node_modules", false, false},
+		{"server.log", false, true},
+		{"logs/server.log", false, true},
+		{"keep.log", false, false},
+		{"docs/a/b/c.tmp", false, true},
+		{"c.tmp", false, false},
+		{"web/generated.go", false, true},
+		{"generated.go", false, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Ignored(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		src  string
+		want []comment
+	}{
+		{
+			name: "go line and block comments",
+			file: "main.go",
+			src:  "x := 1 // one\ny := \"// not a comment\"\n/* two\nthree */ z := 3\n",
+			want: []comment{{1, " one"}, {3, " two"}, {4, "three "}},
+		},
+		{
+			name: "python hash comments",
+			file: "app.py",
+			src:  "# one\nx = '#not' # two\n",
+			want: []comment{{1, " one"}, {2, " two"}},
+		},
+		{
+			name: "html comments",
+			file: "index.html",
+			src:  "<p>hi</p> <!-- one -->\n",
+			want: []comment{{1, " one "}},
+		},
+		{
+			name: "makefile by name",
+			file: "Makefile",
+			src:  "all: # one\n",
+			want: []comment{{1, " one"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syn, ok := syntaxFor(tt.file)
+			if !ok {
+				t.Fatalf("syntaxFor(%q) found no syntax", tt.file)
+			}
+			got := comments(strings.Split(strings.TrimSuffix(tt.src, "\n"), "\n"), syn)
+			if len(got) != len(tt.want) {
+				t.Fatalf("comments() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("comment %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, ok := syntaxFor("image.png"); ok {
+		t.Error("syntaxFor(image.png) should find no syntax")
+	}
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":       "vendor/\n",
+		"main.go":          "package main\n\n// TODO: handle errors\nfunc main() {} // FIXME(ana) leaks memory\n\nvar s = \"TODO: not a comment\"\n",
+		"lib/util.py":      "# HACK work around the old API\n# TODOS are not markers\n",
+		"vendor/dep.go":    "// TODO: ignored\n",
+		"notes.txt":        "TODO: unknown language\n",
+		".contextkeeper/x": "// TODO: store\n",
+	})
+
+	result, err := Scan(root, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Files != 2 {
+		t.Errorf("Files = %d, want 2", result.Files)
+	}
+
+	got := make(map[string]Finding)
+	for _, f := range result.Findings {
+		got[f.Anchor.String()] = f
+	}
+	want := map[string][2]string{
+		filepath.FromSlash("main.go:3"):     {"TODO", "handle errors"},
+		filepath.FromSlash("main.go:4"):     {"FIXME", "leaks memory"},
+		filepath.FromSlash("lib/util.py:1"): {"HACK", "work around the old API"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Findings = %+v, want %v", result.Findings, want)
+	}
+	for loc, w := range want {
+		f, ok := got[loc]
+		if !ok {
+			t.Errorf("No finding at %s", loc)
+			continue
+		}
+		if f.Marker != w[0] || f.Text != w[1] {
+			t.Errorf("Finding at %s = %s %q, want %s %q", loc, f.Marker, f.Text, w[0], w[1])
+		}
+		if f.Key == "" || f.Anchor.Fingerprint == "" {
+			t.Errorf("Finding at %s has no key or fingerprint", loc)
+		}
+	}
+
+	t.Run("custom markers", func(t *testing.T) {
+		result, err := Scan(root, nil, []string{"TODOS"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Findings) != 1 || result.Findings[0].Text != "are not markers" {
+			t.Errorf("Findings = %+v, want the TODOS comment", result.Findings)
+		}
+	})
+
+	t.Run("outside of root", func(t *testing.T) {
+		if _, err := Scan(filepath.Join(root, "lib"), []string{root}, nil); err == nil {
+			t.Error("Expected an error for a path outside of the root")
+		}
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	scanFiles := func(files map[string]string) *Result {
+		t.Helper()
+		writeFiles(t, root, files)
+		result, err := Scan(root, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	reconcile := func(items []models.ContextItem, result *Result, now time.Time) Plan {
+		t.Helper()
+		plan, err := Reconcile(items, result, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return plan
+	}
+	apply := func(items []models.ContextItem, plan Plan) []models.ContextItem {
+		changed := make(map[string]models.ContextItem)
+		for _, item := range append(plan.Updated, plan.Done...) {
+			changed[item.ID] = item
+		}
+		for i, item := range items {
+			if c, ok := changed[item.ID]; ok {
+				items[i] = c
+			}
+		}
+		return append(items, plan.New...)
+	}
+
+	handAdded := models.ContextItem{ID: "manual", Content: "Not from a scan"}
+	items := []models.ContextItem{handAdded}
+
+	plan := reconcile(items, scanFiles(map[string]string{
+		"a.go": "package a\n\n// TODO: first\nfunc A() {}\n\n// FIXME: second\nfunc B() {}\n",
+	}), now)
+	if len(plan.New) != 2 || len(plan.Updated) != 0 || len(plan.Done) != 0 {
+		t.Fatalf("First scan: %d new, %d updated, %d done, want 2 new", len(plan.New), len(plan.Updated), len(plan.Done))
+	}
+	if tags := plan.New[1].Tags; len(tags) != 1 || tags[0] != "fixme" {
+		t.Errorf("Tags = %v, want [fixme]", tags)
+	}
+	items = apply(items, plan)
+
+	t.Run("unchanged code changes nothing", func(t *testing.T) {
+		plan := reconcile(items, scanFiles(nil), now)
+		if len(plan.New)+len(plan.Updated)+len(plan.Done) != 0 {
+			t.Errorf("Expected no changes, got %+v", plan)
+		}
+	})
+
+	t.Run("moved comments keep their item", func(t *testing.T) {
+		plan := reconcile(items, scanFiles(map[string]string{
+			"a.go": "package a\n\nimport \"fmt\"\n\n// TODO: first\nfunc A() {}\n\n// FIXME: second\nfunc B() {}\n",
+		}), now)
+		if len(plan.New) != 0 || len(plan.Updated) != 2 || len(plan.Done) != 0 {
+			t.Fatalf("Got %d new, %d updated, %d done, want 2 updated", len(plan.New), len(plan.Updated), len(plan.Done))
+		}
+		if plan.Updated[0].Anchors[0].Line != 5 {
+			t.Errorf("Moved anchor line = %d, want 5", plan.Updated[0].Anchors[0].Line)
+		}
+		items = apply(items, plan)
+	})
+
+	t.Run("reworded comments keep their item", func(t *testing.T) {
+		plan := reconcile(items, scanFiles(map[string]string{
+			"a.go": "package a\n\nimport \"fmt\"\n\n// TODO: first\nfunc A() {}\n\n// FIXME: second, reworded\nfunc B() {}\n",
+		}), now)
+		if len(plan.New) != 0 || len(plan.Updated) != 1 || len(plan.Done) != 0 {
+			t.Fatalf("Got %d new, %d updated, %d done, want 1 updated", len(plan.New), len(plan.Updated), len(plan.Done))
+		}
+		if plan.Updated[0].Content != "second, reworded" {
+			t.Errorf("Reworded content = %q", plan.Updated[0].Content)
+		}
+		items = apply(items, plan)
+	})
+
+	t.Run("removed comments are done and come back", func(t *testing.T) {
+		plan := reconcile(items, scanFiles(map[string]string{
+			"a.go": "package a\n\nimport \"fmt\"\n\n// TODO: first\nfunc A() {}\n",
+		}), now)
+		if len(plan.Done) != 1 || plan.Done[0].Content != "second, reworded" {
+			t.Fatalf("Done = %+v, want the FIXME item", plan.Done)
+		}
+		if plan.Done[0].StateReason() != ReasonRemoved {
+			t.Errorf("Reason = %q, want %q", plan.Done[0].StateReason(), ReasonRemoved)
+		}
+		items = apply(items, plan)
+
+		plan = reconcile(items, scanFiles(map[string]string{
+			"a.go": "package a\n\nimport \"fmt\"\n\n// TODO: first\nfunc A() {}\n\n// FIXME: second, reworded\nfunc B() {}\n",
+		}), now)
+		reopened := false
+		for _, item := range plan.Updated {
+			if item.Content == "second, reworded" {
+				reopened = item.CompletedAt == nil && item.StateReason() == ReasonRestored
+			}
+		}
+		if !reopened || len(plan.New) != 0 {
+			t.Fatalf("Updated = %+v, want the FIXME item reopened", plan.Updated)
+		}
+	})
+
+	t.Run("items outside of the scope are kept", func(t *testing.T) {
+		plan := reconcile(items, &Result{Scope: []string{"other"}}, now)
+		if len(plan.Done) != 0 {
+			t.Errorf("Done = %+v, want none", plan.Done)
+		}
+	})
+}
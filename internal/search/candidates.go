@@ -0,0 +1,126 @@
+// Package search implements ranked full-text search over context items.
+// See tokenize.go for an overview.
+package search
+
+import (
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/query"
+)
+
+// Candidates returns the items that can match node, looked up in the index
+// so that only they need to be filtered and ranked. Every item that Match
+// would accept is kept, as is any item missing from the index. Queries that
+// the index cannot narrow down, such as a lone negation or field filter,
+// return items unchanged.
+func (s *Searcher) Candidates(items []models.ContextItem, node query.Node) []models.ContextItem {
+	ids, ok := s.candidates(node)
+	if !ok {
+		return items
+	}
+	filtered := make([]models.ContextItem, 0, len(ids))
+	for _, item := range items {
+		if _, indexed := s.idx.Docs[item.ID]; ids[item.ID] || !indexed {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// candidates returns the IDs of the indexed items that can match node, and
+// false if node does not restrict them.
+func (s *Searcher) candidates(node query.Node) (map[string]bool, bool) {
+	switch n := node.(type) {
+	case *query.And:
+		var ids map[string]bool
+		for _, child := range n.Children {
+			if c, ok := s.candidates(child); ok {
+				ids = intersect(ids, c)
+			}
+		}
+		return ids, ids != nil
+	case *query.Or:
+		ids := make(map[string]bool)
+		for _, child := range n.Children {
+			c, ok := s.candidates(child)
+			if !ok {
+				return nil, false
+			}
+			for id := range c {
+				ids[id] = true
+			}
+		}
+		return ids, true
+	case *query.Text:
+		return s.textCandidates(n)
+	}
+	return nil, false
+}
+
+// textCandidates returns the IDs of the indexed items that can match a text
+// term, either as a substring (see substringCandidates) or, for unquoted
+// terms, word by word through the expansions Match accepts.
+func (s *Searcher) textCandidates(t *query.Text) (map[string]bool, bool) {
+	ids, ok := s.substringCandidates(t.Value)
+	if !ok || t.Phrase {
+		return ids, ok
+	}
+
+	words := Tokenize(t.Value)
+	if len(words) == 0 {
+		return nil, false
+	}
+	var matched map[string]bool
+	for _, word := range words {
+		docs := make(map[string]bool)
+		for _, e := range s.expansionsOf(word) {
+			for id := range s.idx.Postings[e.term] {
+				docs[id] = true
+			}
+		}
+		matched = intersect(matched, docs)
+	}
+	for id := range matched {
+		ids[id] = true
+	}
+	return ids, true
+}
+
+// substringCandidates returns the IDs of the indexed items whose content or
+// tags may contain value. Each word of value lies within a single word of any
+// text containing it, so a candidate must have, for every word of value, an
+// indexed word containing it.
+func (s *Searcher) substringCandidates(value string) (map[string]bool, bool) {
+	fragments := splitWords(value)
+	if len(fragments) == 0 {
+		return nil, false
+	}
+	var ids map[string]bool
+	for _, fragment := range fragments {
+		docs := make(map[string]bool)
+		for word, wordDocs := range s.idx.Words {
+			if strings.Contains(word, fragment) {
+				for id := range wordDocs {
+					docs[id] = true
+				}
+			}
+		}
+		ids = intersect(ids, docs)
+	}
+	return ids, true
+}
+
+// intersect returns the IDs in both a and b, treating a nil a as every ID.
+func intersect(a, b map[string]bool) map[string]bool {
+	if a == nil {
+		return b
+	}
+	out := make(map[string]bool)
+	for id := range a {
+		if b[id] {
+			out[id] = true
+		}
+	}
+	return out
+}
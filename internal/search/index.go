@@ -0,0 +1,157 @@
+// Package search implements ranked full-text search over context items.
+// See tokenize.go for an overview.
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// Field weights applied to term frequencies. A match in a tag says more about
+// an item than a match somewhere in a long note.
+const (
+	contentWeight = 1
+	tagWeight     = 2
+	projectWeight = 1
+)
+
+// Index is an inverted index over context items.
+//
+// Postings map each term to the items containing it and the weighted term
+// frequency. Words map each lowercase word of an item's content and tags,
+// before stemming, to the items containing it; they let substring matches be
+// looked up too. Docs keeps each item's own terms and words so that an item
+// can be removed or replaced without rebuilding the whole index.
+type Index struct {
+	Docs        map[string]*Doc
+	Postings    map[string]map[string]int
+	Words       map[string]map[string]bool
+	TotalLength int
+}
+
+// Doc holds the indexed terms of a single item.
+type Doc struct {
+	Length int            // Sum of weighted term frequencies
+	Terms  map[string]int // Term to weighted frequency
+	Words  []string       // Distinct unstemmed words of content and tags
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		Docs:     make(map[string]*Doc),
+		Postings: make(map[string]map[string]int),
+		Words:    make(map[string]map[string]bool),
+	}
+}
+
+// Build returns an index containing all of the given items.
+func Build(items []models.ContextItem) *Index {
+	idx := NewIndex()
+	for _, item := range items {
+		idx.Add(item)
+	}
+	return idx
+}
+
+// Add indexes an item, replacing any previous entry with the same ID.
+func (idx *Index) Add(item models.ContextItem) {
+	idx.Remove(item.ID)
+	idx.addDoc(item.ID, itemTerms(item), itemWords(item))
+}
+
+// addDoc adds the terms and words of a document to the index. The ID must
+// not already be indexed.
+func (idx *Index) addDoc(id string, terms map[string]int, words []string) {
+	doc := &Doc{Terms: terms, Words: words}
+	for _, word := range words {
+		docs := idx.Words[word]
+		if docs == nil {
+			docs = make(map[string]bool)
+			idx.Words[word] = docs
+		}
+		docs[id] = true
+	}
+	for term, tf := range terms {
+		postings := idx.Postings[term]
+		if postings == nil {
+			postings = make(map[string]int)
+			idx.Postings[term] = postings
+		}
+		postings[id] = tf
+		doc.Length += tf
+	}
+	idx.Docs[id] = doc
+	idx.TotalLength += doc.Length
+}
+
+// Remove deletes an item from the index. Removing an unknown ID is a no-op.
+func (idx *Index) Remove(id string) {
+	doc, ok := idx.Docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.Terms {
+		delete(idx.Postings[term], id)
+		if len(idx.Postings[term]) == 0 {
+			delete(idx.Postings, term)
+		}
+	}
+	for _, word := range doc.Words {
+		delete(idx.Words[word], id)
+		if len(idx.Words[word]) == 0 {
+			delete(idx.Words, word)
+		}
+	}
+	idx.TotalLength -= doc.Length
+	delete(idx.Docs, id)
+}
+
+// Len returns the number of indexed items.
+func (idx *Index) Len() int {
+	return len(idx.Docs)
+}
+
+// Vocabulary returns all indexed terms in sorted order.
+func (idx *Index) Vocabulary() []string {
+	terms := make([]string, 0, len(idx.Postings))
+	for term := range idx.Postings {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
+// itemTerms returns the weighted term frequencies of an item's content, tags
+// and project.
+func itemTerms(item models.ContextItem) map[string]int {
+	terms := make(map[string]int)
+	for _, term := range Tokenize(item.Content) {
+		terms[term] += contentWeight
+	}
+	for _, term := range Tokenize(strings.Join(item.Tags, " ")) {
+		terms[term] += tagWeight
+	}
+	for _, term := range Tokenize(item.Project) {
+		terms[term] += projectWeight
+	}
+	return terms
+}
+
+// itemWords returns the distinct lowercase words of an item's content and
+// tags, split like Tokenize but not stemmed.
+func itemWords(item models.ContextItem) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, text := range append([]string{item.Content}, item.Tags...) {
+		for _, word := range splitWords(text) {
+			if !seen[word] {
+				seen[word] = true
+				words = append(words, word)
+			}
+		}
+	}
+	return words
+}
@@ -0,0 +1,232 @@
+// Package search implements ranked full-text search over context items.
+// See tokenize.go for an overview.
+package search
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// IndexFileName is the name of the persisted search index. It is stored next
+// to the items file and is ignored by git, since it can always be rebuilt.
+const IndexFileName = "search-index.gob"
+
+// indexVersion is stored in the index file and bumped whenever the tokenizer,
+// the term weights or the file layout change, so that older index files are
+// rebuilt instead of being misread.
+const indexVersion = 2
+
+// Stamp identifies a version of the items file. The index records the stamp
+// of the items file it was built from; a different stamp means the items
+// were changed without updating the index, for example by a git checkout.
+type Stamp struct {
+	Size    int64 // File size in bytes
+	ModTime int64 // Modification time in Unix nanoseconds
+}
+
+// indexFile is the on-disk form of an Index. The postings are stored along
+// with the documents so that opening the index does not rebuild them.
+type indexFile struct {
+	Version int
+	Store   Stamp
+	Index   Index
+}
+
+// IndexPath returns the path of the index file for the items file at
+// storePath.
+func IndexPath(storePath string) string {
+	return filepath.Join(filepath.Dir(storePath), IndexFileName)
+}
+
+// StampOf returns the stamp of the items file at storePath. A missing file
+// has the zero stamp.
+func StampOf(storePath string) (Stamp, error) {
+	info, err := os.Stat(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Stamp{}, nil
+		}
+		return Stamp{}, fmt.Errorf("failed to stat storage file %q: %w", storePath, err)
+	}
+	return Stamp{Size: info.Size(), ModTime: info.ModTime().UnixNano()}, nil
+}
+
+// LoadIndex reads the persisted index for the items file at storePath.
+//
+// It returns a nil Index and no error if there is no index file, or if the
+// index was written by a different version or no longer matches the items
+// file. Callers should then build a new index with Build.
+func LoadIndex(storePath string) (*Index, error) {
+	stamp, err := StampOf(storePath)
+	if err != nil {
+		return nil, err
+	}
+	file, err := readIndexFile(IndexPath(storePath))
+	if err != nil || file == nil {
+		return nil, err
+	}
+	if file.Version != indexVersion || file.Store != stamp {
+		return nil, nil
+	}
+	return file.index(), nil
+}
+
+// SaveIndex writes idx as the index of the items file at storePath, stamped
+// with the items file's current state.
+func SaveIndex(storePath string, idx *Index) error {
+	stamp, err := StampOf(storePath)
+	if err != nil {
+		return err
+	}
+
+	file := indexFile{Version: indexVersion, Store: stamp, Index: *idx}
+
+	path := IndexPath(storePath)
+	if err := ensureIgnored(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a concurrent search never
+	// reads a partially written index
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create search index %q: %w", tmp, err)
+	}
+	w := bufio.NewWriter(f)
+	if err := gob.NewEncoder(w).Encode(&file); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write search index %q: %w", tmp, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write search index %q: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace search index %q: %w", path, err)
+	}
+	return nil
+}
+
+// OpenIndex returns an up-to-date index of items, the current contents of
+// the items file at storePath.
+//
+// The persisted index is used when it is fresh. Otherwise the index is built
+// from items and persisted for the next search. The index is only a cache,
+// so failing to read or write it is not an error: the freshly built index is
+// returned either way.
+func OpenIndex(storePath string, items []models.ContextItem) *Index {
+	if idx, err := LoadIndex(storePath); err == nil && idx != nil && idx.Len() == len(items) {
+		return idx
+	}
+
+	idx := Build(items)
+	SaveIndex(storePath, idx)
+	return idx
+}
+
+// UpdateIndex applies an incremental change to the persisted index after the
+// items file at storePath has been written. before is the stamp of the items
+// file prior to the write.
+//
+// Nothing is done if there is no persisted index yet, or if it was already
+// stale before the write; in both cases the next OpenIndex rebuilds it.
+func UpdateIndex(storePath string, before Stamp, update func(idx *Index)) error {
+	file, err := readIndexFile(IndexPath(storePath))
+	if err != nil || file == nil {
+		return err
+	}
+	if file.Version != indexVersion || file.Store != before {
+		return nil
+	}
+
+	idx := file.index()
+	update(idx)
+	return SaveIndex(storePath, idx)
+}
+
+// RemoveIndex deletes the persisted index for the items file at storePath.
+// A missing index is not an error.
+func RemoveIndex(storePath string) error {
+	if err := os.Remove(IndexPath(storePath)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove search index: %w", err)
+	}
+	return nil
+}
+
+// readIndexFile decodes the index file at path. A missing file returns nil
+// and no error; so does a corrupt one, which is simply rebuilt.
+func readIndexFile(path string) (*indexFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open search index %q: %w", path, err)
+	}
+	defer f.Close()
+
+	var file indexFile
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&file); err != nil {
+		return nil, nil
+	}
+	return &file, nil
+}
+
+// index returns the decoded index. Gob leaves empty maps nil, so they are
+// recreated before the index is used or updated.
+func (file *indexFile) index() *Index {
+	idx := &file.Index
+	if idx.Docs == nil {
+		idx.Docs = make(map[string]*Doc)
+	}
+	if idx.Postings == nil {
+		idx.Postings = make(map[string]map[string]int)
+	}
+	if idx.Words == nil {
+		idx.Words = make(map[string]map[string]bool)
+	}
+	return idx
+}
+
+// ensureIgnored adds the index file to the .gitignore in dir, creating it if
+// needed, so that the index is never committed alongside items.json.
+func ensureIgnored(dir string) error {
+	path := filepath.Join(dir, ".gitignore")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	content := string(data)
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == IndexFileName {
+			return nil
+		}
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += IndexFileName + "\n" + IndexFileName + ".tmp\n"
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", dir, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// Package search implements ranked full-text search over context items.
+// See tokenize.go for an overview.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/query"
+)
+
+// BM25 parameters.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Weights of the different ways a query word can match an indexed term.
+const (
+	exactMatchWeight  = 1.0
+	prefixMatchWeight = 0.7
+	fuzzyMatchWeight  = 0.5
+
+	// phraseBonus multiplies the score of items containing a quoted phrase verbatim
+	phraseBonus = 1.5
+
+	// minPrefixLength is the shortest query word that matches longer terms by prefix
+	minPrefixLength = 3
+)
+
+// Result is a single ranked search result.
+type Result struct {
+	Item  models.ContextItem
+	Score float64
+}
+
+// expansion is an indexed term that a query word matched, with the weight of
+// the match.
+type expansion struct {
+	term   string
+	weight float64
+}
+
+// Searcher ranks items against the text terms of a query.
+type Searcher struct {
+	idx     *Index
+	words   [][]string             // Query words of each text term
+	expands map[string][]expansion // Query word to matching indexed terms
+}
+
+// NewSearcher prepares a search for the given text terms over idx.
+//
+// Every query word is expanded up front into the indexed terms it matches
+// exactly, as a prefix, or within a small edit distance, so that matching and
+// scoring each item is cheap.
+func NewSearcher(idx *Index, terms []*query.Text) *Searcher {
+	s := &Searcher{idx: idx, expands: make(map[string][]expansion)}
+	vocab := idx.Vocabulary()
+
+	for _, term := range terms {
+		words := Tokenize(term.Value)
+		s.words = append(s.words, words)
+		for _, word := range words {
+			if _, ok := s.expands[word]; !ok {
+				s.expands[word] = expand(word, vocab)
+			}
+		}
+	}
+	return s
+}
+
+// expand returns the vocabulary terms matched by a query word.
+func expand(word string, vocab []string) []expansion {
+	var out []expansion
+	maxEdits := allowedEdits(word)
+	for _, term := range vocab {
+		switch {
+		case term == word:
+			out = append(out, expansion{term, exactMatchWeight})
+		case len(word) >= minPrefixLength && strings.HasPrefix(term, word):
+			out = append(out, expansion{term, prefixMatchWeight})
+		case maxEdits > 0 && withinDistance(word, term, maxEdits):
+			out = append(out, expansion{term, fuzzyMatchWeight})
+		}
+	}
+	return out
+}
+
+// Match reports whether item satisfies a text term of the query.
+//
+// An item matches if the term occurs in its content or tags as a substring
+// (the behaviour of query.Text), or, for unquoted terms, if every word of the
+// term matches one of the item's indexed terms exactly, by prefix or fuzzily.
+// Quoted phrases must appear verbatim.
+func (s *Searcher) Match(t *query.Text, item models.ContextItem) bool {
+	if t.Match(item) {
+		return true
+	}
+	if t.Phrase {
+		return false
+	}
+
+	terms := s.docTerms(item)
+	for _, word := range Tokenize(t.Value) {
+		found := false
+		for _, e := range s.expansionsOf(word) {
+			if terms[e.term] > 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Rank scores items with BM25 and returns them ordered by descending score.
+// Items with equal scores keep their original order. Without text terms
+// every item scores zero and the order is unchanged.
+func (s *Searcher) Rank(items []models.ContextItem) []Result {
+	results := make([]Result, len(items))
+	for i, item := range items {
+		results[i] = Result{Item: item, Score: s.Score(item)}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// Score returns the BM25 score of item for the query.
+//
+// For each query word the best-scoring expansion counts, weighted by how it
+// matched. Quoted phrases found verbatim in the content earn a bonus.
+func (s *Searcher) Score(item models.ContextItem) float64 {
+	terms := s.docTerms(item)
+	docLen := 0
+	for _, tf := range terms {
+		docLen += tf
+	}
+
+	n := float64(s.idx.Len())
+	avgLen := 1.0
+	if s.idx.Len() > 0 && s.idx.TotalLength > 0 {
+		avgLen = float64(s.idx.TotalLength) / n
+	}
+
+	score := 0.0
+	for _, words := range s.words {
+		termScore := 0.0
+		for _, word := range words {
+			best := 0.0
+			for _, e := range s.expansionsOf(word) {
+				tf := float64(terms[e.term])
+				if tf == 0 {
+					continue
+				}
+				df := float64(len(s.idx.Postings[e.term]))
+				idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+				norm := tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(docLen)/avgLen))
+				if v := e.weight * idf * norm; v > best {
+					best = v
+				}
+			}
+			termScore += best
+		}
+		score += termScore
+	}
+
+	for _, words := range s.words {
+		if len(words) > 1 && strings.Contains(strings.ToLower(item.Content), strings.Join(words, " ")) {
+			score *= phraseBonus
+		}
+	}
+	return score
+}
+
+// expansionsOf returns the expansions of a query word, computing them on
+// demand for words that were not part of the original query.
+func (s *Searcher) expansionsOf(word string) []expansion {
+	if e, ok := s.expands[word]; ok {
+		return e
+	}
+	e := expand(word, s.idx.Vocabulary())
+	s.expands[word] = e
+	return e
+}
+
+// docTerms returns the indexed terms of an item, tokenizing it directly if
+// the item is not in the index.
+func (s *Searcher) docTerms(item models.ContextItem) map[string]int {
+	if doc, ok := s.idx.Docs[item.ID]; ok {
+		return doc.Terms
+	}
+	return itemTerms(item)
+}
+
+// Snippet returns an excerpt of content of about width characters around the
+// first word that matches the query, with matching words wrapped in open and
+// close (for example ANSI color codes). Newlines are collapsed to spaces and
+// "..." marks text cut at either end.
+func (s *Searcher) Snippet(content string, width int, open, close string) string {
+	matched := make(map[string]bool)
+	for _, words := range s.words {
+		for _, word := range words {
+			for _, e := range s.expansionsOf(word) {
+				matched[e.term] = true
+			}
+		}
+	}
+
+	text := []rune(strings.Join(strings.Fields(content), " "))
+	type span struct{ start, end int }
+	var spans []span
+	for i := 0; i < len(text); {
+		if !isWordRune(text[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(text) && isWordRune(text[j]) {
+			j++
+		}
+		if matched[Stem(strings.ToLower(string(text[i:j])))] {
+			spans = append(spans, span{i, j})
+		}
+		i = j
+	}
+
+	start, end := 0, len(text)
+	if len(text) > width {
+		if len(spans) > 0 {
+			start = spans[0].start - width/4
+		}
+		if start < 0 {
+			start = 0
+		}
+		end = start + width
+		if end > len(text) {
+			end = len(text)
+			start = end - width
+		}
+		// Move the cut points to word boundaries
+		for start > 0 && isWordRune(text[start-1]) {
+			start++
+		}
+		for end < len(text) && end > start && isWordRune(text[end]) {
+			end--
+		}
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("...")
+	}
+	pos := start
+	for _, sp := range spans {
+		if sp.start < start || sp.end > end {
+			continue
+		}
+		sb.WriteString(string(text[pos:sp.start]))
+		sb.WriteString(open + string(text[sp.start:sp.end]) + close)
+		pos = sp.end
+	}
+	sb.WriteString(string(text[pos:end]))
+	if end < len(text) {
+		sb.WriteString("...")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// isWordRune reports whether r is part of a word for tokenization purposes.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
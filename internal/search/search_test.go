@@ -0,0 +1,282 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/query"
+)
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"tokens":   "token",
+		"queries":  "query",
+		"limiting": "limit",
+		"stopped":  "stop",
+		"classes":  "class",
+		"status":   "status",
+		"api":      "api",
+		"fixes":    "fix",
+	}
+	for word, want := range tests {
+		if got := Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := strings.Join(Tokenize("Fix OAuth-tokens, in login.go!"), " ")
+	if got != "fix oauth token in login go" {
+		t.Errorf("Tokenize() = %q", got)
+	}
+}
+
+func TestWithinDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want bool
+	}{
+		{"auth", "auth", 0, true},
+		{"autentication", "authentication", 1, true},
+		{"atuhentication", "authentication", 1, false},
+		{"atuhentication", "authentication", 2, true},
+		{"cat", "dog", 2, false},
+	}
+	for _, tt := range tests {
+		if got := withinDistance(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("withinDistance(%q, %q, %d) = %v, want %v", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestIndexAddRemove(t *testing.T) {
+	idx := Build([]models.ContextItem{
+		{ID: "1", Content: "auth bug"},
+		{ID: "2", Content: "auth feature", Tags: []string{"ui"}},
+	})
+	if idx.Len() != 2 || len(idx.Postings["auth"]) != 2 {
+		t.Fatalf("Unexpected index: %+v", idx)
+	}
+
+	idx.Add(models.ContextItem{ID: "2", Content: "dashboard"})
+	if len(idx.Postings["auth"]) != 1 || idx.Postings["ui"] != nil {
+		t.Errorf("Expected re-adding to replace old terms, got %+v", idx.Postings)
+	}
+
+	idx.Remove("1")
+	idx.Remove("2")
+	if idx.Len() != 0 || len(idx.Postings) != 0 || idx.TotalLength != 0 {
+		t.Errorf("Expected empty index, got %+v", idx)
+	}
+}
+
+func TestSearcherRankAndMatch(t *testing.T) {
+	items := []models.ContextItem{
+		{ID: "1", Content: "Update the README"},
+		{ID: "2", Content: "Rotate authentication tokens for the login service", Tags: []string{"auth"}},
+		{ID: "3", Content: "authentication docs need an example"},
+		{ID: "4", Content: "Token cleanup job"},
+	}
+	idx := Build(items)
+
+	parse := func(q string) *Searcher {
+		node, err := query.Parse(q)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", q, err)
+		}
+		return NewSearcher(idx, query.TextTerms(node))
+	}
+	matching := func(s *Searcher, q string) string {
+		node, _ := query.Parse(q)
+		ids := ""
+		for _, r := range s.Rank(query.FilterWith(items, node, s.Match)) {
+			ids += r.Item.ID
+		}
+		return ids
+	}
+
+	t.Run("ranks tag and multi-term matches first", func(t *testing.T) {
+		s := parse("auth token")
+		if got := matching(s, "auth token"); got != "2" {
+			t.Errorf("Expected only item 2 to match, got %q", got)
+		}
+		s = parse("authentication")
+		if got := matching(s, "authentication"); got[0] != '2' && got[0] != '3' {
+			t.Errorf("Unexpected ranking %q", got)
+		}
+	})
+
+	t.Run("fuzzy matches typos", func(t *testing.T) {
+		s := parse("autentication")
+		if got := matching(s, "autentication"); got != "32" && got != "23" {
+			t.Errorf("Expected typo to match items 2 and 3, got %q", got)
+		}
+	})
+
+	t.Run("stemming matches inflections", func(t *testing.T) {
+		s := parse("tokens")
+		if got := matching(s, "tokens"); !strings.Contains(got, "4") || !strings.Contains(got, "2") {
+			t.Errorf("Expected items 2 and 4, got %q", got)
+		}
+	})
+
+	t.Run("phrases must match verbatim", func(t *testing.T) {
+		s := parse(`"authentication tokens"`)
+		if got := matching(s, `"authentication tokens"`); got != "2" {
+			t.Errorf("Expected only item 2, got %q", got)
+		}
+		if got := matching(s, `"tokens authentication"`); got != "" {
+			t.Errorf("Expected no match for reversed phrase, got %q", got)
+		}
+	})
+
+	t.Run("scores are positive for matches", func(t *testing.T) {
+		s := parse("readme")
+		if s.Score(items[0]) <= 0 || s.Score(items[1]) != 0 {
+			t.Errorf("Unexpected scores: %v %v", s.Score(items[0]), s.Score(items[1]))
+		}
+	})
+}
+
+func TestCandidates(t *testing.T) {
+	items := []models.ContextItem{
+		{ID: "1", Content: "Update the README"},
+		{ID: "2", Content: "Rotate authentication tokens for the login service", Tags: []string{"auth"}},
+		{ID: "3", Content: "authentication docs need an example", Project: "docs"},
+		{ID: "4", Content: "Token cleanup job"},
+	}
+	idx := Build(items)
+	// Items missing from the index are always candidates
+	all := append(items, models.ContextItem{ID: "5", Content: "not indexed yet"})
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"authentication", "235"},
+		{"autentication", "235"},
+		{"thent", "235"},
+		{"token", "245"},
+		{"login OR readme", "125"},
+		{"auth AND -docs", "235"},
+		{"-readme", "12345"},
+		{"project:docs", "12345"},
+		{`"the login"`, "25"},
+		{"nothing", "5"},
+	}
+	for _, tt := range tests {
+		node, err := query.Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q) error: %v", tt.query, err)
+		}
+		s := NewSearcher(idx, query.TextTerms(node))
+		candidates := s.Candidates(all, node)
+		ids := ""
+		for _, item := range candidates {
+			ids += item.ID
+		}
+		if ids != tt.want {
+			t.Errorf("Candidates(%q) = %q, want %q", tt.query, ids, tt.want)
+		}
+		// Candidates never drop a match
+		if got, want := len(query.FilterWith(candidates, node, s.Match)), len(query.FilterWith(all, node, s.Match)); got != want {
+			t.Errorf("Candidates(%q) lost matches: %d of %d", tt.query, got, want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	node, _ := query.Parse("tokens")
+	s := NewSearcher(Build([]models.ContextItem{{ID: "1", Content: "tokens"}}), query.TextTerms(node))
+
+	content := strings.Repeat("filler words here ", 10) + "rotate the Token now " + strings.Repeat("more filler text ", 10)
+	snippet := s.Snippet(content, 60, "[", "]")
+
+	if !strings.Contains(snippet, "[Token]") {
+		t.Errorf("Expected highlighted match, got %q", snippet)
+	}
+	if !strings.HasPrefix(snippet, "...") || !strings.HasSuffix(snippet, "...") {
+		t.Errorf("Expected ellipses on both ends, got %q", snippet)
+	}
+
+	if got := s.Snippet("short token note", 60, "[", "]"); got != "short [token] note" {
+		t.Errorf("Unexpected short snippet %q", got)
+	}
+}
+
+func TestPersistedIndex(t *testing.T) {
+	dir := t.TempDir()
+	storePath := filepath.Join(dir, "items.json")
+	if err := os.WriteFile(storePath, []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	items := []models.ContextItem{{ID: "1", Content: "auth tokens"}}
+	if err := SaveIndex(storePath, Build(items)); err != nil {
+		t.Fatalf("SaveIndex() error: %v", err)
+	}
+
+	t.Run("loads a fresh index", func(t *testing.T) {
+		idx, err := LoadIndex(storePath)
+		if err != nil || idx == nil {
+			t.Fatalf("LoadIndex() = %v, %v", idx, err)
+		}
+		if idx.Len() != 1 || idx.Postings["token"]["1"] != 1 || idx.TotalLength != 2 {
+			t.Errorf("Unexpected index: %+v", idx)
+		}
+	})
+
+	t.Run("ignores the index in git", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+		if err != nil || !strings.Contains(string(data), IndexFileName) {
+			t.Errorf("Expected .gitignore entry, got %q (err=%v)", data, err)
+		}
+	})
+
+	t.Run("updates incrementally", func(t *testing.T) {
+		before, _ := StampOf(storePath)
+		if err := os.WriteFile(storePath, []byte("[{}]"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := UpdateIndex(storePath, before, func(idx *Index) {
+			idx.Add(models.ContextItem{ID: "2", Content: "dashboard"})
+		})
+		if err != nil {
+			t.Fatalf("UpdateIndex() error: %v", err)
+		}
+		idx, _ := LoadIndex(storePath)
+		if idx == nil || idx.Len() != 2 {
+			t.Fatalf("Expected updated index with 2 items, got %+v", idx)
+		}
+	})
+
+	t.Run("detects external changes", func(t *testing.T) {
+		if err := os.WriteFile(storePath, []byte("[{}, {}]"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if idx, _ := LoadIndex(storePath); idx != nil {
+			t.Error("Expected stale index to be rejected")
+		}
+
+		// A stale index is not updated incrementally
+		before, _ := StampOf(storePath)
+		called := false
+		UpdateIndex(storePath, before, func(*Index) { called = true })
+		if called {
+			t.Error("Expected no update of a stale index")
+		}
+
+		rebuilt := OpenIndex(storePath, []models.ContextItem{{ID: "3", Content: "rebuilt"}})
+		if rebuilt.Len() != 1 || rebuilt.Docs["3"] == nil {
+			t.Errorf("Expected rebuilt index, got %+v", rebuilt)
+		}
+		if idx, _ := LoadIndex(storePath); idx == nil || idx.Docs["3"] == nil {
+			t.Error("Expected rebuilt index to be persisted")
+		}
+	})
+}
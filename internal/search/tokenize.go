@@ -0,0 +1,73 @@
+// Package search implements ranked full-text search over context items.
+//
+// Items are tokenized into lowercase, lightly stemmed terms and stored in an
+// inverted Index. Rank scores items against the text terms of a query with
+// Okapi BM25, falling back to prefix and fuzzy (edit distance) matches for
+// partial words and typos, and produces highlighted snippets for display.
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minStemLength is the shortest word that suffix stripping is applied to.
+const minStemLength = 4
+
+// Tokenize splits text into normalized search terms.
+//
+// Text is lowercased and split on anything that is not a letter or digit;
+// each word is then passed through Stem.
+func Tokenize(text string) []string {
+	words := splitWords(text)
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, Stem(word))
+	}
+	return terms
+}
+
+// splitWords lowercases text and splits it on anything that is not a letter
+// or digit.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Stem applies light suffix stripping so that common inflections of a word
+// share a term: "tokens" and "token", "limiting" and "limit", "queries" and
+// "query". It deliberately does much less than a full Porter stemmer, which
+// keeps results predictable for short technical notes.
+func Stem(word string) string {
+	if len([]rune(word)) < minStemLength {
+		return word
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return word[:len(word)-1]
+	case strings.HasSuffix(word, "ing") && len(word) > 5:
+		return undouble(word[:len(word)-3])
+	case strings.HasSuffix(word, "ed") && len(word) > 4:
+		return undouble(word[:len(word)-2])
+	}
+	return word
+}
+
+// undouble removes a doubled final consonant left behind by suffix
+// stripping, e.g. "stopp" (from "stopped") becomes "stop".
+func undouble(stem string) string {
+	n := len(stem)
+	if n >= 3 && stem[n-1] == stem[n-2] && !strings.ContainsRune("aeiouls", rune(stem[n-1])) {
+		return stem[:n-1]
+	}
+	return stem
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/search"
 )
 
 // ErrItemNotFound is returned when an item with the specified ID doesn't exist.
@@ -22,6 +24,10 @@ const (
 	// ItemsFileName is the default filename for storing items.
 	ItemsFileName = "items.json"
 
+	// CounterFileName is the file next to items.json holding the last item
+	// number allocated, so that numbers of deleted items are not reused.
+	CounterFileName = "counter"
+
 	// DefaultDirPerms are the default permissions for created directories.
 	DefaultDirPerms = 0755
 
@@ -70,6 +76,11 @@ type Storage interface {
 
 	// SetItems replaces all items with the provided slice.
 	SetItems(items []models.ContextItem)
+
+	// Batch runs fn and writes the changes it makes through this storage
+	// once fn returns, instead of after each change. Changes made before fn
+	// fails are still written. Nested calls join the outer batch.
+	Batch(fn func() error) error
 }
 
 // storageImpl provides thread-safe JSON file storage for context items.
@@ -78,6 +89,12 @@ type storageImpl struct {
 	mu    sync.RWMutex // Protects all fields
 	path  string       // Directory path for storage
 	items []models.ContextItem
+
+	counter int // The last item number allocated
+	saved   int // The counter as last read or written
+
+	batching bool                  // Inside Batch: writes are deferred
+	pending  []func(*search.Index) // Index updates deferred by Batch
 }
 
 // NewStorage creates a new Storage instance that persists to the specified directory.
@@ -115,6 +132,7 @@ func (s *storageImpl) persistLocked() error {
 		return err
 	}
 
+	s.counter = Renumber(s.items, s.counter)
 	data, err := json.MarshalIndent(s.items, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal items to JSON: %w", err)
@@ -123,6 +141,75 @@ func (s *storageImpl) persistLocked() error {
 	if err := os.WriteFile(s.path, data, DefaultFilePerms); err != nil {
 		return fmt.Errorf("failed to write storage file %q: %w", s.path, err)
 	}
+	if s.counter != s.saved {
+		counterPath := filepath.Join(filepath.Dir(s.path), CounterFileName)
+		if err := os.WriteFile(counterPath, []byte(strconv.Itoa(s.counter)+"\n"), DefaultFilePerms); err != nil {
+			return fmt.Errorf("failed to write counter file %q: %w", counterPath, err)
+		}
+		s.saved = s.counter
+	}
+	return nil
+}
+
+// loadCounterLocked reads the counter file; a missing one counts as 0.
+// Caller must hold the write lock.
+func (s *storageImpl) loadCounterLocked() error {
+	counterPath := filepath.Join(filepath.Dir(s.path), CounterFileName)
+	data, err := os.ReadFile(counterPath)
+	if os.IsNotExist(err) {
+		s.counter, s.saved = 0, 0
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read counter file %q: %w", counterPath, err)
+	}
+	counter, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("invalid counter file %q: %w", counterPath, err)
+	}
+	s.counter, s.saved = counter, counter
+	return nil
+}
+
+// stampLocked returns the current stamp of the storage file for a later
+// indexLocked call. Caller must hold the write lock.
+func (s *storageImpl) stampLocked() search.Stamp {
+	stamp, _ := search.StampOf(s.path)
+	return stamp
+}
+
+// indexLocked applies a change to the persisted search index after a write.
+// before is the stamp of the storage file prior to the write.
+//
+// Errors are ignored: the index is a cache, and one that misses an update is
+// detected as stale and rebuilt by the next search.
+// Caller must hold the write lock.
+func (s *storageImpl) indexLocked(before search.Stamp, update func(idx *search.Index)) {
+	search.UpdateIndex(s.path, before, update)
+}
+
+// reindex rebuilds the search index from the current items, after they
+// were replaced wholesale. It is run as an index update with the write lock
+// held, so that in a batch it sees the items as they are at the end.
+func (s *storageImpl) reindex(idx *search.Index) {
+	*idx = *search.Build(s.items)
+}
+
+// writeLocked persists the items after a change and applies update to the
+// persisted search index, or defers both to the end of the current Batch.
+// Caller must hold the write lock.
+func (s *storageImpl) writeLocked(update func(idx *search.Index)) error {
+	s.counter = Renumber(s.items, s.counter)
+	if s.batching {
+		s.pending = append(s.pending, update)
+		return nil
+	}
+
+	before := s.stampLocked()
+	if err := s.persistLocked(); err != nil {
+		return err
+	}
+	s.indexLocked(before, update)
 	return nil
 }
 
@@ -131,6 +218,9 @@ func (s *storageImpl) Load() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.loadCounterLocked(); err != nil {
+		return err
+	}
 	data, err := os.ReadFile(s.path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -140,9 +230,17 @@ func (s *storageImpl) Load() error {
 		return fmt.Errorf("failed to read storage file %q: %w", s.path, err)
 	}
 
-	if err := json.Unmarshal(data, &s.items); err != nil {
+	// Decode into a fresh slice: decoding into the existing one would keep
+	// fields of previously loaded items that are omitted from the file
+	var items []models.ContextItem
+	if err := json.Unmarshal(data, &items); err != nil {
 		return fmt.Errorf("failed to unmarshal JSON from storage file %q: %w", s.path, err)
 	}
+	s.items = items
+
+	// Number items written before numbers existed, or that share a number
+	// after a merge; the numbers are saved with the next change
+	s.counter = Renumber(s.items, s.counter)
 
 	return nil
 }
@@ -152,7 +250,7 @@ func (s *storageImpl) Save() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.persistLocked()
+	return s.writeLocked(s.reindex)
 }
 
 // GetAll returns a copy of all stored items.
@@ -179,7 +277,8 @@ func (s *storageImpl) GetByID(id string) (models.ContextItem, error) {
 	return models.ContextItem{}, ErrItemNotFound
 }
 
-// GetByPrefix retrieves items by ID prefix.
+// GetByPrefix retrieves items by ID prefix, or short ID prefix (see
+// models.MatchesID).
 // Returns the item if exactly one matches.
 // Returns ErrItemNotFound if no items match.
 // Returns ErrAmbiguousID if multiple items match.
@@ -189,7 +288,7 @@ func (s *storageImpl) GetByPrefix(prefix string) (models.ContextItem, error) {
 
 	var matches []models.ContextItem
 	for _, item := range s.items {
-		if strings.HasPrefix(item.ID, prefix) {
+		if models.MatchesID(item.ID, prefix) {
 			matches = append(matches, item)
 		}
 	}
@@ -210,7 +309,7 @@ func (s *storageImpl) Add(item models.ContextItem) error {
 	defer s.mu.Unlock()
 
 	s.items = append(s.items, item)
-	return s.persistLocked()
+	return s.writeLocked(func(idx *search.Index) { idx.Add(item) })
 }
 
 // Update modifies an existing item.
@@ -220,8 +319,11 @@ func (s *storageImpl) Update(item models.ContextItem) error {
 
 	for i := range s.items {
 		if s.items[i].ID == item.ID {
+			if item.Number == 0 {
+				item.Number = s.items[i].Number
+			}
 			s.items[i] = item
-			return s.persistLocked()
+			return s.writeLocked(func(idx *search.Index) { idx.Add(item) })
 		}
 	}
 
@@ -236,7 +338,8 @@ func (s *storageImpl) Archive(id string) error {
 	for i := range s.items {
 		if s.items[i].ID == id {
 			s.items[i].Archived = true
-			return s.persistLocked()
+			archived := s.items[i]
+			return s.writeLocked(func(idx *search.Index) { idx.Add(archived) })
 		}
 	}
 
@@ -251,7 +354,7 @@ func (s *storageImpl) Delete(id string) error {
 	for i := range s.items {
 		if s.items[i].ID == id {
 			s.items = append(s.items[:i], s.items[i+1:]...)
-			return s.persistLocked()
+			return s.writeLocked(func(idx *search.Index) { idx.Remove(id) })
 		}
 	}
 
@@ -264,5 +367,38 @@ func (s *storageImpl) SetItems(items []models.ContextItem) {
 	defer s.mu.Unlock()
 
 	s.items = items
-	s.persistLocked()
+	s.writeLocked(s.reindex)
+}
+
+// Batch runs fn with writes deferred, then persists the items and updates
+// the search index once for all of the changes fn made.
+func (s *storageImpl) Batch(fn func() error) error {
+	s.mu.Lock()
+	if s.batching {
+		s.mu.Unlock()
+		return fn()
+	}
+	s.batching = true
+	s.mu.Unlock()
+
+	err := fn()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := s.pending
+	s.batching, s.pending = false, nil
+	if len(pending) == 0 {
+		return err
+	}
+
+	before := s.stampLocked()
+	if perr := s.persistLocked(); perr != nil {
+		return errors.Join(err, perr)
+	}
+	s.indexLocked(before, func(idx *search.Index) {
+		for _, update := range pending {
+			update(idx)
+		}
+	})
+	return err
 }
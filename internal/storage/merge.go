@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// Conflict is a field of an item that both sides of a merge changed
+// differently. Merge keeps our side of it.
+type Conflict struct {
+	ID    string // The item's ID
+	Field string // The JSON name of the field, or "" if one side deleted the item
+}
+
+// item is an item of a store file as its JSON fields, to merge them one by
+// one.
+type item map[string]json.RawMessage
+
+// Merge merges two versions of a store file, ours and theirs, that were both
+// changed from base, as a git merge driver does.
+//
+// Items are matched by ID and merged field by field: a field changed on one
+// side only takes that side's value. Fields both sides changed differently
+// keep our value and are returned as conflicts, as are items deleted on one
+// side and changed on the other, which are kept. Items added on both sides
+// may share a number; they are renumbered with Renumber.
+func Merge(base, ours, theirs []byte) ([]byte, []Conflict, error) {
+	var sides [3][]item
+	last := 0
+	for i, data := range [][]byte{base, ours, theirs} {
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(data, &sides[i]); err != nil {
+			return nil, nil, fmt.Errorf("invalid store file: %w", err)
+		}
+		var items []models.ContextItem
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, nil, fmt.Errorf("invalid store file: %w", err)
+		}
+		for _, it := range items {
+			if it.Number > last {
+				last = it.Number
+			}
+		}
+	}
+	byID := func(items []item) map[string]item {
+		m := make(map[string]item, len(items))
+		for _, it := range items {
+			m[it.id()] = it
+		}
+		return m
+	}
+	baseItems, ourItems, theirItems := byID(sides[0]), byID(sides[1]), byID(sides[2])
+
+	// Our items in our order, then the items only they have
+	var ids []string
+	for _, it := range sides[1] {
+		ids = append(ids, it.id())
+	}
+	for _, it := range sides[2] {
+		if _, ok := ourItems[it.id()]; !ok {
+			ids = append(ids, it.id())
+		}
+	}
+
+	var merged []item
+	var conflicts []Conflict
+	for _, id := range ids {
+		b, o, t := baseItems[id], ourItems[id], theirItems[id]
+		switch {
+		case o == nil && t == nil:
+			continue
+		case b != nil && o == nil:
+			// Deleted by us: gone unless they changed it
+			if !b.equal(t) {
+				merged = append(merged, t)
+				conflicts = append(conflicts, Conflict{ID: id})
+			}
+		case b != nil && t == nil:
+			if !b.equal(o) {
+				merged = append(merged, o)
+				conflicts = append(conflicts, Conflict{ID: id})
+			}
+		case o == nil:
+			merged = append(merged, t)
+		case t == nil:
+			merged = append(merged, o)
+		default:
+			it, fields := mergeFields(b, o, t)
+			merged = append(merged, it)
+			for _, f := range fields {
+				conflicts = append(conflicts, Conflict{ID: id, Field: f})
+			}
+		}
+	}
+
+	// Decode into items to renumber them and write them like Save does
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+	items := make([]models.ContextItem, 0, len(merged))
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, nil, err
+	}
+	Renumber(items, last)
+	out, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, conflicts, nil
+}
+
+// mergeFields merges the fields of an item changed on both sides, returning
+// the names of the fields both sides changed differently.
+func mergeFields(base, ours, theirs item) (item, []string) {
+	merged := make(item)
+	var conflicts []string
+	for _, key := range fieldNames(ours, theirs, base) {
+		b, o, t := base[key], ours[key], theirs[key]
+		v := o
+		switch {
+		case sameJSON(o, t), sameJSON(b, t):
+		case sameJSON(b, o):
+			v = t
+		case key == "number":
+			// Both renumbered the item; Renumber settles it
+		default:
+			conflicts = append(conflicts, key)
+		}
+		if v != nil {
+			merged[key] = v
+		}
+	}
+	return merged, conflicts
+}
+
+// fieldNames returns the field names of the items, each once, sorted.
+func fieldNames(items ...item) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, it := range items {
+		for key := range it {
+			if !seen[key] {
+				seen[key] = true
+				names = append(names, key)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// id returns the item's ID.
+func (it item) id() string {
+	var id string
+	json.Unmarshal(it["id"], &id)
+	return id
+}
+
+// equal reports whether two items have the same fields and values.
+func (it item) equal(other item) bool {
+	if len(it) != len(other) {
+		return false
+	}
+	for key, v := range it {
+		if !sameJSON(v, other[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameJSON reports whether two JSON values are equal ignoring layout; a nil
+// value, for a missing field, only equals another nil value.
+func sameJSON(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
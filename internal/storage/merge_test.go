@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+func TestMerge(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	encode := func(t *testing.T, items ...models.ContextItem) []byte {
+		t.Helper()
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	decode := func(t *testing.T, data []byte) map[string]models.ContextItem {
+		t.Helper()
+		var items []models.ContextItem
+		if err := json.Unmarshal(data, &items); err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[string]models.ContextItem)
+		for _, item := range items {
+			m[item.ID] = item
+		}
+		return m
+	}
+
+	login := models.ContextItem{ID: "login", Number: 1, Content: "Use OIDC", CreatedAt: created}
+	api := models.ContextItem{ID: "api", Number: 2, Content: "Rate limit", CreatedAt: created}
+	gone := models.ContextItem{ID: "gone", Number: 3, Content: "Old note", CreatedAt: created}
+	base := encode(t, login, api, gone)
+
+	t.Run("changes of both sides", func(t *testing.T) {
+		oursLogin, theirsLogin := login, login
+		oursLogin.Content = "Use OIDC for login"
+		theirsLogin.SetState(models.StateDone, "", created)
+		oursNew := models.ContextItem{ID: "ours", Number: 4, Content: "Added on main", CreatedAt: created.Add(2 * time.Hour)}
+		theirsNew := models.ContextItem{ID: "theirs", Number: 4, Content: "Added on feature", CreatedAt: created.Add(time.Hour)}
+
+		merged, conflicts, err := Merge(base,
+			encode(t, oursLogin, api, oursNew),
+			encode(t, theirsLogin, api, gone, theirsNew))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(conflicts) != 0 {
+			t.Errorf("Unexpected conflicts: %+v", conflicts)
+		}
+
+		items := decode(t, merged)
+		if _, ok := items["gone"]; ok || len(items) != 4 {
+			t.Errorf("Expected login, api and both new items, got %v", items)
+		}
+		if got := items["login"]; got.Content != "Use OIDC for login" || !got.IsCompleted() {
+			t.Errorf("Expected the edit and the completion merged, got %+v", got)
+		}
+		// The feature item was created first and keeps the number
+		if items["theirs"].Number != 4 || items["ours"].Number != 5 {
+			t.Errorf("Numbers = %d (theirs), %d (ours), want 4 and 5", items["theirs"].Number, items["ours"].Number)
+		}
+	})
+
+	t.Run("conflicts keep ours", func(t *testing.T) {
+		oursLogin, theirsLogin := login, login
+		oursLogin.Content = "Use OIDC for login"
+		theirsLogin.Content = "Use SAML"
+		theirsGone := gone
+		theirsGone.Tags = []string{"keep"}
+
+		merged, conflicts, err := Merge(base,
+			encode(t, oursLogin, api),
+			encode(t, theirsLogin, api, theirsGone))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []Conflict{{ID: "login", Field: "content"}, {ID: "gone"}}
+		if !reflect.DeepEqual(conflicts, want) {
+			t.Errorf("Conflicts = %+v, want %+v", conflicts, want)
+		}
+		items := decode(t, merged)
+		if items["login"].Content != "Use OIDC for login" {
+			t.Errorf("Expected our content kept, got %q", items["login"].Content)
+		}
+		if _, ok := items["gone"]; !ok {
+			t.Error("Expected the item changed on their side kept")
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, _, err := Merge(base, []byte("<<<<<<< HEAD"), base); err == nil {
+			t.Error("Expected an error for an invalid store file")
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package storage
+
+import "github.com/ondrahracek/contextkeeper/internal/models"
+
+// Renumber gives a new number to every item without one and to every item
+// whose number is also held by an older item, as happens when two branches
+// allocate the same number and are merged. Of the items sharing a number,
+// the one created first (by CreatedAt, then ID) keeps it. New numbers are
+// allocated above last and every number in use, in the order of items.
+//
+// The result only depends on the items, so every clone renumbers a merged
+// store the same way. Renumber returns the last number allocated.
+func Renumber(items []models.ContextItem, last int) int {
+	owner := make(map[int]int) // Number to the index of the item keeping it
+	for i, item := range items {
+		if item.Number > last {
+			last = item.Number
+		}
+		if item.Number <= 0 {
+			continue
+		}
+		if j, ok := owner[item.Number]; !ok || olderItem(item, items[j]) {
+			owner[item.Number] = i
+		}
+	}
+
+	for i := range items {
+		if items[i].Number <= 0 || owner[items[i].Number] != i {
+			last++
+			items[i].Number = last
+		}
+	}
+	return last
+}
+
+// olderItem reports whether a was created before b, breaking ties by ID.
+func olderItem(a, b models.ContextItem) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
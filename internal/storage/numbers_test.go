@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+func TestRenumber(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	items := []models.ContextItem{
+		{ID: "a", Number: 1, CreatedAt: created},
+		{ID: "c", Number: 2, CreatedAt: created.Add(time.Hour)},
+		{ID: "b", Number: 2, CreatedAt: created.Add(time.Minute)}, // Older, keeps 2
+		{ID: "d", CreatedAt: created},
+		{ID: "e", Number: 2, CreatedAt: created.Add(time.Minute)}, // Same time as b, ID after it
+	}
+	last := Renumber(items, 3)
+
+	var got []int
+	for _, item := range items {
+		got = append(got, item.Number)
+	}
+	if want := []int{1, 4, 2, 5, 6}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Numbers = %v, want %v", got, want)
+	}
+	if last != 6 {
+		t.Errorf("Renumber() = %d, want 6", last)
+	}
+}
+
+func TestStorageNumbers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ItemsFileName)
+
+	// Items written before numbers existed are numbered in order on load
+	if err := os.WriteFile(path, []byte(`[{"id": "old-1", "content": "One"}, {"id": "old-2", "content": "Two"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stor := NewStorage(path)
+	if err := stor.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if item, _ := stor.GetByID("old-2"); item.Number != 2 {
+		t.Errorf("Number of old-2 = %d, want 2", item.Number)
+	}
+
+	if err := stor.Add(models.ContextItem{ID: "new-3", Content: "Three"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stor.Delete("new-3"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, CounterFileName)); string(data) != "3\n" {
+		t.Errorf("Counter file = %q, want 3", data)
+	}
+
+	// The number of a deleted item is not reused, even by a new instance
+	stor = NewStorage(path)
+	if err := stor.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := stor.Add(models.ContextItem{ID: "new-4", Content: "Four"}); err != nil {
+		t.Fatal(err)
+	}
+	if item, _ := stor.GetByID("new-4"); item.Number != 4 {
+		t.Errorf("Number of new-4 = %d, want 4", item.Number)
+	}
+
+	// Updating with an item that lost its number keeps the number
+	item, _ := stor.GetByID("old-1")
+	item.Number = 0
+	if err := stor.Update(item); err != nil {
+		t.Fatal(err)
+	}
+	if item, _ := stor.GetByID("old-1"); item.Number != 1 {
+		t.Errorf("Number of old-1 after update = %d, want 1", item.Number)
+	}
+}
@@ -1,11 +1,13 @@
 package storage
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/ondrahracek/contextkeeper/internal/models"
+	"github.com/ondrahracek/contextkeeper/internal/search"
 )
 
 func TestNewStorage(t *testing.T) {
@@ -160,3 +162,103 @@ func TestStorageThreadSafety(t *testing.T) {
 		t.Errorf("After concurrent reads: got %d items, want 1", len(items))
 	}
 }
+
+func TestStorageUpdatesSearchIndex(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "contextkeeper-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, ItemsFileName)
+	stor := NewStorage(path)
+	if err := stor.Add(models.ContextItem{ID: "1", Content: "first note"}); err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+
+	// Without a persisted index, mutations don't create one
+	if _, err := os.Stat(search.IndexPath(path)); !os.IsNotExist(err) {
+		t.Fatalf("Expected no index file, got err=%v", err)
+	}
+
+	if err := search.SaveIndex(path, search.Build(stor.GetAll())); err != nil {
+		t.Fatalf("SaveIndex() error: %v", err)
+	}
+	if err := stor.Add(models.ContextItem{ID: "2", Content: "second note"}); err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+	if err := stor.Update(models.ContextItem{ID: "1", Content: "renamed"}); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if err := stor.Delete("2"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+
+	idx, err := search.LoadIndex(path)
+	if err != nil || idx == nil {
+		t.Fatalf("Expected a fresh index after mutations, got %v (err=%v)", idx, err)
+	}
+	if idx.Len() != 1 || idx.Postings[search.Stem("renamed")] == nil {
+		t.Errorf("Index not updated incrementally: %v", idx.Vocabulary())
+	}
+	if idx.Postings["first"] != nil || idx.Postings["second"] != nil {
+		t.Errorf("Index kept stale terms: %v", idx.Vocabulary())
+	}
+}
+
+func TestStorageBatch(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "contextkeeper-test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, ItemsFileName)
+	stor := NewStorage(path)
+	if err := stor.Add(models.ContextItem{ID: "1", Content: "first note"}); err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+	if err := search.SaveIndex(path, search.Build(stor.GetAll())); err != nil {
+		t.Fatalf("SaveIndex() error: %v", err)
+	}
+
+	failed := errors.New("failed")
+	err = stor.Batch(func() error {
+		if err := stor.Add(models.ContextItem{ID: "2", Content: "second note"}); err != nil {
+			return err
+		}
+		if err := stor.Update(models.ContextItem{ID: "1", Content: "renamed"}); err != nil {
+			return err
+		}
+		// Nothing is written until the batch ends
+		if loaded := loadItems(t, path); len(loaded) != 1 || loaded[0].Content != "first note" {
+			t.Errorf("Batch wrote items early: %v", loaded)
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Batch() error = %v, want %v", err, failed)
+	}
+
+	// Changes made before the failure are still written
+	if loaded := loadItems(t, path); len(loaded) != 2 || loaded[1].Number != 2 {
+		t.Errorf("Batch did not write its changes: %v", loaded)
+	}
+	idx, err := search.LoadIndex(path)
+	if err != nil || idx == nil {
+		t.Fatalf("Expected a fresh index after the batch, got %v (err=%v)", idx, err)
+	}
+	if idx.Len() != 2 || idx.Postings["second"] == nil || idx.Postings["first"] != nil {
+		t.Errorf("Index not updated by the batch: %v", idx.Vocabulary())
+	}
+}
+
+// loadItems reads the items file at path into a new storage.
+func loadItems(t *testing.T, path string) []models.ContextItem {
+	t.Helper()
+	stor := NewStorage(path)
+	if err := stor.Load(); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	return stor.GetAll()
+}
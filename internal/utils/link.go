@@ -0,0 +1,42 @@
+// Package utils provides utility functions for the contextkeeper application.
+// It includes formatting helpers for output, tag parsing/validation, UUID generation,
+// and time formatting utilities.
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// linkTypeAliases maps accepted link type spellings to link types.
+var linkTypeAliases = map[string]string{
+	"blocks":     models.LinkBlocks,
+	"block":      models.LinkBlocks,
+	"relates-to": models.LinkRelatesTo,
+	"relates":    models.LinkRelatesTo,
+	"related":    models.LinkRelatesTo,
+	"duplicates": models.LinkDuplicates,
+	"duplicate":  models.LinkDuplicates,
+	"dup":        models.LinkDuplicates,
+	"supersedes": models.LinkSupersedes,
+	"replaces":   models.LinkSupersedes,
+}
+
+// ParseLinkType parses a link type given on the command line.
+//
+// Parameters:
+//   - s: A link type such as "blocks", "relates-to" or "supersedes"
+//     (case-insensitive, "_" may be used instead of "-")
+//
+// Returns:
+//   - The canonical link type (models.LinkBlocks, ...)
+//   - An error if the link type is not recognized
+func ParseLinkType(s string) (string, error) {
+	key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "_", "-")
+	if t, ok := linkTypeAliases[key]; ok {
+		return t, nil
+	}
+	return "", fmt.Errorf("invalid link type %q: use %s", s, strings.Join(models.LinkTypes, ", "))
+}
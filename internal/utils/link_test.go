@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+// TestParseLinkType tests link type parsing and aliases.
+func TestParseLinkType(t *testing.T) {
+	tests := map[string]string{
+		"blocks":     "blocks",
+		"Blocks":     "blocks",
+		"relates_to": "relates-to",
+		"related":    "relates-to",
+		"dup":        "duplicates",
+		"replaces":   "supersedes",
+	}
+	for in, want := range tests {
+		got, err := ParseLinkType(in)
+		if err != nil {
+			t.Errorf("ParseLinkType(%q) error: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("ParseLinkType(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if _, err := ParseLinkType("parent-of"); err == nil {
+		t.Error("Expected error for unknown link type")
+	}
+}
@@ -0,0 +1,63 @@
+// Package utils provides utility functions for the contextkeeper application.
+// It includes formatting helpers for output, tag parsing/validation, UUID generation,
+// and time formatting utilities.
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Inline Markdown patterns rendered by RenderMarkdown.
+var (
+	markdownCode   = regexp.MustCompile("`([^`]+)`")
+	markdownBold   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownLink   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownList   = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+	markdownHeader = regexp.MustCompile(`^#{1,6}\s+`)
+)
+
+// RenderMarkdown renders Markdown text for a terminal: headings and bold
+// text are shown bold, code in cyan, code blocks and quotes dimmed, list
+// bullets as "•" and links as "text (url)". Anything else is left as is.
+func RenderMarkdown(text string) string {
+	var sb strings.Builder
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			// Fences only delimit the block
+			inCode = !inCode
+			continue
+		case inCode:
+			line = colorDim + "    " + line + colorReset
+		case markdownHeader.MatchString(trimmed):
+			line = colorBold + markdownHeader.ReplaceAllString(trimmed, "") + colorReset
+		case strings.HasPrefix(trimmed, ">"):
+			line = colorDim + "│ " + renderInline(strings.TrimSpace(trimmed[1:])) + colorReset
+		default:
+			line = renderInline(markdownList.ReplaceAllString(line, "$1• "))
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// renderInline renders the code spans, bold text and links of a line.
+func renderInline(line string) string {
+	// Code spans first, so that their content is left alone
+	parts := markdownCode.Split(line, -1)
+	codes := markdownCode.FindAllStringSubmatch(line, -1)
+	var sb strings.Builder
+	for i, part := range parts {
+		part = markdownLink.ReplaceAllString(part, "$1 ($2)")
+		part = markdownBold.ReplaceAllString(part, colorBold+"$1$2"+colorReset)
+		sb.WriteString(part)
+		if i < len(codes) {
+			sb.WriteString(colorCyan + codes[i][1] + colorReset)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,29 @@
+package utils
+
+import "testing"
+
+// TestRenderMarkdown tests the terminal rendering of Markdown elements.
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain text", "Use OIDC", "Use OIDC"},
+		{"heading", "## Decision", colorBold + "Decision" + colorReset},
+		{"bold", "Use **OIDC** now", "Use " + colorBold + "OIDC" + colorReset + " now"},
+		{"code", "Run `go test` first", "Run " + colorCyan + "go test" + colorReset + " first"},
+		{"bold inside code", "`**x**`", colorCyan + "**x**" + colorReset},
+		{"link", "See [the RFC](https://example.com/rfc)", "See the RFC (https://example.com/rfc)"},
+		{"list", "- one\n  * two", "• one\n  • two"},
+		{"quote", "> Quoted", colorDim + "│ Quoted" + colorReset},
+		{"code block", "```go\nx := 1\n```\nafter", colorDim + "    x := 1" + colorReset + "\nafter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkdown(tt.in); got != tt.want {
+				t.Errorf("RenderMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
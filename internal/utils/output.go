@@ -6,6 +6,7 @@ package utils
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ondrahracek/contextkeeper/internal/models"
 )
@@ -29,6 +30,12 @@ const (
 	colorDim = "\033[2m"
 )
 
+// Highlight markers wrap the matching words in search result snippets.
+const (
+	HighlightOpen  = colorYellow
+	HighlightClose = colorReset
+)
+
 // Truncation limits for output formatting.
 const (
 	// maxContentLength is the maximum length for item content in list view
@@ -61,24 +68,60 @@ const (
 //
 //	A formatted string representation of the items, or "No items found." if empty
 func FormatItemList(items []models.ContextItem, showCompleted bool) string {
+	return FormatItems(items, ListOptions{ShowCompleted: showCompleted})
+}
+
+// ListOptions controls how FormatItems lays out a list of items.
+type ListOptions struct {
+	// ShowCompleted includes completed items in the output
+	ShowCompleted bool
+	// Progress holds the subtask rollups to show, keyed by parent ID
+	Progress map[string]models.Progress
+	// Tree indents subtasks under their parents
+	Tree bool
+	// BlockedBy holds the IDs of the open items blocking each item, keyed by
+	// the blocked item's ID (see models.Blockers)
+	BlockedBy map[string][]string
+	// Anchors shows the code locations items are attached to
+	Anchors bool
+}
+
+// FormatItems formats a list of ContextItem for display, like FormatItemList,
+// with the subtask rollups and tree layout given in opts.
+//
+// In tree layout every item is followed by its subtasks, indented one level
+// deeper. Items whose parent is not part of the list are shown at the top
+// level.
+func FormatItems(items []models.ContextItem, opts ListOptions) string {
 	if len(items) == 0 {
 		return "No items found."
 	}
 
-	var sb strings.Builder
+	shown := make([]models.ContextItem, 0, len(items))
 	for _, item := range items {
-		if item.CompletedAt != nil && !showCompleted {
+		if item.CompletedAt != nil && !opts.ShowCompleted {
 			continue
 		}
+		shown = append(shown, item)
+	}
 
-		// Show first 6 characters of ID
-		idDisplay := item.ID[:6]
+	depths := make([]int, len(shown))
+	if opts.Tree {
+		shown, depths = treeOrder(shown)
+	}
 
-		status := "[ ]"
-		if item.CompletedAt != nil {
-			status = fmt.Sprintf("%s[x]%s", colorGreen, colorReset)
+	now := time.Now()
+	var sb strings.Builder
+	for i, item := range shown {
+		// Show the number, if any, and the first 6 characters of the short ID
+		idDisplay := models.ShortID(item.ID)
+		idDisplay = idDisplay[:min(6, len(idDisplay))]
+		if item.Number > 0 {
+			idDisplay = fmt.Sprintf("#%d %s", item.Number, idDisplay)
 		}
 
+		status := strings.Repeat("  ", depths[i]) + formatState(item)
+
 		projectInfo := ""
 		if item.Project != "" {
 			projectInfo = fmt.Sprintf("%s@%s%s", colorCyan, item.Project, colorReset)
@@ -88,6 +131,22 @@ func FormatItemList(items []models.ContextItem, showCompleted bool) string {
 		if len(item.Tags) > 0 {
 			tagsInfo = fmt.Sprintf(" %s[%s]%s", colorYellow, strings.Join(item.Tags, ", "), colorReset)
 		}
+		if item.Pinned {
+			tagsInfo += fmt.Sprintf(" %s(pinned)%s", colorDim, colorReset)
+		}
+		if reason := item.StateReason(); reason != "" && item.EffectiveState() == models.StateBlocked {
+			tagsInfo += fmt.Sprintf(" %s(blocked: %s)%s", colorRed, reason, colorReset)
+		}
+		if blockers := opts.BlockedBy[item.ID]; len(blockers) > 0 {
+			tagsInfo += formatBlockers(blockers)
+		}
+		if p, ok := opts.Progress[item.ID]; ok {
+			tagsInfo += formatProgress(p)
+		}
+		tagsInfo += formatPlanning(item, now)
+		if opts.Anchors {
+			tagsInfo += formatAnchors(item.Anchors)
+		}
 
 		createdAt := item.CreatedAt.Format("2006-01-02 15:04")
 		truncatedContent := truncateString(item.Content, maxContentLength)
@@ -109,6 +168,129 @@ func FormatItemList(items []models.ContextItem, showCompleted bool) string {
 	return sb.String()
 }
 
+// treeOrder orders items so that each item is directly followed by its
+// subtasks, keeping the original order among siblings, and returns the
+// nesting depth of each item. Items in a parent cycle are shown at the top
+// level.
+func treeOrder(items []models.ContextItem) ([]models.ContextItem, []int) {
+	present := make(map[string]bool, len(items))
+	for _, item := range items {
+		present[item.ID] = true
+	}
+	children := make(map[string][]models.ContextItem)
+	var roots []models.ContextItem
+	for _, item := range items {
+		if item.ParentID != "" && present[item.ParentID] {
+			children[item.ParentID] = append(children[item.ParentID], item)
+		} else {
+			roots = append(roots, item)
+		}
+	}
+
+	ordered := make([]models.ContextItem, 0, len(items))
+	depths := make([]int, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	var walk func(item models.ContextItem, depth int)
+	walk = func(item models.ContextItem, depth int) {
+		seen[item.ID] = true
+		ordered = append(ordered, item)
+		depths = append(depths, depth)
+		for _, child := range children[item.ID] {
+			if !seen[child.ID] {
+				walk(child, depth+1)
+			}
+		}
+	}
+	for _, item := range roots {
+		walk(item, 0)
+	}
+	// Whatever is left has no path to a root
+	for _, item := range items {
+		if !seen[item.ID] {
+			walk(item, 0)
+		}
+	}
+	return ordered, depths
+}
+
+// formatProgress formats a subtask rollup such as " (3/5 done)", in green
+// once every subtask is done.
+func formatProgress(p models.Progress) string {
+	color := colorDim
+	if p.Total > 0 && p.Done == p.Total {
+		color = colorGreen
+	}
+	return fmt.Sprintf(" %s(%s)%s", color, FormatProgress(p), colorReset)
+}
+
+// formatBlockers formats the items blocking an item as " (blocked by
+// abc123, def456)".
+func formatBlockers(ids []string) string {
+	short := make([]string, len(ids))
+	for i, id := range ids {
+		if id = models.ShortID(id); len(id) > 6 {
+			id = id[:6]
+		}
+		short[i] = id
+	}
+	return fmt.Sprintf(" %s(blocked by %s)%s", colorRed, strings.Join(short, ", "), colorReset)
+}
+
+// formatAnchors formats the code locations of an item, such as
+// " <internal/auth/login.go:120>". Stale anchors are shown in red.
+func formatAnchors(anchors []models.Anchor) string {
+	var out string
+	for _, a := range anchors {
+		if a.Stale {
+			out += fmt.Sprintf(" %s<%s stale>%s", colorRed, a, colorReset)
+		} else {
+			out += fmt.Sprintf(" %s<%s>%s", colorDim, a, colorReset)
+		}
+	}
+	return out
+}
+
+// FormatProgress formats a subtask rollup as "3/5 done".
+func FormatProgress(p models.Progress) string {
+	return fmt.Sprintf("%d/%d done", p.Done, p.Total)
+}
+
+// formatState formats the checkbox marking an item's workflow state:
+// "[ ]" todo, "[>]" in progress, "[!]" blocked, "[x]" done and "[-]" wontfix.
+func formatState(item models.ContextItem) string {
+	switch item.EffectiveState() {
+	case models.StateInProgress:
+		return fmt.Sprintf("%s%s[>]%s", colorBold, colorCyan, colorReset)
+	case models.StateBlocked:
+		return fmt.Sprintf("%s[!]%s", colorRed, colorReset)
+	case models.StateDone:
+		return fmt.Sprintf("%s[x]%s", colorGreen, colorReset)
+	case models.StateWontfix:
+		return fmt.Sprintf("%s[-]%s", colorDim, colorReset)
+	}
+	return "[ ]"
+}
+
+// formatPlanning formats the priority and due date markers of an item, such
+// as " !high due in 3d". Overdue dates are shown in red.
+func formatPlanning(item models.ContextItem, now time.Time) string {
+	var out string
+	switch item.Priority {
+	case models.PriorityHigh:
+		out += fmt.Sprintf(" %s!%s%s", colorRed, item.Priority, colorReset)
+	case models.PriorityMedium, models.PriorityLow:
+		out += fmt.Sprintf(" %s!%s%s", colorDim, item.Priority, colorReset)
+	}
+	if item.DueAt != nil && item.CompletedAt == nil {
+		color := colorDim
+		if item.IsOverdue(now) {
+			color = colorRed
+		}
+		out += fmt.Sprintf(" %s%s%s", color, FormatDue(*item.DueAt, now), colorReset)
+	}
+	return out
+}
+
 // FormatTable formats items in a table-like format with aligned columns.
 // Each row displays ID, content, project, and creation date.
 //
@@ -53,3 +53,53 @@ func TestFormatItemList(t *testing.T) {
 		t.Errorf("Expected output to contain '[x]' for completed item")
 	}
 }
+
+// TestFormatItemsTree tests that subtasks are indented under their parents and
+// that subtask rollups are shown.
+func TestFormatItemsTree(t *testing.T) {
+	now := time.Now()
+	items := []models.ContextItem{
+		{ID: "child1-0000", Content: "Child one", CreatedAt: now, ParentID: "parent-0000"},
+		{ID: "parent-0000", Content: "Parent", CreatedAt: now},
+		{ID: "grand1-0000", Content: "Grandchild", CreatedAt: now, ParentID: "child1-0000"},
+		{ID: "orphan-0000", Content: "Orphan", CreatedAt: now, ParentID: "missing-0000"},
+	}
+	progress := map[string]models.Progress{"parent-0000": {Done: 1, Total: 3}}
+
+	output := FormatItems(items, ListOptions{Progress: progress, Tree: true})
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %d:\n%s", len(lines), output)
+	}
+	expected := []struct{ prefix, id string }{
+		{"[ ]", "parent"},
+		{"  [ ]", "child1"},
+		{"    [ ]", "grand1"},
+		{"[ ]", "orphan"},
+	}
+	for i, e := range expected {
+		if !strings.HasPrefix(lines[i], e.prefix+" ["+e.id+"]") {
+			t.Errorf("Line %d: expected %q indented as %q, got %q", i, e.id, e.prefix, lines[i])
+		}
+	}
+	if !strings.Contains(lines[0], "(1/3 done)") {
+		t.Errorf("Expected rollup on the parent line, got %q", lines[0])
+	}
+
+	// Without tree layout the original order is kept
+	flat := FormatItems(items, ListOptions{})
+	if !strings.HasPrefix(flat, "[ ] [child1]") {
+		t.Errorf("Expected flat list in original order, got:\n%s", flat)
+	}
+}
+
+// TestFormatItemListShortIDs tests that IDs shorter than the 6-character
+// prefix, as hand-edited or imported items can have, are shown whole.
+func TestFormatItemListShortIDs(t *testing.T) {
+	items := []models.ContextItem{{ID: "abc", Content: "Imported", CreatedAt: time.Now()}}
+
+	output := FormatItemList(items, false)
+	if !strings.Contains(output, "abc") {
+		t.Errorf("Expected output to contain ID 'abc', got %q", output)
+	}
+}
@@ -0,0 +1,44 @@
+// Package utils provides utility functions for the contextkeeper application.
+// It includes formatting helpers for output, tag parsing/validation, UUID generation,
+// and time formatting utilities.
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ondrahracek/contextkeeper/internal/models"
+)
+
+// priorityAliases maps accepted priority spellings to priority levels.
+var priorityAliases = map[string]string{
+	"high":   models.PriorityHigh,
+	"h":      models.PriorityHigh,
+	"urgent": models.PriorityHigh,
+	"medium": models.PriorityMedium,
+	"med":    models.PriorityMedium,
+	"m":      models.PriorityMedium,
+	"normal": models.PriorityMedium,
+	"low":    models.PriorityLow,
+	"l":      models.PriorityLow,
+}
+
+// ParsePriority parses a priority level given on the command line.
+//
+// Parameters:
+//   - s: A priority such as "high", "h", "medium" or "low" (case-insensitive)
+//
+// Returns:
+//   - The canonical priority level (models.PriorityHigh, ...), or an empty
+//     string for "none" or an empty input
+//   - An error if the priority is not recognized
+func ParsePriority(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "none" {
+		return "", nil
+	}
+	if p, ok := priorityAliases[s]; ok {
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid priority %q: use high, medium or low", s)
+}
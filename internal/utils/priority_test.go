@@ -0,0 +1,29 @@
+package utils
+
+import "testing"
+
+// TestParsePriority tests priority parsing and aliases.
+func TestParsePriority(t *testing.T) {
+	tests := map[string]string{
+		"high":   "high",
+		"H":      "high",
+		"urgent": "high",
+		"med":    "medium",
+		"low":    "low",
+		"":       "",
+		"none":   "",
+	}
+	for in, want := range tests {
+		got, err := ParsePriority(in)
+		if err != nil {
+			t.Errorf("ParsePriority(%q) error: %v", in, err)
+		}
+		if got != want {
+			t.Errorf("ParsePriority(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if _, err := ParsePriority("critical"); err == nil {
+		t.Error("Expected error for unknown priority")
+	}
+}
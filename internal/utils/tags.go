@@ -29,6 +29,21 @@ var tagRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 //
 //	A slice of unique, trimmed tags in their original order
 func ParseTags(tagStr string) []string {
+	return ParseTagsWithAliases(tagStr, nil)
+}
+
+// ParseTagsWithAliases parses tags like ParseTags, replacing each tag that
+// is a key of aliases with its value, such as "bugs" with "bug". Duplicates
+// are removed after replacing aliases.
+//
+// Parameters:
+//   - tagStr: A string containing tags separated by spaces, commas, or both
+//   - aliases: Maps alias tags to the tags they stand for; may be nil
+//
+// Returns:
+//
+//	A slice of unique, trimmed tags in their original order
+func ParseTagsWithAliases(tagStr string, aliases map[string]string) []string {
 	if strings.TrimSpace(tagStr) == "" {
 		return nil
 	}
@@ -42,6 +57,9 @@ func ParseTags(tagStr string) []string {
 	result := make([]string, 0, len(tags))
 	for _, tag := range tags {
 		tag = strings.TrimSpace(tag)
+		if alias, ok := aliases[tag]; ok {
+			tag = alias
+		}
 		if tag != "" && !seen[tag] {
 			seen[tag] = true
 			result = append(result, tag)
@@ -167,3 +167,24 @@ func TestValidateTags(t *testing.T) {
 		})
 	}
 }
+
+// TestParseTagsWithAliases tests that aliases are replaced before duplicates
+// are removed.
+func TestParseTagsWithAliases(t *testing.T) {
+	aliases := map[string]string{"bugs": "bug", "ui": "frontend"}
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "bugs", expected: []string{"bug"}},
+		{input: "bugs, bug urgent", expected: []string{"bug", "urgent"}},
+		{input: "UI ui", expected: []string{"UI", "frontend"}},
+		{input: "", expected: nil},
+	}
+	for _, tt := range tests {
+		result := ParseTagsWithAliases(tt.input, aliases)
+		if strings.Join(result, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("ParseTagsWithAliases(%q) = %v, want %v", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -3,7 +3,12 @@
 // and time formatting utilities.
 package utils
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // FormatTime formats a time.Time value using the specified format string.
 //
@@ -30,3 +35,132 @@ func FormatTime(t time.Time, format string) string {
 func ParseTime(s string, format string) (time.Time, error) {
 	return time.Parse(format, s)
 }
+
+// FormatAge formats the time elapsed since t in a compact form such as
+// "today", "3d", "2w", "5mo" or "1y".
+//
+// Parameters:
+//   - t: The earlier point in time
+//   - now: The reference time, usually time.Now()
+//
+// Returns:
+//
+//	The compact age string, or an empty string if t is the zero time
+func FormatAge(t time.Time, now time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	days := int(now.Sub(t).Hours() / 24)
+	switch {
+	case days < 1:
+		return "today"
+	case days < 14:
+		return fmt.Sprintf("%dd", days)
+	case days < 60:
+		return fmt.Sprintf("%dw", days/7)
+	case days < 365:
+		return fmt.Sprintf("%dmo", days/30)
+	default:
+		return fmt.Sprintf("%dy", days/365)
+	}
+}
+
+// ParseDue parses a due date given on the command line and returns local
+// midnight of that day.
+//
+// Accepted forms:
+//   - Absolute dates: "2026-11-01"
+//   - "today" and "tomorrow"
+//   - Offsets from today in days or weeks: "+3d", "+2w", "-1d"
+//   - Weekday names, full or abbreviated: "friday", "fri". A weekday means
+//     its next occurrence after today; use "today" for today.
+//
+// Parameters:
+//   - s: The due date to parse (case-insensitive)
+//   - now: The reference time, usually time.Now()
+//
+// Returns:
+//   - Midnight of the due day in now's location
+//   - An error if the date is not recognized
+func ParseDue(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	switch s {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if t, err := ParseTime(s, "2006-01-02"); err == nil {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location()), nil
+	}
+
+	if len(s) >= 3 && (s[0] == '+' || s[0] == '-') {
+		n, err := strconv.Atoi(s[1 : len(s)-1])
+		if err == nil {
+			if s[0] == '-' {
+				n = -n
+			}
+			switch s[len(s)-1] {
+			case 'd':
+				return today.AddDate(0, 0, n), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*n), nil
+			}
+		}
+	}
+
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if s == name || s == name[:3] {
+			days := (int(wd) - int(today.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			return today.AddDate(0, 0, days), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid due date %q: use YYYY-MM-DD, today, tomorrow, +3d, +2w or a weekday", s)
+}
+
+// DaysUntil returns the number of calendar days from the day of now to the
+// day of t: 0 for today, 1 for tomorrow and negative values for past days.
+func DaysUntil(t time.Time, now time.Time) int {
+	ty, tm, td := t.In(now.Location()).Date()
+	ny, nm, nd := now.Date()
+	day := time.Date(ty, tm, td, 12, 0, 0, 0, time.UTC)
+	ref := time.Date(ny, nm, nd, 12, 0, 0, 0, time.UTC)
+	return int(day.Sub(ref).Hours() / 24)
+}
+
+// FormatDue formats a due date relative to now, e.g. "overdue 3d",
+// "due today", "due tomorrow", "due in 5d" or "due 2026-11-01" for dates
+// more than two weeks away.
+//
+// Parameters:
+//   - due: The due date
+//   - now: The reference time, usually time.Now()
+//
+// Returns:
+//
+//	The compact due date string
+func FormatDue(due time.Time, now time.Time) string {
+	days := DaysUntil(due, now)
+	switch {
+	case days < 0:
+		return fmt.Sprintf("overdue %dd", -days)
+	case days == 0:
+		return "due today"
+	case days == 1:
+		return "due tomorrow"
+	case days < 14:
+		return fmt.Sprintf("due in %dd", days)
+	default:
+		return "due " + due.Format("2006-01-02")
+	}
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFormatAge tests the compact age formatting used in sync output.
+func TestFormatAge(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"zero time", time.Time{}, ""},
+		{"same day", now.Add(-3 * time.Hour), "today"},
+		{"days", now.Add(-3 * day), "3d"},
+		{"weeks", now.Add(-21 * day), "3w"},
+		{"months", now.Add(-90 * day), "3mo"},
+		{"years", now.Add(-800 * day), "2y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatAge(tt.t, now); got != tt.want {
+				t.Errorf("FormatAge() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseDue tests the absolute and relative due date forms.
+func TestParseDue(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.Local)
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2026-11-01", day(2026, 11, 1)},
+		{"today", day(2026, 10, 14)},
+		{"Tomorrow", day(2026, 10, 15)},
+		{"+3d", day(2026, 10, 17)},
+		{"+2w", day(2026, 10, 28)},
+		{"-1d", day(2026, 10, 13)},
+		{"friday", day(2026, 10, 16)},
+		{"fri", day(2026, 10, 16)},
+		{"wednesday", day(2026, 10, 21)},
+		{"mon", day(2026, 10, 19)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDue(tt.in, now)
+			if err != nil {
+				t.Fatalf("ParseDue(%q) error: %v", tt.in, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDue(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "soon", "+d", "+3y", "2026-13-01"} {
+		if _, err := ParseDue(bad, now); err == nil {
+			t.Errorf("ParseDue(%q) expected error", bad)
+		}
+	}
+}
+
+// TestFormatDue tests the relative due date formatting.
+func TestFormatDue(t *testing.T) {
+	now := time.Date(2026, 10, 14, 23, 0, 0, 0, time.Local)
+	day := func(offset int) time.Time {
+		return time.Date(2026, 10, 14+offset, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		due  time.Time
+		want string
+	}{
+		{day(-3), "overdue 3d"},
+		{day(0), "due today"},
+		{day(1), "due tomorrow"},
+		{day(5), "due in 5d"},
+		{day(30), "due 2026-11-13"},
+	}
+	for _, tt := range tests {
+		if got := FormatDue(tt.due, now); got != tt.want {
+			t.Errorf("FormatDue(%v) = %q, want %q", tt.due, got, tt.want)
+		}
+	}
+}
@@ -5,35 +5,70 @@ package utils
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"fmt"
+	"sync"
+	"time"
 )
 
 // UUID constants.
 const (
 	// uuidLength is the standard length of a UUID string (36 characters including dashes)
 	uuidLength = 36
-	// uuidVersion4Format indicates the UUID follows the version 4 random format
-	uuidVersion4Format = 4
+	// uuidVersion7Format indicates the UUID follows the version 7 time-ordered format
+	uuidVersion7Format = 7
 )
 
-// GenerateUUID generates a random UUID version 4 using cryptographically secure
-// random number generation (crypto/rand).
+// uuidClock holds the timestamp of the last generated UUID, so that UUIDs
+// generated by one process are strictly increasing even within a millisecond.
+var uuidClock struct {
+	sync.Mutex
+	last int64 // Milliseconds << 12 | 12-bit fraction of the millisecond
+}
+
+// GenerateUUID generates a time-ordered UUID version 7 (RFC 9562): 48 bits
+// of Unix time in milliseconds, 12 bits of the fraction of the millisecond,
+// and 62 bits from crypto/rand. Sorting the IDs as strings sorts them by
+// creation time.
 //
 // Returns:
 //
-//	A string representation of the UUID in the format "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
-func GenerateUUID() string {
-	// Generate 16 random bytes (128 bits) for UUID v4
+//	A string representation of the UUID in the format "xxxxxxxx-xxxx-7xxx-xxxx-xxxxxxxxxxxx",
+//	or an error if no random bytes could be read
+func GenerateUUID() (string, error) {
 	b := make([]byte, 16)
-	_, err := rand.Read(b)
-	if err != nil {
-		// Fallback to a simple random string if crypto/rand fails
-		// This should rarely happen in practice
-		return fmt.Sprintf("rand-%d", b[0])
+	if _, err := rand.Read(b[8:]); err != nil {
+		return "", fmt.Errorf("failed to generate ID: %w", err)
 	}
+	return formatUUIDv7(b, nextUUIDTime(time.Now())), nil
+}
+
+// nextUUIDTime returns the timestamp for a UUID generated at now, later
+// than that of any UUID generated before.
+func nextUUIDTime(now time.Time) int64 {
+	ts := uuidTime(now)
+
+	uuidClock.Lock()
+	defer uuidClock.Unlock()
+	if ts <= uuidClock.last {
+		ts = uuidClock.last + 1
+	}
+	uuidClock.last = ts
+	return ts
+}
+
+// uuidTime returns the timestamp of a UUID generated at t: milliseconds
+// shifted left by 12 bits, plus the fraction of the millisecond in 1/4096.
+func uuidTime(t time.Time) int64 {
+	ns := t.UnixNano()
+	return ns/int64(time.Millisecond)<<12 | (ns%int64(time.Millisecond))*4096/int64(time.Millisecond)
+}
 
-	// Set UUID version 4 bits (bits 4-7 of byte 6)
-	b[6] = (b[6] & 0x0f) | (uuidVersion4Format << 4)
+// formatUUIDv7 sets the timestamp, version and variant of the 16 bytes b,
+// whose last 8 are random, and formats them as a UUID.
+func formatUUIDv7(b []byte, ts int64) string {
+	// 48 bits of milliseconds, then the version and 12 bits of fraction
+	binary.BigEndian.PutUint64(b[0:8], uint64(ts>>12)<<16|uuidVersion7Format<<12|uint64(ts&0xfff))
 	// Set UUID variant to RFC 4122 (bits 6-7 of byte 8)
 	b[8] = (b[8] & 0x3f) | 0x80
 
@@ -1,15 +1,18 @@
 package utils
 
 import (
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 // TestGenerateUUID tests the GenerateUUID function for format, uniqueness, and validity.
 func TestGenerateUUID(t *testing.T) {
 	tests := []struct {
 		name   string
-		action func() string
+		action func() (string, error)
 		check  func(t *testing.T, result string)
 	}{
 		{
@@ -53,7 +56,7 @@ func TestGenerateUUID(t *testing.T) {
 			action: GenerateUUID,
 			check: func(t *testing.T, result string) {
 				// Note: This test has a theoretical chance of collision, but it's astronomically low
-				result2 := GenerateUUID()
+				result2, _ := GenerateUUID()
 				if result == result2 {
 					t.Error("GenerateUUID should return unique values")
 				}
@@ -75,8 +78,53 @@ func TestGenerateUUID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.action()
+			result, err := tt.action()
+			if err != nil {
+				t.Fatal(err)
+			}
 			tt.check(t, result)
 		})
 	}
 }
+
+// TestGenerateUUIDTimeOrdered tests that the UUIDs are version 7 and sort by
+// creation time.
+func TestGenerateUUIDTimeOrdered(t *testing.T) {
+	before := time.Now().UnixMilli()
+	ids := make([]string, 1000)
+	for i := range ids {
+		id, err := GenerateUUID()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+	after := time.Now().UnixMilli()
+
+	if !sort.StringsAreSorted(ids) {
+		t.Error("UUIDs generated in sequence should sort in order")
+	}
+	for _, id := range ids[:3] {
+		if id[14] != '7' || !strings.ContainsRune("89ab", rune(id[19])) {
+			t.Errorf("UUID %s: want version 7 and the RFC 4122 variant", id)
+		}
+		ms, err := strconv.ParseInt(strings.ReplaceAll(id[:13], "-", ""), 16, 64)
+		if err != nil || ms < before || ms > after {
+			t.Errorf("UUID %s: timestamp %d not within [%d, %d]", id, ms, before, after)
+		}
+	}
+}
+
+// TestFormatUUIDv7 tests the layout of the timestamp in the UUID.
+func TestFormatUUIDv7(t *testing.T) {
+	at := time.Date(2026, 10, 18, 9, 30, 0, 500000, time.UTC) // Half a millisecond
+	got := formatUUIDv7(make([]byte, 16), uuidTime(at))
+	if want := "01a14e58-b9c0-7800-8000-000000000000"; got != want {
+		t.Errorf("formatUUIDv7() = %s, want %s", got, want)
+	}
+
+	ts := nextUUIDTime(at.Add(time.Hour))
+	if next := nextUUIDTime(at.Add(time.Hour)); next <= ts {
+		t.Errorf("Timestamp for the same time %d should be after %d", next, ts)
+	}
+}